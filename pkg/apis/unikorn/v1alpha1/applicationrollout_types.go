@@ -0,0 +1,158 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ApplicationRolloutList defines a list of application rollouts.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type ApplicationRolloutList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ApplicationRollout `json:"items"`
+}
+
+// ApplicationRollout coordinates a staged upgrade of resources from one version
+// of an application to another.  It lives in the same namespace as the application
+// it refers to.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Namespaced,categories=unikorn
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="application",type="string",JSONPath=".spec.application"
+// +kubebuilder:printcolumn:name="from",type="string",JSONPath=".spec.from"
+// +kubebuilder:printcolumn:name="to",type="string",JSONPath=".spec.to"
+// +kubebuilder:printcolumn:name="phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="admitted",type="integer",JSONPath=".status.admittedPercent"
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+type ApplicationRollout struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ApplicationRolloutSpec   `json:"spec"`
+	Status            ApplicationRolloutStatus `json:"status,omitempty"`
+}
+
+type ApplicationRolloutSpec struct {
+	// Application is the name of the application being rolled out.
+	Application string `json:"application"`
+	// From is the version resources remain on until they are admitted to
+	// the rollout.
+	From SemanticVersion `json:"from"`
+	// To is the version being rolled out.
+	To SemanticVersion `json:"to"`
+	// Resources selects all resources that take part in the rollout.
+	Resources ApplicationRolloutResources `json:"resources"`
+	// CanaryPercent is the percentage of resources upgraded in the first batch.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default=10
+	CanaryPercent *int `json:"canaryPercent,omitempty"`
+	// BatchPercent is the percentage of resources upgraded in each subsequent
+	// batch.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default=25
+	BatchPercent *int `json:"batchPercent,omitempty"`
+	// PauseOnFailure stops any further resources being admitted when an upgraded
+	// resource fails.  The rollout will resume once all failed resources become
+	// healthy.
+	// +kubebuilder:default=true
+	PauseOnFailure *bool `json:"pauseOnFailure,omitempty"`
+	// Paused stops any further resources being admitted.
+	Paused bool `json:"paused,omitempty"`
+}
+
+// ApplicationRolloutResources selects resources that take part in a rollout,
+// these are expected to be of a single kind that uses the application.
+type ApplicationRolloutResources struct {
+	// APIVersion is the resources' API version.
+	APIVersion string `json:"apiVersion"`
+	// Kind is the resources' kind.
+	Kind string `json:"kind"`
+	// Selector, if set, limits the rollout to matching resources, otherwise
+	// all resources of the kind take part.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// ApplicationRolloutPhase defines the overall state of a rollout.
+// +kubebuilder:validation:Enum=Progressing;Paused;Failed;Complete
+type ApplicationRolloutPhase string
+
+const (
+	// ApplicationRolloutPhaseProgressing means resources are being admitted.
+	ApplicationRolloutPhaseProgressing ApplicationRolloutPhase = "Progressing"
+	// ApplicationRolloutPhasePaused means the rollout was paused by the user.
+	ApplicationRolloutPhasePaused ApplicationRolloutPhase = "Paused"
+	// ApplicationRolloutPhaseFailed means an upgraded resource has failed and
+	// the rollout has been paused.
+	ApplicationRolloutPhaseFailed ApplicationRolloutPhase = "Failed"
+	// ApplicationRolloutPhaseComplete means all resources have been upgraded.
+	ApplicationRolloutPhaseComplete ApplicationRolloutPhase = "Complete"
+)
+
+// ApplicationRolloutTargetState defines the state of a resource in a rollout.
+// +kubebuilder:validation:Enum=Admitted;Upgrading;Failed
+type ApplicationRolloutTargetState string
+
+const (
+	// ApplicationRolloutTargetStateAdmitted means the resource may upgrade on
+	// its next reconcile.
+	ApplicationRolloutTargetStateAdmitted ApplicationRolloutTargetState = "Admitted"
+	// ApplicationRolloutTargetStateUpgrading means the resource has picked up
+	// the new version.
+	ApplicationRolloutTargetStateUpgrading ApplicationRolloutTargetState = "Upgrading"
+	// ApplicationRolloutTargetStateFailed means the resource errored while
+	// provisioning the new version.
+	ApplicationRolloutTargetStateFailed ApplicationRolloutTargetState = "Failed"
+)
+
+// ApplicationRolloutTarget records an admitted resource's progress through a rollout.
+type ApplicationRolloutTarget struct {
+	// APIVersion is the resource's API version.
+	APIVersion string `json:"apiVersion"`
+	// Kind is the resource's kind.
+	Kind string `json:"kind"`
+	// Namespace is the resource's namespace.
+	Namespace string `json:"namespace,omitempty"`
+	// Name is the resource's name.
+	Name string `json:"name"`
+	// UID is the resource's unique ID.
+	UID types.UID `json:"uid"`
+	// State is the resource's state in the rollout.
+	State ApplicationRolloutTargetState `json:"state"`
+	// UpgradeTime is when the resource picked up the new version.
+	UpgradeTime *metav1.Time `json:"upgradeTime,omitempty"`
+}
+
+type ApplicationRolloutStatus struct {
+	// Phase is the overall state of the rollout.
+	Phase ApplicationRolloutPhase `json:"phase,omitempty"`
+	// AdmittedPercent is the percentage of resources currently admitted.
+	AdmittedPercent int `json:"admittedPercent,omitempty"`
+	// Total is the number of resources taking part in the rollout.
+	Total int `json:"total,omitempty"`
+	// Admitted is the number of resources admitted to the rollout, including
+	// those that have completed.
+	Admitted int `json:"admitted,omitempty"`
+	// Targets records resources that are being upgraded, or have failed to.
+	// Those that have upgraded successfully are removed, so this is bounded by
+	// the batch size and number of failures.
+	Targets []ApplicationRolloutTarget `json:"targets,omitempty"`
+}
@@ -19,6 +19,7 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // HelmApplicationList defines a list of Helm applications.
@@ -77,6 +78,16 @@ type HelmApplicationVersion struct {
 	// Parameters is a set of static --set parameters to pass to the chart.
 	// If not set, uses the application default.
 	Parameters []HelmApplicationParameter `json:"parameters,omitempty"`
+	// Values is a set of static values to pass to the chart.  These are deep-merged
+	// with any values provided by a generator, with the generator taking precedence.
+	// Parameters take precedence over both.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Type=object
+	Values *runtime.RawExtension `json:"values,omitempty"`
+	// Schema, if set, is used to validate the values and parameters passed to
+	// the chart before it is provisioned.  This is typically the chart's
+	// values.schema.json.
+	Schema *HelmApplicationSchema `json:"schema,omitempty"`
 	// Namespace is the namespace to install the application to.
 	Namespace *string `json:"namespace,omitempty"`
 	// CreateNamespace indicates whether the chart requires a namespace to be
@@ -107,6 +118,26 @@ type HelmApplicationParameter struct {
 	Value string `json:"value"`
 }
 
+// +kubebuilder:validation:XValidation:rule="has(self.inline) != has(self.configMapRef)",message="exactly one of inline or configMapRef must be specified"
+type HelmApplicationSchema struct {
+	// Inline is a JSON schema defined in line.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Type=object
+	Inline *runtime.RawExtension `json:"inline,omitempty"`
+	// ConfigMapRef references a JSON schema in a config map in the same
+	// namespace as the application.
+	ConfigMapRef *HelmApplicationSchemaConfigMapReference `json:"configMapRef,omitempty"`
+}
+
+type HelmApplicationSchemaConfigMapReference struct {
+	// Name is the name of the config map.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Key is the key in the config map that contains the schema.
+	// +kubebuilder:default=values.schema.json
+	Key string `json:"key,omitempty"`
+}
+
 type HelmApplicationDependency struct {
 	// Name of the application to depend on.
 	// +kubebuilder:validation:MinLength=1
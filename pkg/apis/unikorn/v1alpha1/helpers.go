@@ -86,6 +86,10 @@ func UpdateCondition(conditions *[]Condition, t ConditionType, status corev1.Con
 	existing := *existingPtr
 	existing.LastTransitionTime = condition.LastTransitionTime
 
+	// The observed generation is updated independently, and a change in
+	// generation alone is not a transition.
+	existing.ObservedGeneration = condition.ObservedGeneration
+
 	if existing != condition {
 		*existingPtr = condition
 	}
@@ -38,6 +38,8 @@ type ResourceLabeller interface {
 }
 
 // ReconcilePauser indicates a resource can have its reconciliation paused.
+// Any resource may also be paused with a reason and expiry using the standard
+// pause annotations.
 type ReconcilePauser interface {
 	// Paused indicates a resource is paused and will not do anything.
 	Paused() bool
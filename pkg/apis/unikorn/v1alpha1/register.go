@@ -35,6 +35,11 @@ const (
 	HelmApplicationKind = "HelmApplication"
 	// HelmApplicationResource is the API endpoint for helm application descriptors.
 	HelmApplicationResource = "helmapplications"
+
+	// ApplicationRolloutKind is the API kind for application rollouts.
+	ApplicationRolloutKind = "ApplicationRollout"
+	// ApplicationRolloutResource is the API endpoint for application rollouts.
+	ApplicationRolloutResource = "applicationrollouts"
 )
 
 var (
@@ -53,7 +58,7 @@ var (
 
 //nolint:gochecknoinits
 func init() {
-	SchemeBuilder.Register(&HelmApplication{}, &HelmApplicationList{})
+	SchemeBuilder.Register(&HelmApplication{}, &HelmApplicationList{}, &ApplicationRollout{}, &ApplicationRolloutList{})
 }
 
 // Resource maps a resource type to a group resource.
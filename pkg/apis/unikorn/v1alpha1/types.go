@@ -268,7 +268,7 @@ type NetworkGeneric struct {
 	DNSNameservers []IPv4Address `json:"dnsNameservers"`
 }
 
-// +kubebuilder:validation:Enum=Available
+// +kubebuilder:validation:Enum=Available;Healthy;Upgrading;Paused;Reconciled
 type ConditionType string
 
 const (
@@ -276,11 +276,24 @@ const (
 	// resource is not ready, or is known to be in a bad state and should
 	// not be used.  When true, while not guaranteed to be fully functional.
 	ConditionAvailable ConditionType = "Available"
+	// ConditionHealthy is false when a resource is available, but one or
+	// more provisioners have reported it to be degraded.
+	ConditionHealthy ConditionType = "Healthy"
+	// ConditionUpgrading is true when one or more applications are being
+	// upgraded to a new version.
+	ConditionUpgrading ConditionType = "Upgrading"
+	// ConditionPaused is true when reconciliation of the resource has been
+	// paused.
+	ConditionPaused ConditionType = "Paused"
+	// ConditionReconciled reflects the outcome of the most recent reconcile,
+	// and in conjunction with the observed generation, whether the controller
+	// has acted upon the latest specification.
+	ConditionReconciled ConditionType = "Reconciled"
 )
 
 // ConditionReason defines the possible reasons of a resource
 // condition.  These are generic and may be used by any condition.
-// +kubebuilder:validation:Enum=Provisioning;Provisioned;Cancelled;Errored;Deprovisioning;Deprovisioned
+// +kubebuilder:validation:Enum=Provisioning;Provisioned;Cancelled;Errored;Deprovisioning;Deprovisioned;Healthy;Degraded;Upgrading;Upgraded;Paused;Resumed
 type ConditionReason string
 
 const (
@@ -305,6 +318,24 @@ const (
 	// indicate we have finished deprovisioning and the Kubernetes
 	// garbage collector can remove the resource.
 	ConditionReasonDeprovisioned ConditionReason = "Deprovisioned"
+	// ConditionReasonHealthy is used by the Healthy condition to indicate
+	// no problems were reported.
+	ConditionReasonHealthy ConditionReason = "Healthy"
+	// ConditionReasonDegraded is used by the Healthy condition to indicate
+	// the resource is usable, but with reduced functionality.
+	ConditionReasonDegraded ConditionReason = "Degraded"
+	// ConditionReasonUpgrading is used by the Upgrading condition to indicate
+	// an upgrade is in progress.
+	ConditionReasonUpgrading ConditionReason = "Upgrading"
+	// ConditionReasonUpgraded is used by the Upgrading condition to indicate
+	// an upgrade has completed.
+	ConditionReasonUpgraded ConditionReason = "Upgraded"
+	// ConditionReasonPaused is used by the Paused condition to indicate
+	// reconciliation is paused.
+	ConditionReasonPaused ConditionReason = "Paused"
+	// ConditionReasonResumed is used by the Paused condition to indicate
+	// reconciliation has resumed.
+	ConditionReasonResumed ConditionReason = "Resumed"
 )
 
 // Condition is a generic condition type for use across all resource types.
@@ -322,6 +353,10 @@ type Condition struct {
 	Reason ConditionReason `json:"reason"`
 	// Human-readable message indicating details about last transition.
 	Message string `json:"message"`
+	// ObservedGeneration is the resource generation the condition was
+	// set for.  If this is less than the resource's generation, then the
+	// condition may be stale.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
 // ApplicationReferenceKind defines the application kind we wish to reference.
@@ -24,6 +24,7 @@ package v1alpha1
 import (
 	net "net"
 
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -80,6 +81,165 @@ func (in *ApplicationReference) DeepCopy() *ApplicationReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRollout) DeepCopyInto(out *ApplicationRollout) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRollout.
+func (in *ApplicationRollout) DeepCopy() *ApplicationRollout {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRollout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApplicationRollout) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRolloutList) DeepCopyInto(out *ApplicationRolloutList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ApplicationRollout, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRolloutList.
+func (in *ApplicationRolloutList) DeepCopy() *ApplicationRolloutList {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRolloutList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApplicationRolloutList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRolloutResources) DeepCopyInto(out *ApplicationRolloutResources) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRolloutResources.
+func (in *ApplicationRolloutResources) DeepCopy() *ApplicationRolloutResources {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRolloutResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRolloutSpec) DeepCopyInto(out *ApplicationRolloutSpec) {
+	*out = *in
+	out.From = in.From
+	out.To = in.To
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.CanaryPercent != nil {
+		in, out := &in.CanaryPercent, &out.CanaryPercent
+		*out = new(int)
+		**out = **in
+	}
+	if in.BatchPercent != nil {
+		in, out := &in.BatchPercent, &out.BatchPercent
+		*out = new(int)
+		**out = **in
+	}
+	if in.PauseOnFailure != nil {
+		in, out := &in.PauseOnFailure, &out.PauseOnFailure
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRolloutSpec.
+func (in *ApplicationRolloutSpec) DeepCopy() *ApplicationRolloutSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRolloutSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRolloutStatus) DeepCopyInto(out *ApplicationRolloutStatus) {
+	*out = *in
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]ApplicationRolloutTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRolloutStatus.
+func (in *ApplicationRolloutStatus) DeepCopy() *ApplicationRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRolloutTarget) DeepCopyInto(out *ApplicationRolloutTarget) {
+	*out = *in
+	if in.UpgradeTime != nil {
+		in, out := &in.UpgradeTime, &out.UpgradeTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRolloutTarget.
+func (in *ApplicationRolloutTarget) DeepCopy() *ApplicationRolloutTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRolloutTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Condition) DeepCopyInto(out *Condition) {
 	*out = *in
@@ -210,6 +370,48 @@ func (in *HelmApplicationRecommendation) DeepCopy() *HelmApplicationRecommendati
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmApplicationSchema) DeepCopyInto(out *HelmApplicationSchema) {
+	*out = *in
+	if in.Inline != nil {
+		in, out := &in.Inline, &out.Inline
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(HelmApplicationSchemaConfigMapReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmApplicationSchema.
+func (in *HelmApplicationSchema) DeepCopy() *HelmApplicationSchema {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmApplicationSchema)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmApplicationSchemaConfigMapReference) DeepCopyInto(out *HelmApplicationSchemaConfigMapReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmApplicationSchemaConfigMapReference.
+func (in *HelmApplicationSchemaConfigMapReference) DeepCopy() *HelmApplicationSchemaConfigMapReference {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmApplicationSchemaConfigMapReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HelmApplicationSpec) DeepCopyInto(out *HelmApplicationSpec) {
 	*out = *in
@@ -303,6 +505,16 @@ func (in *HelmApplicationVersion) DeepCopyInto(out *HelmApplicationVersion) {
 		*out = make([]HelmApplicationParameter, len(*in))
 		copy(*out, *in)
 	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Schema != nil {
+		in, out := &in.Schema, &out.Schema
+		*out = new(HelmApplicationSchema)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Namespace != nil {
 		in, out := &in.Namespace, &out.Namespace
 		*out = new(string)
@@ -81,6 +81,17 @@ func New(client client.Client, options Options) *Driver {
 	}
 }
 
+// Ensure the cd.HealthChecker interface is implemented.
+var _ cd.HealthChecker = &Driver{}
+
+// CheckHealth implements the cd.HealthChecker interface.  ArgoCD is considered
+// reachable if its applications can be listed.
+func (d *Driver) CheckHealth(ctx context.Context) error {
+	var resources argoprojv1.ApplicationList
+
+	return d.client.List(ctx, &resources, &client.ListOptions{Namespace: namespace, Limit: 1})
+}
+
 // clusterName generates a cluster name from a cluster identifier.
 // Due to legacy reasons (backward compatibility) we only use the values in the labels
 // and not the keys.
@@ -364,6 +375,8 @@ func (d *Driver) CreateOrUpdateHelmApplication(ctx context.Context, id *cd.Resou
 	// remote cluster creation, so can allow the rest to provision while it's
 	// still sorting its manager out.
 	if app.AllowDegraded && resource.Status.Health.Status == argoprojv1.Degraded {
+		provisioners.ReportDegraded(ctx, "application "+id.Name+" is degraded")
+
 		return nil
 	}
 
@@ -136,7 +136,11 @@ func TestApplicationCreateHelm(t *testing.T) {
 	application = mustGetApplication(t, tc, id)
 	application.Status.Sync.Status = argoprojv1.Synced
 	assert.NoError(t, tc.client.Update(context.TODO(), application))
-	assert.NoError(t, tc.driver.CreateOrUpdateHelmApplication(context.TODO(), id, app))
+
+	ctx := provisioners.NewContextWithReconcileStatus(context.TODO())
+
+	assert.NoError(t, tc.driver.CreateOrUpdateHelmApplication(ctx, id, app))
+	assert.Equal(t, []string{"application test is degraded"}, provisioners.ReconcileStatusFromContext(ctx).Degraded())
 
 	application = mustGetApplication(t, tc, id)
 	application.Status.Health.Status = argoprojv1.Healthy
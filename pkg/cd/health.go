@@ -0,0 +1,29 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cd
+
+import (
+	"context"
+)
+
+// HealthChecker may be optionally implemented by a driver to report whether
+// the CD backend is reachable, and therefore whether the controller can do
+// anything useful.
+type HealthChecker interface {
+	// CheckHealth returns an error if the backend is unreachable.
+	CheckHealth(ctx context.Context) error
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/unikorn-cloud/core/pkg/cd"
+	"github.com/unikorn-cloud/core/pkg/provisioners"
+	"github.com/unikorn-cloud/core/pkg/util/otel"
+)
+
+// Driver wraps a CD driver and creates a span for every call.
+type Driver struct {
+	driver cd.Driver
+}
+
+// Ensure the Driver and HealthChecker interfaces are implemented.
+var (
+	_ cd.Driver        = &Driver{}
+	_ cd.HealthChecker = &Driver{}
+)
+
+// New returns a driver that creates a span for every call to the underlying
+// driver.
+func New(driver cd.Driver) *Driver {
+	return &Driver{
+		driver: driver,
+	}
+}
+
+// start creates a new span for a driver call.
+//
+//nolint:spancheck
+func (d *Driver) start(ctx context.Context, operation string, id *cd.ResourceIdentifier) (context.Context, trace.Span) {
+	attr := []attribute.KeyValue{
+		attribute.String("cd.driver", string(d.driver.Kind())),
+		attribute.String("cd.operation", operation),
+		attribute.String("cd.resource.name", id.Name),
+	}
+
+	for _, label := range id.Labels {
+		attr = append(attr, attribute.String("cd.resource.label."+label.Name, label.Value))
+	}
+
+	return otel.StartSpan(ctx, "cd "+operation, trace.WithAttributes(attr...))
+}
+
+// end records the outcome of a driver call and ends the span.
+func end(span trace.Span, err error) {
+	provisioners.RecordOutcome(span, "cd.outcome", err)
+
+	span.End()
+}
+
+func (d *Driver) Kind() cd.DriverKind {
+	return d.driver.Kind()
+}
+
+func (d *Driver) ListHelmApplications(ctx context.Context, id *cd.ResourceIdentifier) (map[*cd.ResourceIdentifier]*cd.HelmApplication, error) {
+	ctx, span := d.start(ctx, "ListHelmApplications", id)
+
+	applications, err := d.driver.ListHelmApplications(ctx, id)
+
+	end(span, err)
+
+	return applications, err
+}
+
+func (d *Driver) CreateOrUpdateHelmApplication(ctx context.Context, id *cd.ResourceIdentifier, app *cd.HelmApplication) error {
+	ctx, span := d.start(ctx, "CreateOrUpdateHelmApplication", id)
+
+	err := d.driver.CreateOrUpdateHelmApplication(ctx, id, app)
+
+	end(span, err)
+
+	return err
+}
+
+func (d *Driver) DeleteHelmApplication(ctx context.Context, id *cd.ResourceIdentifier, backgroundDelete bool) error {
+	ctx, span := d.start(ctx, "DeleteHelmApplication", id)
+
+	err := d.driver.DeleteHelmApplication(ctx, id, backgroundDelete)
+
+	end(span, err)
+
+	return err
+}
+
+func (d *Driver) CreateOrUpdateCluster(ctx context.Context, id *cd.ResourceIdentifier, cluster *cd.Cluster) error {
+	ctx, span := d.start(ctx, "CreateOrUpdateCluster", id)
+
+	err := d.driver.CreateOrUpdateCluster(ctx, id, cluster)
+
+	end(span, err)
+
+	return err
+}
+
+func (d *Driver) DeleteCluster(ctx context.Context, id *cd.ResourceIdentifier) error {
+	ctx, span := d.start(ctx, "DeleteCluster", id)
+
+	err := d.driver.DeleteCluster(ctx, id)
+
+	end(span, err)
+
+	return err
+}
+
+// CheckHealth implements the cd.HealthChecker interface, health checks are
+// not traced as they are frequent and uninteresting.
+func (d *Driver) CheckHealth(ctx context.Context) error {
+	if checker, ok := d.driver.(cd.HealthChecker); ok {
+		return checker.CheckHealth(ctx)
+	}
+
+	return nil
+}
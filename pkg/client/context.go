@@ -23,6 +23,8 @@ import (
 	"github.com/unikorn-cloud/core/pkg/cd"
 	"github.com/unikorn-cloud/core/pkg/errors"
 
+	"k8s.io/client-go/rest"
+
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -35,6 +37,10 @@ type ClusterContext struct {
 	// chain to the next remote cluster.  It is also used when some combination
 	// of Helm application/ArgoCD is broken and needs manual intervention.
 	Client client.Client
+	// RESTConfig is the configuration used to create the client.  This may
+	// be used to create typed clients where controller-runtime lacks the
+	// functionality e.g. reading pod logs.
+	RESTConfig *rest.Config
 	// ID is the unique remote cluster ID as cunsumed by the CD layer.
 	// This is only set on invocation of a remote cluster provisioner.
 	ID *cd.ResourceIdentifier
@@ -44,6 +50,15 @@ type ClusterContext struct {
 	// Port is the Kubernetes endpoint port. This is only set on
 	// invocation of a remote cluster provisioner.
 	Port string
+	// Discovery describes the cluster's capabilities.  This is only set on
+	// invocation of a remote cluster provisioner.
+	Discovery *Discovery
+}
+
+// HasAPI returns true if the cluster serves the API group version and kind.
+// This will always return false if discovery information is not available.
+func (c *ClusterContext) HasAPI(group, version, kind string) bool {
+	return c.Discovery.HasAPI(group, version, kind)
 }
 
 type key int
@@ -59,6 +74,10 @@ const (
 
 	// namespaceKey is used to propagate the process's namespace to clients.
 	namespaceKey
+
+	// provisionerReaderKey is an uncached reader that is scoped to the cluster
+	// containing the current provisioner.
+	provisionerReaderKey
 )
 
 func NewContextWithProvisionerClient(ctx context.Context, client client.Client) context.Context {
@@ -75,6 +94,26 @@ func ProvisionerClientFromContext(ctx context.Context) (client.Client, error) {
 	return nil, errors.ErrInvalidContext
 }
 
+// NewContextWithProvisionerReader adds an uncached reader for the cluster containing
+// the current provisioner.  This should be used to read resources that aren't watched
+// by the controller, as the cached client would start an informer for every type it
+// reads, cluster wide.
+func NewContextWithProvisionerReader(ctx context.Context, reader client.Reader) context.Context {
+	return context.WithValue(ctx, provisionerReaderKey, reader)
+}
+
+// ProvisionerReaderFromContext returns the uncached reader for the cluster containing
+// the current provisioner.
+func ProvisionerReaderFromContext(ctx context.Context) (client.Reader, error) {
+	if value := ctx.Value(provisionerReaderKey); value != nil {
+		if reader, ok := value.(client.Reader); ok {
+			return reader, nil
+		}
+	}
+
+	return nil, errors.ErrInvalidContext
+}
+
 func NewContextWithCluster(ctx context.Context, remote *ClusterContext) context.Context {
 	return context.WithValue(ctx, clusterKey, remote)
 }
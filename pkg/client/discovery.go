@@ -0,0 +1,135 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
+	apimachineryversion "k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Discovery is a snapshot of a cluster's capabilities, allowing generators to
+// tailor what they install based on Kubernetes version, installed APIs and the
+// like.
+type Discovery struct {
+	// ServerVersion is the Kubernetes server version.
+	ServerVersion *apimachineryversion.Info
+	// Resources maps from group version to the resources it provides.
+	Resources map[schema.GroupVersion][]metav1.APIResource
+	// Architectures maps from node architecture to the number of nodes
+	// with that architecture.  This will be empty if the client is not
+	// permitted to list nodes.
+	Architectures map[string]int
+}
+
+// NewDiscovery takes a snapshot of the cluster's capabilities.  Failure to discover
+// individual API groups e.g. due to a broken aggregated API, is not considered fatal
+// and those groups will simply not be present.
+func NewDiscovery(ctx context.Context, discoveryClient discovery.DiscoveryInterface, c client.Client) (*Discovery, error) {
+	serverVersion, err := discoveryClient.ServerVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	_, resourceLists, err := discoveryClient.ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+
+	d := &Discovery{
+		ServerVersion: serverVersion,
+		Resources:     map[schema.GroupVersion][]metav1.APIResource{},
+		Architectures: map[string]int{},
+	}
+
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		d.Resources[gv] = resourceList.APIResources
+	}
+
+	var nodes corev1.NodeList
+
+	if err := c.List(ctx, &nodes); err != nil {
+		if !kerrors.IsForbidden(err) {
+			return nil, err
+		}
+	}
+
+	for i := range nodes.Items {
+		d.Architectures[nodes.Items[i].Status.NodeInfo.Architecture]++
+	}
+
+	return d, nil
+}
+
+// HasGroupVersion returns true if the API group version is served.
+func (d *Discovery) HasGroupVersion(group, version string) bool {
+	if d == nil {
+		return false
+	}
+
+	_, ok := d.Resources[schema.GroupVersion{Group: group, Version: version}]
+
+	return ok
+}
+
+// HasAPI returns true if the API group version serves the kind.
+func (d *Discovery) HasAPI(group, version, kind string) bool {
+	if d == nil {
+		return false
+	}
+
+	for _, resource := range d.Resources[schema.GroupVersion{Group: group, Version: version}] {
+		if resource.Kind == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ServerVersionAtLeast returns true if the Kubernetes version is at least that
+// provided e.g. "1.31".
+func (d *Discovery) ServerVersionAtLeast(minimum string) (bool, error) {
+	if d == nil || d.ServerVersion == nil {
+		return false, nil
+	}
+
+	current, err := version.ParseGeneric(d.ServerVersion.GitVersion)
+	if err != nil {
+		return false, err
+	}
+
+	required, err := version.ParseGeneric(minimum)
+	if err != nil {
+		return false, err
+	}
+
+	return current.AtLeast(required), nil
+}
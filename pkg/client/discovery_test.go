@@ -0,0 +1,103 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	coreclient "github.com/unikorn-cloud/core/pkg/client"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newNode(name, arch string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: corev1.NodeStatus{
+			NodeInfo: corev1.NodeSystemInfo{
+				Architecture: arch,
+			},
+		},
+	}
+}
+
+// TestDiscovery tests discovery snapshots are correctly populated and queryable.
+func TestDiscovery(t *testing.T) {
+	t.Parallel()
+
+	discoveryClient := &fakediscovery.FakeDiscovery{
+		Fake: &clienttesting.Fake{
+			Resources: []*metav1.APIResourceList{
+				{
+					GroupVersion: "v1",
+					APIResources: []metav1.APIResource{
+						{Name: "configmaps", Kind: "ConfigMap"},
+					},
+				},
+				{
+					GroupVersion: "gateway.networking.k8s.io/v1",
+					APIResources: []metav1.APIResource{
+						{Name: "gateways", Kind: "Gateway"},
+					},
+				},
+			},
+		},
+		FakedServerVersion: &version.Info{
+			GitVersion: "v1.31.2",
+		},
+	}
+
+	c := fake.NewClientBuilder().WithObjects(newNode("a", "amd64"), newNode("b", "amd64"), newNode("c", "arm64")).Build()
+
+	d, err := coreclient.NewDiscovery(context.Background(), discoveryClient, c)
+	require.NoError(t, err)
+
+	assert.True(t, d.HasAPI("", "v1", "ConfigMap"))
+	assert.True(t, d.HasAPI("gateway.networking.k8s.io", "v1", "Gateway"))
+	assert.False(t, d.HasAPI("gateway.networking.k8s.io", "v1", "HTTPRoute"))
+	assert.False(t, d.HasAPI("snapshot.storage.k8s.io", "v1", "VolumeSnapshot"))
+	assert.True(t, d.HasGroupVersion("gateway.networking.k8s.io", "v1"))
+
+	ok, err := d.ServerVersionAtLeast("1.30")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = d.ServerVersionAtLeast("1.32")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.Equal(t, map[string]int{"amd64": 2, "arm64": 1}, d.Architectures)
+
+	// Missing discovery information is handled gracefully.
+	cluster := &coreclient.ClusterContext{}
+	assert.False(t, cluster.HasAPI("", "v1", "ConfigMap"))
+
+	cluster.Discovery = d
+	assert.True(t, cluster.HasAPI("", "v1", "ConfigMap"))
+}
@@ -0,0 +1,392 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// DefaultRemoteClientTTL is how long a remote client will be cached for
+	// before being recreated, this allows things like discovery information
+	// to be refreshed periodically.
+	DefaultRemoteClientTTL = 10 * time.Minute
+
+	// DefaultRemoteClientCacheSize is the maximum number of remote clients
+	// that will be cached at any one time.
+	DefaultRemoteClientCacheSize = 256
+
+	// DefaultDiscoveryFailureTTL is how long a discovery failure will be cached
+	// for before the cluster is probed again.  Unreachable clusters take a full
+	// client timeout to fail, so this avoids paying that on every reconcile.
+	DefaultDiscoveryFailureTTL = time.Minute
+)
+
+//nolint:gochecknoglobals
+var (
+	remoteClientCacheHits = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "unikorn_remote_client_cache_hits_total",
+		Help: "Number of remote cluster client cache hits.",
+	})
+
+	remoteClientCacheMisses = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "unikorn_remote_client_cache_misses_total",
+		Help: "Number of remote cluster client cache misses.",
+	})
+
+	remoteClientCacheEvictions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "unikorn_remote_client_cache_evictions_total",
+		Help: "Number of remote cluster client cache evictions by reason.",
+	}, []string{"reason"})
+
+	// defaultRemoteClientCache is shared across all reconciles in the process.
+	defaultRemoteClientCache = NewRemoteClientCache(DefaultRemoteClientTTL, DefaultRemoteClientCacheSize)
+)
+
+//nolint:gochecknoinits
+func init() {
+	metrics.Registry.MustRegister(remoteClientCacheHits, remoteClientCacheMisses, remoteClientCacheEvictions)
+}
+
+// remoteClientCacheEntry is a single cached client.
+type remoteClientCacheEntry struct {
+	// client is the cached client.
+	client client.Client
+	// restConfig is the REST configuration the client was created with.
+	restConfig *rest.Config
+	// discovery is lazily populated on first use.
+	discovery *Discovery
+	// discoveryError is the result of the last failed discovery.
+	discoveryError error
+	// discoveryRetry is when discovery may be reattempted after a failure.
+	discoveryRetry time.Time
+	// expires is when the entry becomes invalid.
+	expires time.Time
+	// lastUsed is used to evict the least recently used entry when the
+	// cache is full.
+	lastUsed time.Time
+}
+
+// RemoteClientCache caches remote cluster clients across reconciles.  Creating a
+// client is expensive, it involves API discovery and TLS handshakes, so this avoids
+// doing so for every remote cluster, for every reconcile.  Clients are keyed on a
+// hash of the Kubernetes configuration, so any change in endpoint or credentials
+// results in a new client.  Entries expire after a TTL, and are invalidated when
+// the API reports an authentication failure e.g. due to credential rotation.
+type RemoteClientCache struct {
+	// lock provides synchronization around concurrency.
+	lock sync.Mutex
+	// entries maps from configuration hash to client.
+	entries map[string]*remoteClientCacheEntry
+	// ttl is how long entries are valid for.
+	ttl time.Duration
+	// size is the maximum number of entries.
+	size int
+	// discoveryFailureTTL is how long discovery failures are cached for.
+	discoveryFailureTTL time.Duration
+	// group ensures only one client is created at a time per key.
+	group singleflight.Group
+}
+
+// NewRemoteClientCache returns a new cache with the specified TTL and maximum size.
+func NewRemoteClientCache(ttl time.Duration, size int) *RemoteClientCache {
+	return &RemoteClientCache{
+		entries:             map[string]*remoteClientCacheEntry{},
+		ttl:                 ttl,
+		size:                size,
+		discoveryFailureTTL: DefaultDiscoveryFailureTTL,
+	}
+}
+
+// WithDiscoveryFailureTTL sets how long discovery failures are cached for.
+func (c *RemoteClientCache) WithDiscoveryFailureTTL(ttl time.Duration) *RemoteClientCache {
+	c.discoveryFailureTTL = ttl
+
+	return c
+}
+
+// DefaultRemoteClientCache returns the process-wide remote client cache.
+func DefaultRemoteClientCache() *RemoteClientCache {
+	return defaultRemoteClientCache
+}
+
+// remoteClientCacheKey generates a unique key for a configuration.
+func remoteClientCacheKey(config *clientcmdapi.Config) (string, error) {
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
+
+// roundTripperFunc allows a function to be used as a http.RoundTripper.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// invalidateOnUnauthorized wraps the transport so that any authentication
+// failure evicts the client from the cache, and the next lookup will recreate
+// it with fresh credentials.
+func (c *RemoteClientCache) invalidateOnUnauthorized(key string) func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			response, err := rt.RoundTrip(r)
+			if err == nil && response.StatusCode == http.StatusUnauthorized {
+				c.evict(key, "unauthorized")
+			}
+
+			return response, err
+		})
+	}
+}
+
+// evict removes an entry from the cache.
+func (c *RemoteClientCache) evict(key, reason string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.evictLocked(key, reason)
+}
+
+func (c *RemoteClientCache) evictLocked(key, reason string) {
+	if _, ok := c.entries[key]; !ok {
+		return
+	}
+
+	delete(c.entries, key)
+
+	remoteClientCacheEvictions.WithLabelValues(reason).Inc()
+}
+
+// makeRoomLocked removes any expired entries, and if the cache is still full, evicts
+// the least recently used entry.
+func (c *RemoteClientCache) makeRoomLocked(now time.Time) {
+	var lruKey string
+
+	var lru *remoteClientCacheEntry
+
+	for key, entry := range c.entries {
+		if now.After(entry.expires) {
+			c.evictLocked(key, "expired")
+
+			continue
+		}
+
+		if lru == nil || entry.lastUsed.Before(lru.lastUsed) {
+			lruKey = key
+			lru = entry
+		}
+	}
+
+	if len(c.entries) >= c.size && lru != nil {
+		c.evictLocked(lruKey, "capacity")
+	}
+}
+
+// Get returns a client for the configuration, either from the cache, or by
+// creating a new one.  The REST configuration used to create the client is also
+// returned.
+func (c *RemoteClientCache) Get(config *clientcmdapi.Config) (client.Client, *rest.Config, error) {
+	key, err := remoteClientCacheKey(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return c.get(key, config)
+}
+
+// lookup returns an unexpired entry from the cache, evicting any expired one.
+func (c *RemoteClientCache) lookup(key string) *remoteClientCacheEntry {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+
+	if !now.Before(entry.expires) {
+		c.evictLocked(key, "expired")
+
+		return nil
+	}
+
+	remoteClientCacheHits.Inc()
+
+	entry.lastUsed = now
+
+	return entry
+}
+
+// create builds a new client and inserts it into the cache.  Client creation is
+// slow, so is done outside of the lock to avoid serializing unrelated clusters.
+func (c *RemoteClientCache) create(key string, config *clientcmdapi.Config) (*remoteClientCacheEntry, error) {
+	remoteClientCacheMisses.Inc()
+
+	restConfig, err := clientcmd.NewDefaultClientConfig(*config, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	restConfig.Wrap(c.invalidateOnUnauthorized(key))
+
+	cli, err := client.New(restConfig, client.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := time.Now()
+
+	c.makeRoomLocked(now)
+
+	entry := &remoteClientCacheEntry{
+		client:     cli,
+		restConfig: restConfig,
+		expires:    now.Add(c.ttl),
+		lastUsed:   now,
+	}
+
+	c.entries[key] = entry
+
+	return entry, nil
+}
+
+func (c *RemoteClientCache) get(key string, config *clientcmdapi.Config) (client.Client, *rest.Config, error) {
+	if entry := c.lookup(key); entry != nil {
+		return entry.client, entry.restConfig, nil
+	}
+
+	// Concurrent misses for the same cluster share a single creation, and the
+	// cache is checked again in case another caller has just populated it.
+	result, err, _ := c.group.Do(key, func() (any, error) {
+		if entry := c.lookup(key); entry != nil {
+			return entry, nil
+		}
+
+		return c.create(key, config)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	//nolint:forcetypeassert
+	entry := result.(*remoteClientCacheEntry)
+
+	return entry.client, entry.restConfig, nil
+}
+
+// Discover returns a discovery snapshot for the cluster.  This is cached along
+// with the client, so is refreshed at the same time.  Failures are also cached,
+// for a shorter period, so unreachable clusters aren't probed on every call.
+func (c *RemoteClientCache) Discover(ctx context.Context, config *clientcmdapi.Config) (*Discovery, error) {
+	key, err := remoteClientCacheKey(config)
+	if err != nil {
+		return nil, err
+	}
+
+	cli, restConfig, err := c.get(key, config)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+
+	if entry, ok := c.entries[key]; ok {
+		if entry.discovery != nil {
+			c.lock.Unlock()
+
+			return entry.discovery, nil
+		}
+
+		if entry.discoveryError != nil && time.Now().Before(entry.discoveryRetry) {
+			c.lock.Unlock()
+
+			return nil, entry.discoveryError
+		}
+	}
+
+	c.lock.Unlock()
+
+	// Discovery is slow, so do it outside of the lock, the worst that can happen
+	// is concurrent callers do it at the same time.
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := NewDiscovery(ctx, discoveryClient, cli)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if entry, ok := c.entries[key]; ok {
+		entry.discovery = d
+		entry.discoveryError = err
+		entry.discoveryRetry = time.Now().Add(c.discoveryFailureTTL)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// Invalidate removes any client associated with the configuration e.g. when the
+// caller knows the credentials have been revoked.
+func (c *RemoteClientCache) Invalidate(config *clientcmdapi.Config) error {
+	key, err := remoteClientCacheKey(config)
+	if err != nil {
+		return err
+	}
+
+	c.evict(key, "invalidated")
+
+	return nil
+}
+
+// Len returns the number of cached clients.
+func (c *RemoteClientCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return len(c.entries)
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	coreclient "github.com/unikorn-cloud/core/pkg/client"
+
+	corev1 "k8s.io/api/core/v1"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newConfig(server string) *clientcmdapi.Config {
+	return &clientcmdapi.Config{
+		Clusters: map[string]*clientcmdapi.Cluster{
+			"test": {
+				Server: server,
+			},
+		},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{
+			"test": {
+				Token: "token",
+			},
+		},
+		Contexts: map[string]*clientcmdapi.Context{
+			"test": {
+				Cluster:  "test",
+				AuthInfo: "test",
+			},
+		},
+		CurrentContext: "test",
+	}
+}
+
+// TestRemoteClientCacheHit tests clients are reused for identical configurations
+// and recreated for differing ones.
+func TestRemoteClientCacheHit(t *testing.T) {
+	t.Parallel()
+
+	cache := coreclient.NewRemoteClientCache(time.Minute, 2)
+
+	c1, _, err := cache.Get(newConfig("https://foo:6443"))
+	require.NoError(t, err)
+
+	c2, _, err := cache.Get(newConfig("https://foo:6443"))
+	require.NoError(t, err)
+
+	assert.Same(t, c1, c2)
+
+	c3, _, err := cache.Get(newConfig("https://bar:6443"))
+	require.NoError(t, err)
+
+	assert.NotSame(t, c1, c3)
+	assert.Equal(t, 2, cache.Len())
+}
+
+// TestRemoteClientCacheCapacity tests the least recently used client is evicted
+// when the cache is full.
+func TestRemoteClientCacheCapacity(t *testing.T) {
+	t.Parallel()
+
+	cache := coreclient.NewRemoteClientCache(time.Minute, 2)
+
+	c1, _, err := cache.Get(newConfig("https://foo:6443"))
+	require.NoError(t, err)
+
+	_, _, err = cache.Get(newConfig("https://bar:6443"))
+	require.NoError(t, err)
+
+	// Touch the first so the second is the least recently used.
+	_, _, err = cache.Get(newConfig("https://foo:6443"))
+	require.NoError(t, err)
+
+	_, _, err = cache.Get(newConfig("https://baz:6443"))
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, cache.Len())
+
+	c2, _, err := cache.Get(newConfig("https://foo:6443"))
+	require.NoError(t, err)
+
+	assert.Same(t, c1, c2)
+}
+
+// TestRemoteClientCacheExpiry tests clients are recreated after the TTL.
+func TestRemoteClientCacheExpiry(t *testing.T) {
+	t.Parallel()
+
+	cache := coreclient.NewRemoteClientCache(time.Millisecond, 2)
+
+	c1, _, err := cache.Get(newConfig("https://foo:6443"))
+	require.NoError(t, err)
+
+	time.Sleep(2 * time.Millisecond)
+
+	c2, _, err := cache.Get(newConfig("https://foo:6443"))
+	require.NoError(t, err)
+
+	assert.NotSame(t, c1, c2)
+}
+
+// TestRemoteClientCacheInvalidate tests explicit invalidation.
+func TestRemoteClientCacheInvalidate(t *testing.T) {
+	t.Parallel()
+
+	cache := coreclient.NewRemoteClientCache(time.Minute, 2)
+
+	config := newConfig("https://foo:6443")
+
+	_, _, err := cache.Get(config)
+	require.NoError(t, err)
+
+	require.NoError(t, cache.Invalidate(config))
+	assert.Equal(t, 0, cache.Len())
+}
+
+// TestRemoteClientCacheUnauthorized tests clients are evicted when the remote
+// rejects the credentials.
+func TestRemoteClientCacheUnauthorized(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	cache := coreclient.NewRemoteClientCache(time.Minute, 2)
+
+	c, _, err := cache.Get(newConfig(server.URL))
+	require.NoError(t, err)
+	assert.Equal(t, 1, cache.Len())
+
+	var service corev1.Service
+
+	require.Error(t, c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "kubernetes"}, &service))
+	assert.Equal(t, 0, cache.Len())
+}
+
+// TestRemoteClientCacheConcurrent tests concurrent lookups for the same cluster
+// share a single client.
+func TestRemoteClientCacheConcurrent(t *testing.T) {
+	t.Parallel()
+
+	cache := coreclient.NewRemoteClientCache(time.Minute, 2)
+
+	const callers = 16
+
+	clients := make([]client.Client, callers)
+
+	var wg sync.WaitGroup
+
+	for i := range callers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			c, _, err := cache.Get(newConfig("https://foo:6443"))
+			assert.NoError(t, err)
+
+			clients[i] = c
+		}()
+	}
+
+	wg.Wait()
+
+	for i := range callers {
+		assert.Same(t, clients[0], clients[i])
+	}
+
+	assert.Equal(t, 1, cache.Len())
+}
+
+// TestRemoteClientCacheDiscoveryFailure tests discovery failures are cached, and
+// the cluster is probed again once they expire.
+func TestRemoteClientCacheDiscoveryFailure(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	cache := coreclient.NewRemoteClientCache(time.Minute, 2).WithDiscoveryFailureTTL(100 * time.Millisecond)
+
+	config := newConfig(server.URL)
+
+	_, err := cache.Discover(context.Background(), config)
+	require.Error(t, err)
+
+	probes := requests.Load()
+	assert.NotZero(t, probes)
+
+	_, err = cache.Discover(context.Background(), config)
+	require.Error(t, err)
+	assert.Equal(t, probes, requests.Load())
+
+	time.Sleep(150 * time.Millisecond)
+
+	_, err = cache.Discover(context.Background(), config)
+	require.Error(t, err)
+	assert.Greater(t, requests.Load(), probes)
+}
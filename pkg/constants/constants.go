@@ -101,6 +101,34 @@ const (
 	// ApplicationIDLabel is used to lookup applications based on their ID.
 	ApplicationIDLabel = "unikorn-cloud.org/application-id"
 
+	// JobLabel is applied to jobs created by the job provisioner so that all
+	// generations of a job can be found and cleaned up.
+	JobLabel = "unikorn-cloud.org/job"
+
+	// JobOwnerLabel is applied to jobs created by the job provisioner to record
+	// the UID of the resource that owns them, so a recreated resource doesn't
+	// inherit jobs from its predecessor.
+	JobOwnerLabel = "unikorn-cloud.org/job-owner"
+
+	// NamespacePolicyLabel is applied to policy objects created by the namespace
+	// provisioner so that ones no longer required can be found and removed.  The
+	// value identifies the provisioner that created them.
+	NamespacePolicyLabel = "unikorn-cloud.org/namespace-policy"
+
+	// NamespacePolicyOwnerLabel is applied to policy objects created by the
+	// namespace provisioner to record the UID of the resource that owns them.
+	NamespacePolicyOwnerLabel = "unikorn-cloud.org/namespace-policy-owner"
+
+	// ReplicationSourceAnnotation is applied to secrets and config maps copied
+	// by the replication provisioner to record where they came from, and that
+	// we own them.
+	ReplicationSourceAnnotation = "unikorn-cloud.org/replication-source"
+
+	// ReplicationOwnerAnnotation is applied to secrets and config maps copied
+	// by the replication provisioner to record the UID of the resource that
+	// caused their creation.
+	ReplicationOwnerAnnotation = "unikorn-cloud.org/replication-owner"
+
 	// ConfigurationHashAnnotation is used where application owners refuse to
 	// poll configuration updates and we (and all other users) are forced into
 	// manually restarting services based on a Deployment/DaemonSet changing.
@@ -118,6 +146,34 @@ const (
 	// are subject to quotas and link to an allocation.
 	AllocationAnnotation = "unikorn-cloud.org/allocation-id"
 
+	// ApplicationVersionAnnotationPrefix is used to record the version of an
+	// application last successfully provisioned for a resource, keyed by the
+	// application name.  This is used to detect upgrades where the CD driver
+	// is unable to report the version.
+	ApplicationVersionAnnotationPrefix = "application-version.unikorn-cloud.org/"
+
+	// MigrationVersionAnnotation records the version of the last migration
+	// applied to a resource, so migrations are only ever applied once.
+	MigrationVersionAnnotation = "unikorn-cloud.org/migration-version"
+
+	// PausedAnnotation pauses reconciliation of a resource, the value is the
+	// reason why, which is reported in the Paused condition.
+	PausedAnnotation = "unikorn-cloud.org/paused"
+
+	// PausedUntilAnnotation optionally limits how long a resource is paused
+	// for, it's an RFC3339 time after which reconciliation resumes.
+	PausedUntilAnnotation = "unikorn-cloud.org/paused-until"
+
+	// MaintenanceWindowAnnotation overrides the controller's maintenance
+	// windows for a resource, it's in the form "<schedule>;<duration>"
+	// e.g. "0 2 * * 6;4h".
+	MaintenanceWindowAnnotation = "unikorn-cloud.org/maintenance-window"
+
+	// RolloutAdmittedAnnotation is applied to resources by the rollout reconciler
+	// when they are admitted to an application rollout, this triggers a reconcile
+	// so they can pick up the new version.  The value is the UID of the rollout.
+	RolloutAdmittedAnnotation = "unikorn-cloud.org/rollout-admitted"
+
 	// ReferencedResourceKindLabel is used when a resource refers to another,
 	// but not necessarily a Kubernetes resource.  It has the added benefit it
 	// can be used as a label selector.
@@ -136,6 +192,10 @@ const (
 	// and do other complex logic.
 	Finalizer = "unikorn"
 
+	// FieldManager is used to identify fields owned by Unikorn when using
+	// server-side apply.
+	FieldManager = "unikorn"
+
 	// DefaultYieldTimeout allows N seconds for a provisioner to do its thing
 	// and report a healthy status before yielding and giving someone else
 	// a go.
@@ -0,0 +1,130 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/unikorn-cloud/core/pkg/cd"
+	"github.com/unikorn-cloud/core/pkg/manager/options"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// healthCheckTimeout bounds how long any individual check may take.
+	healthCheckTimeout = 5 * time.Second
+)
+
+var (
+	// ErrCacheNotSynced is raised when the informer cache has not synced.
+	ErrCacheNotSynced = errors.New("cache not synced")
+
+	// ErrNotLeader is raised when the process has not been elected leader.
+	ErrNotLeader = errors.New("not elected leader")
+)
+
+// HealthCheckRegisterer may be optionally implemented by a ControllerFactory
+// to add its own liveness and readiness checks.
+type HealthCheckRegisterer interface {
+	// AddHealthChecks adds any checks to the manager, typically with
+	// AddHealthzCheck and AddReadyzCheck.
+	AddHealthChecks(manager manager.Manager) error
+}
+
+// CacheSyncedCheck is ready when the informer cache has synced.
+func CacheSyncedCheck(cache cache.Cache) healthz.Checker {
+	return func(r *http.Request) error {
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		defer cancel()
+
+		if !cache.WaitForCacheSync(ctx) {
+			return ErrCacheNotSynced
+		}
+
+		return nil
+	}
+}
+
+// LeaderCheck is ready when the process has been elected leader.
+func LeaderCheck(elected <-chan struct{}) healthz.Checker {
+	return func(_ *http.Request) error {
+		select {
+		case <-elected:
+			return nil
+		default:
+			return ErrNotLeader
+		}
+	}
+}
+
+// DriverCheck is ready when the CD driver's backend is reachable.  Drivers that
+// do not implement cd.HealthChecker are assumed to be healthy.
+func DriverCheck(driver cd.Driver) healthz.Checker {
+	return func(r *http.Request) error {
+		checker, ok := driver.(cd.HealthChecker)
+		if !ok {
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		defer cancel()
+
+		return checker.CheckHealth(ctx)
+	}
+}
+
+// addHealthChecks registers the standard liveness and readiness checks.
+func addHealthChecks(o *options.Options, manager manager.Manager) error {
+	if err := manager.AddHealthzCheck("ping", healthz.Ping); err != nil {
+		return err
+	}
+
+	if err := manager.AddReadyzCheck("cache", CacheSyncedCheck(manager.GetCache())); err != nil {
+		return err
+	}
+
+	if o.ReadyWhenLeader {
+		if err := manager.AddReadyzCheck("leader", LeaderCheck(manager.Elected())); err != nil {
+			return err
+		}
+	}
+
+	// Use an uncached client, we want to know the API is reachable, and
+	// don't want to start an informer for the sake of it.
+	c, err := client.New(manager.GetConfig(), client.Options{Scheme: manager.GetScheme()})
+	if err != nil {
+		return err
+	}
+
+	driver, err := newDriver(o, c)
+	if err != nil {
+		return err
+	}
+
+	if err := manager.AddReadyzCheck("cd-driver", DriverCheck(driver)); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unikorn-cloud/core/pkg/cd"
+	"github.com/unikorn-cloud/core/pkg/cd/argocd"
+	"github.com/unikorn-cloud/core/pkg/manager"
+)
+
+// unhealthyDriver is a CD driver whose backend is unreachable.
+type unhealthyDriver struct {
+	cd.Driver
+}
+
+func (*unhealthyDriver) CheckHealth(_ context.Context) error {
+	return errUnhandled
+}
+
+func newHealthRequest() *http.Request {
+	return httptest.NewRequest(http.MethodGet, "/readyz", nil)
+}
+
+// TestLeaderCheck tests readiness reflects leader election.
+func TestLeaderCheck(t *testing.T) {
+	t.Parallel()
+
+	elected := make(chan struct{})
+
+	check := manager.LeaderCheck(elected)
+
+	require.ErrorIs(t, check(newHealthRequest()), manager.ErrNotLeader)
+
+	close(elected)
+
+	require.NoError(t, check(newHealthRequest()))
+}
+
+// TestDriverCheck tests readiness reflects CD driver reachability.
+func TestDriverCheck(t *testing.T) {
+	t.Parallel()
+
+	tc := mustNewTestContext(t)
+
+	require.NoError(t, manager.DriverCheck(argocd.New(tc.client, argocd.Options{}))(newHealthRequest()))
+	require.ErrorIs(t, manager.DriverCheck(&unhealthyDriver{})(newHealthRequest()), errUnhandled)
+}
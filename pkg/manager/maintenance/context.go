@@ -0,0 +1,42 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"context"
+)
+
+type contextKeyType int
+
+//nolint:gochecknoglobals
+var contextKey contextKeyType
+
+// NewContext records whether disruptive changes are allowed for the resource
+// being reconciled.
+func NewContext(ctx context.Context, allowed bool) context.Context {
+	return context.WithValue(ctx, contextKey, allowed)
+}
+
+// DisruptionAllowed returns whether disruptive changes, for example application
+// upgrades, are allowed.  If not set then they are.
+func DisruptionAllowed(ctx context.Context) bool {
+	if allowed, ok := ctx.Value(contextKey).(bool); ok {
+		return allowed
+	}
+
+	return true
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrSchedule is raised when a schedule cannot be parsed.
+	ErrSchedule = errors.New("invalid schedule")
+)
+
+const (
+	// searchLimit bounds how far into the future we look for a schedule
+	// match, impossible schedules e.g. 30th February will never match.
+	searchLimit = 5 * 366 * 24 * time.Hour
+)
+
+// Schedule is a standard 5 field cron schedule, minute, hour, day of month,
+// month and day of week.  Fields may be "*", a value, a range "a-b", a step
+// "*/n" or "a-b/n", or a comma separated list of these.  Like cron, if both day
+// of month and day of week are restricted, either may match.  Schedules are
+// evaluated in the location of the time they are matched against, typically UTC.
+type Schedule struct {
+	// spec is the original specification.
+	spec string
+
+	minute     uint64
+	hour       uint64
+	dayOfMonth uint64
+	month      uint64
+	dayOfWeek  uint64
+
+	// dayOfMonthAny and dayOfWeekAny are set when unrestricted.
+	dayOfMonthAny bool
+	dayOfWeekAny  bool
+}
+
+// parseRange parses a value or range.
+func parseRange(in string, lower, upper int) (int, int, error) {
+	if in == "*" {
+		return lower, upper, nil
+	}
+
+	first, last, isRange := strings.Cut(in, "-")
+
+	start, err := strconv.Atoi(first)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	end := start
+
+	if isRange {
+		if end, err = strconv.Atoi(last); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if start < lower || end > upper || start > end {
+		return 0, 0, fmt.Errorf("%w: %s out of range %d-%d", ErrSchedule, in, lower, upper)
+	}
+
+	return start, end, nil
+}
+
+// parseField parses a single field into a bit set of allowed values.
+func parseField(in string, lower, upper int) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(in, ",") {
+		values, stepValue, isStep := strings.Cut(part, "/")
+
+		step := 1
+
+		if isStep {
+			var err error
+
+			if step, err = strconv.Atoi(stepValue); err != nil || step < 1 {
+				return 0, fmt.Errorf("%w: invalid step %s", ErrSchedule, part)
+			}
+		}
+
+		start, end, err := parseRange(values, lower, upper)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s: %w", ErrSchedule, part, err)
+		}
+
+		// A single value with a step means from that value to the end.
+		if isStep && !strings.Contains(values, "-") {
+			end = upper
+		}
+
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+
+	return bits, nil
+}
+
+// ParseSchedule parses a cron schedule.
+func ParseSchedule(spec string) (*Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: %s: expected 5 fields", ErrSchedule, spec)
+	}
+
+	s := &Schedule{
+		spec:          spec,
+		dayOfMonthAny: fields[2] == "*",
+		dayOfWeekAny:  fields[4] == "*",
+	}
+
+	targets := []struct {
+		bits  *uint64
+		lower int
+		upper int
+	}{
+		{&s.minute, 0, 59},
+		{&s.hour, 0, 23},
+		{&s.dayOfMonth, 1, 31},
+		{&s.month, 1, 12},
+		{&s.dayOfWeek, 0, 7},
+	}
+
+	for i, target := range targets {
+		bits, err := parseField(fields[i], target.lower, target.upper)
+		if err != nil {
+			return nil, err
+		}
+
+		*target.bits = bits
+	}
+
+	// Sunday may be specified as 0 or 7.
+	if s.dayOfWeek&(1<<7) != 0 {
+		s.dayOfWeek |= 1
+	}
+
+	return s, nil
+}
+
+// String returns the original specification.
+func (s *Schedule) String() string {
+	return s.spec
+}
+
+// matchesDay returns whether the schedule matches the day.
+func (s *Schedule) matchesDay(t time.Time) bool {
+	if s.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+
+	dayOfMonth := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+
+	if s.dayOfMonthAny || s.dayOfWeekAny {
+		return dayOfMonth && dayOfWeek
+	}
+
+	return dayOfMonth || dayOfWeek
+}
+
+// Matches returns whether the schedule fires at the minute of the given time.
+func (s *Schedule) Matches(t time.Time) bool {
+	return s.matchesDay(t) && s.hour&(1<<uint(t.Hour())) != 0 && s.minute&(1<<uint(t.Minute())) != 0
+}
+
+// Next returns the first time after the one given that the schedule fires, or
+// the zero time if it never will.
+func (s *Schedule) Next(t time.Time) time.Time {
+	limit := t.Add(searchLimit)
+
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	for t.Before(limit) {
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+
+			continue
+		}
+
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+
+			continue
+		}
+
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// previous returns the last time at or before the one given that the schedule
+// fires, or the zero time if it didn't after the limit.
+func (s *Schedule) previous(t, limit time.Time) time.Time {
+	t = t.Truncate(time.Minute)
+
+	for !t.Before(limit) {
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()).Add(-time.Minute)
+
+			continue
+		}
+
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location()).Add(-time.Minute)
+
+			continue
+		}
+
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(-time.Minute)
+
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// Previous returns the last time at or before the one given that the schedule
+// fired, or the zero time if it never has.
+func (s *Schedule) Previous(t time.Time) time.Time {
+	return s.previous(t, t.Add(-searchLimit))
+}
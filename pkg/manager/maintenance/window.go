@@ -0,0 +1,249 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+var (
+	// ErrWindow is raised when a maintenance window cannot be parsed.
+	ErrWindow = errors.New("invalid maintenance window")
+
+	// ErrFreeze is raised when a change freeze cannot be parsed.
+	ErrFreeze = errors.New("invalid change freeze")
+)
+
+const (
+	// maxWindowDuration bounds how long a window can be open for, beyond a
+	// week it'd overlap with itself.
+	maxWindowDuration = 7 * 24 * time.Hour
+)
+
+// Window is a period of time, starting on a schedule, where disruptive changes
+// like application upgrades are allowed.
+type Window struct {
+	// Schedule defines when the window opens.
+	Schedule *Schedule
+
+	// Duration is how long the window is open for.
+	Duration time.Duration
+}
+
+// ParseWindow parses a window in the form "<schedule>;<duration>", for example
+// "0 2 * * 6;4h" opens at 02:00 every Saturday for 4 hours.
+func ParseWindow(in string) (*Window, error) {
+	spec, durationValue, ok := strings.Cut(in, ";")
+	if !ok {
+		return nil, fmt.Errorf("%w: %s: expected <schedule>;<duration>", ErrWindow, in)
+	}
+
+	schedule, err := ParseSchedule(strings.TrimSpace(spec))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrWindow, err)
+	}
+
+	duration, err := time.ParseDuration(strings.TrimSpace(durationValue))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrWindow, err)
+	}
+
+	if duration < time.Minute || duration > maxWindowDuration {
+		return nil, fmt.Errorf("%w: %s: duration must be between 1m and %v", ErrWindow, in, maxWindowDuration)
+	}
+
+	w := &Window{
+		Schedule: schedule,
+		Duration: duration,
+	}
+
+	return w, nil
+}
+
+// String returns the window in its parsable form.
+func (w *Window) String() string {
+	return fmt.Sprintf("%s;%v", w.Schedule, w.Duration)
+}
+
+// Open returns whether the window is open, and when that next changes.  If open
+// this is when the window closes, otherwise when it next opens.
+func (w *Window) Open(t time.Time) (bool, time.Time) {
+	// Look for the most recent start that would still be open.
+	if start := w.Schedule.previous(t, t.Add(-w.Duration)); !start.IsZero() && t.Sub(start) < w.Duration {
+		return true, start.Add(w.Duration)
+	}
+
+	return false, w.Schedule.Next(t)
+}
+
+// Windows is a set of maintenance windows, which may be used as a flag.
+type Windows []*Window
+
+var _ pflag.Value = &Windows{}
+
+// Open returns whether any window is open, and when that next changes.  When there
+// are no windows, disruptive changes are always allowed.
+func (w Windows) Open(t time.Time) (bool, time.Time) {
+	if len(w) == 0 {
+		return true, time.Time{}
+	}
+
+	var open bool
+
+	var boundary time.Time
+
+	for _, window := range w {
+		windowOpen, windowBoundary := window.Open(t)
+
+		switch {
+		case windowOpen && (!open || windowBoundary.After(boundary)):
+			open = true
+			boundary = windowBoundary
+		case !open && !windowBoundary.IsZero() && (boundary.IsZero() || windowBoundary.Before(boundary)):
+			boundary = windowBoundary
+		}
+	}
+
+	return open, boundary
+}
+
+// String implements the pflag.Value interface.
+func (w *Windows) String() string {
+	out := make([]string, len(*w))
+
+	for i, window := range *w {
+		out[i] = window.String()
+	}
+
+	return strings.Join(out, ",")
+}
+
+// Set implements the pflag.Value interface, it may be specified multiple times.
+func (w *Windows) Set(in string) error {
+	window, err := ParseWindow(in)
+	if err != nil {
+		return err
+	}
+
+	*w = append(*w, window)
+
+	return nil
+}
+
+// Type implements the pflag.Value interface.
+func (w *Windows) Type() string {
+	return "window"
+}
+
+// Freeze is a period of time where no changes are allowed at all.
+type Freeze struct {
+	// Start is when the freeze begins.
+	Start time.Time
+
+	// End is when the freeze ends.
+	End time.Time
+}
+
+// ParseFreeze parses a freeze in the form "<start>/<end>", where both are RFC3339
+// times, for example "2025-12-20T00:00:00Z/2026-01-05T00:00:00Z".
+func ParseFreeze(in string) (*Freeze, error) {
+	startValue, endValue, ok := strings.Cut(in, "/")
+	if !ok {
+		return nil, fmt.Errorf("%w: %s: expected <start>/<end>", ErrFreeze, in)
+	}
+
+	start, err := time.Parse(time.RFC3339, startValue)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFreeze, err)
+	}
+
+	end, err := time.Parse(time.RFC3339, endValue)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFreeze, err)
+	}
+
+	if !end.After(start) {
+		return nil, fmt.Errorf("%w: %s: end must be after start", ErrFreeze, in)
+	}
+
+	f := &Freeze{
+		Start: start,
+		End:   end,
+	}
+
+	return f, nil
+}
+
+// String returns the freeze in its parsable form.
+func (f *Freeze) String() string {
+	return f.Start.Format(time.RFC3339) + "/" + f.End.Format(time.RFC3339)
+}
+
+// Freezes is a set of change freezes, which may be used as a flag.
+type Freezes []*Freeze
+
+var _ pflag.Value = &Freezes{}
+
+// Active returns the active freeze that ends last, or nil if none are active.
+func (f Freezes) Active(t time.Time) *Freeze {
+	var active *Freeze
+
+	for _, freeze := range f {
+		if t.Before(freeze.Start) || !t.Before(freeze.End) {
+			continue
+		}
+
+		if active == nil || freeze.End.After(active.End) {
+			active = freeze
+		}
+	}
+
+	return active
+}
+
+// String implements the pflag.Value interface.
+func (f *Freezes) String() string {
+	out := make([]string, len(*f))
+
+	for i, freeze := range *f {
+		out[i] = freeze.String()
+	}
+
+	return strings.Join(out, ",")
+}
+
+// Set implements the pflag.Value interface, it may be specified multiple times.
+func (f *Freezes) Set(in string) error {
+	freeze, err := ParseFreeze(in)
+	if err != nil {
+		return err
+	}
+
+	*f = append(*f, freeze)
+
+	return nil
+}
+
+// Type implements the pflag.Value interface.
+func (f *Freezes) Type() string {
+	return "freeze"
+}
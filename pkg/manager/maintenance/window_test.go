@@ -0,0 +1,191 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unikorn-cloud/core/pkg/manager/maintenance"
+)
+
+func mustParseTime(t *testing.T, in string) time.Time {
+	t.Helper()
+
+	out, err := time.Parse(time.RFC3339, in)
+	require.NoError(t, err)
+
+	return out
+}
+
+// TestScheduleNext tests schedules fire when expected.
+func TestScheduleNext(t *testing.T) {
+	t.Parallel()
+
+	// 2025-01-01 was a Wednesday.
+	now := mustParseTime(t, "2025-01-01T12:30:00Z")
+
+	tests := []struct {
+		spec     string
+		expected string
+	}{
+		{"*/15 * * * *", "2025-01-01T12:45:00Z"},
+		{"0 2 * * 6", "2025-01-04T02:00:00Z"},
+		{"0 2 * * 0", "2025-01-05T02:00:00Z"},
+		{"0 2 * * 7", "2025-01-05T02:00:00Z"},
+		{"0 9-17/4 * * 1-5", "2025-01-01T13:00:00Z"},
+		{"30 12 1 2,3 *", "2025-02-01T12:30:00Z"},
+		{"0 0 15 * 1", "2025-01-06T00:00:00Z"},
+	}
+
+	for _, test := range tests {
+		schedule, err := maintenance.ParseSchedule(test.spec)
+		require.NoError(t, err, test.spec)
+		assert.Equal(t, mustParseTime(t, test.expected), schedule.Next(now), test.spec)
+	}
+
+	never, err := maintenance.ParseSchedule("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, never.Next(now).IsZero())
+}
+
+// TestSchedulePrevious tests when a schedule last fired.
+func TestSchedulePrevious(t *testing.T) {
+	t.Parallel()
+
+	// 2025-01-01 was a Wednesday.
+	now := mustParseTime(t, "2025-01-01T12:30:00Z")
+
+	tests := []struct {
+		spec     string
+		expected string
+	}{
+		{"*/15 * * * *", "2025-01-01T12:30:00Z"},
+		{"0 2 * * 6", "2024-12-28T02:00:00Z"},
+		{"0 2 * * 0", "2024-12-29T02:00:00Z"},
+		{"0 9-17/4 * * 1-5", "2025-01-01T09:00:00Z"},
+		{"30 12 1 2,3 *", "2024-03-01T12:30:00Z"},
+		{"0 0 15 * 1", "2024-12-30T00:00:00Z"},
+	}
+
+	for _, test := range tests {
+		schedule, err := maintenance.ParseSchedule(test.spec)
+		require.NoError(t, err, test.spec)
+		assert.Equal(t, mustParseTime(t, test.expected), schedule.Previous(now), test.spec)
+	}
+
+	never, err := maintenance.ParseSchedule("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, never.Previous(now).IsZero())
+}
+
+// TestScheduleInvalid tests invalid schedules are rejected.
+func TestScheduleInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, spec := range []string{"* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := maintenance.ParseSchedule(spec)
+		require.ErrorIs(t, err, maintenance.ErrSchedule, spec)
+	}
+}
+
+// TestWindowOpen tests whether a window is open and its boundaries.
+func TestWindowOpen(t *testing.T) {
+	t.Parallel()
+
+	window, err := maintenance.ParseWindow("0 2 * * 6;4h")
+	require.NoError(t, err)
+
+	open, boundary := window.Open(mustParseTime(t, "2025-01-04T05:59:00Z"))
+	assert.True(t, open)
+	assert.Equal(t, mustParseTime(t, "2025-01-04T06:00:00Z"), boundary)
+
+	open, boundary = window.Open(mustParseTime(t, "2025-01-04T06:00:00Z"))
+	assert.False(t, open)
+	assert.Equal(t, mustParseTime(t, "2025-01-11T02:00:00Z"), boundary)
+
+	// Long windows that span days are open from the most recent start.
+	window, err = maintenance.ParseWindow("0 2 * * 6;168h")
+	require.NoError(t, err)
+
+	open, boundary = window.Open(mustParseTime(t, "2025-01-11T01:59:00Z"))
+	assert.True(t, open)
+	assert.Equal(t, mustParseTime(t, "2025-01-11T02:00:00Z"), boundary)
+
+	open, boundary = window.Open(mustParseTime(t, "2025-01-11T02:00:30Z"))
+	assert.True(t, open)
+	assert.Equal(t, mustParseTime(t, "2025-01-18T02:00:00Z"), boundary)
+
+	_, err = maintenance.ParseWindow("0 2 * * 6")
+	require.ErrorIs(t, err, maintenance.ErrWindow)
+
+	_, err = maintenance.ParseWindow("0 2 * * 6;30s")
+	require.ErrorIs(t, err, maintenance.ErrWindow)
+}
+
+// TestWindows tests multiple windows combine, and no windows allows disruption.
+func TestWindows(t *testing.T) {
+	t.Parallel()
+
+	var windows maintenance.Windows
+
+	open, boundary := windows.Open(time.Now())
+	assert.True(t, open)
+	assert.True(t, boundary.IsZero())
+
+	require.NoError(t, windows.Set("0 2 * * 6;4h"))
+	require.NoError(t, windows.Set("0 22 * * 3;1h"))
+	assert.Equal(t, "0 2 * * 6;4h0m0s,0 22 * * 3;1h0m0s", windows.String())
+
+	open, boundary = windows.Open(mustParseTime(t, "2025-01-01T12:00:00Z"))
+	assert.False(t, open)
+	assert.Equal(t, mustParseTime(t, "2025-01-01T22:00:00Z"), boundary)
+
+	open, boundary = windows.Open(mustParseTime(t, "2025-01-01T22:30:00Z"))
+	assert.True(t, open)
+	assert.Equal(t, mustParseTime(t, "2025-01-01T23:00:00Z"), boundary)
+}
+
+// TestFreezes tests the active freeze is the one that ends last.
+func TestFreezes(t *testing.T) {
+	t.Parallel()
+
+	var freezes maintenance.Freezes
+
+	require.NoError(t, freezes.Set("2025-12-20T00:00:00Z/2026-01-05T00:00:00Z"))
+	require.NoError(t, freezes.Set("2025-12-24T00:00:00Z/2025-12-27T00:00:00Z"))
+	require.ErrorIs(t, freezes.Set("2025-12-24T00:00:00Z/2025-12-20T00:00:00Z"), maintenance.ErrFreeze)
+
+	assert.Nil(t, freezes.Active(mustParseTime(t, "2025-12-19T23:59:59Z")))
+	assert.Nil(t, freezes.Active(mustParseTime(t, "2026-01-05T00:00:00Z")))
+
+	active := freezes.Active(mustParseTime(t, "2025-12-25T00:00:00Z"))
+	require.NotNil(t, active)
+	assert.Equal(t, mustParseTime(t, "2026-01-05T00:00:00Z"), active.End)
+}
+
+// TestContext tests disruption is allowed unless set otherwise.
+func TestContext(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, maintenance.DisruptionAllowed(context.Background()))
+	assert.False(t, maintenance.DisruptionAllowed(maintenance.NewContext(context.Background(), false)))
+}
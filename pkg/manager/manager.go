@@ -19,12 +19,15 @@ package manager
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"os"
 
 	"github.com/spf13/pflag"
 
 	coreclient "github.com/unikorn-cloud/core/pkg/client"
+	"github.com/unikorn-cloud/core/pkg/manager/migration"
 	"github.com/unikorn-cloud/core/pkg/manager/options"
 	"github.com/unikorn-cloud/core/pkg/manager/otel"
 
@@ -37,7 +40,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
 // ControllerOptions abstracts controller specific flags.
@@ -65,7 +70,8 @@ type ControllerFactory interface {
 
 	// Upgrade allows version based upgrades of managed resources.
 	// DO NOT MODIFY THE SPEC EVER.  Only things like metadata can
-	// be touched.
+	// be touched.  Prefer implementing Migrator for per-resource
+	// migrations.
 	Upgrade(client client.Client) error
 
 	// Schemes allows controllers to add types to the client beyond
@@ -73,8 +79,121 @@ type ControllerFactory interface {
 	Schemes() []coreclient.SchemeAdder
 }
 
+// Migrator may be optionally implemented by a ControllerFactory to declare
+// versioned resource migrations.  These are run before Upgrade, and unlike it
+// are only applied once per resource and support dry runs.
+type Migrator interface {
+	// Migrations registers any migrations with the registry.
+	Migrations(registry *migration.Registry)
+}
+
+var (
+	// ErrDuplicateController is raised when multiple controllers are registered
+	// with the same name.
+	ErrDuplicateController = errors.New("duplicate controller name")
+
+	// ErrDuplicateFlag is raised when multiple controllers declare the same flag.
+	ErrDuplicateFlag = errors.New("duplicate flag")
+)
+
+// controllerInstance is the runtime state of a single controller.
+type controllerInstance struct {
+	// factory creates the controller.
+	factory ControllerFactory
+
+	// name is the unique controller name.
+	name string
+
+	// options are the controller specific options, may be nil.
+	options ControllerOptions
+
+	// maxConcurrentReconciles, if non-zero, overrides the global setting.
+	maxConcurrentReconciles int
+}
+
+// newControllerInstances creates controller state for each factory, ensuring
+// names are unique.
+func newControllerInstances(factories []ControllerFactory) ([]*controllerInstance, error) {
+	controllers := make([]*controllerInstance, len(factories))
+
+	names := map[string]bool{}
+
+	for i, f := range factories {
+		name, _, _ := f.Metadata()
+
+		if names[name] {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateController, name)
+		}
+
+		names[name] = true
+
+		controllers[i] = &controllerInstance{
+			factory: f,
+			name:    name,
+			options: f.Options(),
+		}
+	}
+
+	return controllers, nil
+}
+
+// addFlags adds any controller specific flags.  When running multiple controllers
+// concurrency may be tuned per controller.
+func (c *controllerInstance) addFlags(f *pflag.FlagSet, multiple bool) {
+	if c.options != nil {
+		c.options.AddFlags(f)
+	}
+
+	if multiple {
+		f.IntVar(&c.maxConcurrentReconciles, c.name+"-max-concurrency", 0, "Maximum number of requests to process at the same time for the "+c.name+" controller, overrides --max-concurrency if set")
+	}
+}
+
+// addControllerFlags adds flags for all controllers.  Each controller's flags are
+// gathered separately so that any declared by multiple controllers, or that clash
+// with common flags, can be reported, rather than causing a panic.
+func addControllerFlags(f *pflag.FlagSet, controllers []*controllerInstance) error {
+	owners := map[string]string{}
+
+	for _, c := range controllers {
+		controllerFlags := pflag.NewFlagSet(c.name, pflag.ContinueOnError)
+
+		c.addFlags(controllerFlags, len(controllers) > 1)
+
+		var err error
+
+		controllerFlags.VisitAll(func(flag *pflag.Flag) {
+			if err != nil {
+				return
+			}
+
+			if owner, ok := owners[flag.Name]; ok {
+				err = fmt.Errorf("%w: --%s declared by controllers %s and %s", ErrDuplicateFlag, flag.Name, owner, c.name)
+
+				return
+			}
+
+			if f.Lookup(flag.Name) != nil || (flag.Shorthand != "" && f.ShorthandLookup(flag.Shorthand) != nil) {
+				err = fmt.Errorf("%w: --%s declared by controller %s is already defined", ErrDuplicateFlag, flag.Name, c.name)
+
+				return
+			}
+
+			owners[flag.Name] = c.name
+
+			f.AddFlag(flag)
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // getManager returns a generic manager.
-func getManager(f ControllerFactory) (manager.Manager, error) {
+func getManager(o *options.Options, application string, controllers []*controllerInstance) (manager.Manager, error) {
 	// Create a manager with leadership election to prevent split brain
 	// problems, and set the scheme so it gets propagated to the client.
 	config, err := clientconfig.GetConfig()
@@ -82,17 +201,29 @@ func getManager(f ControllerFactory) (manager.Manager, error) {
 		return nil, err
 	}
 
-	scheme, err := coreclient.NewScheme(f.Schemes()...)
+	var schemes []coreclient.SchemeAdder
+
+	for _, c := range controllers {
+		schemes = append(schemes, c.factory.Schemes()...)
+	}
+
+	scheme, err := coreclient.NewScheme(schemes...)
 	if err != nil {
 		return nil, err
 	}
 
-	application, _, _ := f.Metadata()
-
 	options := manager.Options{
-		Scheme:           scheme,
-		LeaderElection:   true,
-		LeaderElectionID: application,
+		Scheme:                 scheme,
+		LeaderElection:         true,
+		LeaderElectionID:       application,
+		HealthProbeBindAddress: o.HealthProbeBindAddress,
+		Metrics: metricsserver.Options{
+			BindAddress: o.MetricsBindAddress,
+		},
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port:    o.WebhookPort,
+			CertDir: o.WebhookCertDir,
+		}),
 	}
 
 	manager, err := manager.New(config, options)
@@ -100,46 +231,90 @@ func getManager(f ControllerFactory) (manager.Manager, error) {
 		return nil, err
 	}
 
+	if err := addHealthChecks(o, manager); err != nil {
+		return nil, err
+	}
+
+	for _, c := range controllers {
+		if registerer, ok := c.factory.(HealthCheckRegisterer); ok {
+			if err := registerer.AddHealthChecks(manager); err != nil {
+				return nil, fmt.Errorf("%s: %w", c.name, err)
+			}
+		}
+	}
+
+	if err := registerWebhooks(manager, controllers); err != nil {
+		return nil, err
+	}
+
 	return manager, nil
 }
 
 // getController returns a generic controller.
-func getController(o *options.Options, controllerOptions ControllerOptions, manager manager.Manager, f ControllerFactory) (controller.Controller, error) {
+func getController(o *options.Options, manager manager.Manager, c *controllerInstance) (controller.Controller, error) {
 	// This prevents a single bad reconcile from affecting all the rest by
 	// boning the whole container.
 	recoverPanic := true
 
+	maxConcurrentReconciles := o.MaxConcurrentReconciles
+
+	if c.maxConcurrentReconciles > 0 {
+		maxConcurrentReconciles = c.maxConcurrentReconciles
+	}
+
 	options := controller.Options{
-		MaxConcurrentReconciles: o.MaxConcurrentReconciles,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
 		RecoverPanic:            &recoverPanic,
-		Reconciler:              f.Reconciler(o, controllerOptions, manager),
+		Reconciler:              c.factory.Reconciler(o, c.options, manager),
 	}
 
-	application, _, _ := f.Metadata()
-
-	c, err := controller.New(application, manager, options)
+	controller, err := controller.New(c.name, manager, options)
 	if err != nil {
 		return nil, err
 	}
 
-	return c, nil
+	return controller, nil
 }
 
-func doUpgrade(f ControllerFactory) error {
+// doUpgrade runs migrations and upgrades for each controller in the order they
+// were registered, allowing later controllers to depend on upgrades performed by
+// earlier ones.  In dry run mode only migrations are run as free-form upgrades
+// may have side effects.
+func doUpgrade(ctx context.Context, o *options.Options, controllers []*controllerInstance) error {
 	client, err := coreclient.New(context.TODO())
 	if err != nil {
 		return err
 	}
 
-	if err := f.Upgrade(client); err != nil {
-		return err
+	for _, c := range controllers {
+		if migrator, ok := c.factory.(Migrator); ok {
+			registry := migration.NewRegistry().WithDryRun(o.MigrationDryRun)
+
+			migrator.Migrations(registry)
+
+			if err := registry.Run(log.IntoContext(ctx, log.FromContext(ctx).WithValues("controller", c.name)), client); err != nil {
+				return fmt.Errorf("%s: %w", c.name, err)
+			}
+		}
+
+		if o.MigrationDryRun {
+			continue
+		}
+
+		if err := c.factory.Upgrade(client); err != nil {
+			return fmt.Errorf("%s: %w", c.name, err)
+		}
 	}
 
 	return nil
 }
 
-// Run provides common manager initialization and execution.
-func Run(f ControllerFactory) {
+// Run provides common manager initialization and execution.  Multiple controllers
+// may be run by a single manager, sharing its cache and leader election.  The
+// first factory's metadata identifies the process, for example for leader election,
+// so should remain the same when adding further controllers.  Upgrades are run
+// in the order the factories are specified.
+func Run(f ControllerFactory, others ...ControllerFactory) {
 	zapOptions := &zap.Options{}
 	zapOptions.BindFlags(flag.CommandLine)
 
@@ -151,9 +326,15 @@ func Run(f ControllerFactory) {
 	otelOptions := &otel.Options{}
 	otelOptions.AddFlags(pflag.CommandLine)
 
-	controllerOptions := f.Options()
-	if controllerOptions != nil {
-		controllerOptions.AddFlags(pflag.CommandLine)
+	controllers, err := newControllerInstances(append([]ControllerFactory{f}, others...))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if err := addControllerFlags(pflag.CommandLine, controllers); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
 	pflag.Parse()
@@ -175,26 +356,33 @@ func Run(f ControllerFactory) {
 		os.Exit(1)
 	}
 
-	if err := doUpgrade(f); err != nil {
+	if err := doUpgrade(log.IntoContext(ctx, log.Log.WithName("migration")), o, controllers); err != nil {
 		logger.Error(err, "resource upgrade failed")
 		os.Exit(1)
 	}
 
-	manager, err := getManager(f)
-	if err != nil {
-		logger.Error(err, "manager creation error")
-		os.Exit(1)
+	if o.MigrationDryRun {
+		logger.Info("migration dry run complete")
+		os.Exit(0)
 	}
 
-	controller, err := getController(o, controllerOptions, manager, f)
+	manager, err := getManager(o, application, controllers)
 	if err != nil {
-		logger.Error(err, "controller creation error")
+		logger.Error(err, "manager creation error")
 		os.Exit(1)
 	}
 
-	if err := f.RegisterWatches(manager, controller); err != nil {
-		logger.Error(err, "watcher registration error")
-		os.Exit(1)
+	for _, c := range controllers {
+		controller, err := getController(o, manager, c)
+		if err != nil {
+			logger.Error(err, "controller creation error", "controller", c.name)
+			os.Exit(1)
+		}
+
+		if err := c.factory.RegisterWatches(manager, controller); err != nil {
+			logger.Error(err, "watcher registration error", "controller", c.name)
+			os.Exit(1)
+		}
 	}
 
 	if err := manager.Start(ctx); err != nil {
@@ -0,0 +1,65 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	unikornv1 "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+//nolint:gochecknoglobals
+var (
+	reconcileOutcomes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "unikorn_reconcile_outcomes_total",
+		Help: "Number of reconciles by resource kind and outcome reason.",
+	}, []string{"kind", "reason"})
+
+	reconcileYields = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "unikorn_reconcile_yields_total",
+		Help: "Number of reconciles that yielded by resource kind.",
+	}, []string{"kind"})
+
+	provisioningDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "unikorn_provisioning_duration_seconds",
+		Help:    "Time spent in the Provisioning state before becoming Provisioned by resource kind.",
+		Buckets: prometheus.ExponentialBuckets(10, 2, 12),
+	}, []string{"kind"})
+)
+
+//nolint:gochecknoinits
+func init() {
+	metrics.Registry.MustRegister(reconcileOutcomes, reconcileYields, provisioningDuration)
+}
+
+// recordReconcileMetrics records the outcome of a reconcile.  The previous condition,
+// if any, is used to measure how long it took to provision a resource.
+func recordReconcileMetrics(kind string, previous *unikornv1.Condition, reason unikornv1.ConditionReason) {
+	reconcileOutcomes.WithLabelValues(kind, string(reason)).Inc()
+
+	if reason == unikornv1.ConditionReasonProvisioning || reason == unikornv1.ConditionReasonDeprovisioning {
+		reconcileYields.WithLabelValues(kind).Inc()
+	}
+
+	if previous != nil && previous.Reason == unikornv1.ConditionReasonProvisioning && reason == unikornv1.ConditionReasonProvisioned {
+		provisioningDuration.WithLabelValues(kind).Observe(time.Since(previous.LastTransitionTime.Time).Seconds())
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	unikornv1 "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1"
+	unikornv1fake "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1/fake"
+	"github.com/unikorn-cloud/core/pkg/manager"
+	"github.com/unikorn-cloud/core/pkg/provisioners"
+	mockprovisioners "github.com/unikorn-cloud/core/pkg/provisioners/mock"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// provisioningObservations returns the number of provisioning duration samples.
+func provisioningObservations(t *testing.T) uint64 {
+	t.Helper()
+
+	families, err := metrics.Registry.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != "unikorn_provisioning_duration_seconds" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			return metric.GetHistogram().GetSampleCount()
+		}
+	}
+
+	return 0
+}
+
+// TestReconcileProvisioningDuration tests the time taken to provision is recorded
+// when a resource transitions from provisioning to provisioned.
+func TestReconcileProvisioningDuration(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	request := &unikornv1fake.ManagedResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testName,
+		},
+		Status: unikornv1fake.ManagedResourceStatus{
+			Conditions: []unikornv1.Condition{
+				{
+					Type:               unikornv1.ConditionAvailable,
+					Status:             corev1.ConditionFalse,
+					Reason:             unikornv1.ConditionReasonProvisioning,
+					LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Minute)),
+				},
+			},
+		},
+	}
+
+	tc := mustNewTestContext(t, request)
+
+	p := mockprovisioners.NewMockManagerProvisioner(c)
+	p.EXPECT().Object().Return(&unikornv1fake.ManagedResource{})
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Provision(gomock.Any()).Return(nil)
+
+	reconciler := manager.NewReconciler(managerOptions(), nil, tc.newManager(c), func(_ manager.ControllerOptions) provisioners.ManagerProvisioner { return p })
+
+	before := provisioningObservations(t)
+
+	_, err := reconciler.Reconcile(context.Background(), newRequest(testNamespace, testName))
+	require.NoError(t, err)
+
+	assert.Equal(t, before+1, provisioningObservations(t))
+}
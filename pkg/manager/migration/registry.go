@@ -0,0 +1,305 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/unikorn-cloud/core/pkg/constants"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var (
+	// ErrVersion is raised when a migration or resource version is invalid.
+	ErrVersion = errors.New("invalid migration version")
+
+	// ErrMigration is raised when a migration fails to apply.
+	ErrMigration = errors.New("migration failed")
+)
+
+// Func migrates a single object in place.  It must only modify the object
+// as the framework is responsible for persisting it, which allows dry runs.
+// Objects without a recorded version are assumed to predate all migrations,
+// so functions must tolerate objects that are already in the desired form.
+// DO NOT MODIFY THE SPEC EVER.  Only things like metadata can be touched.
+type Func func(ctx context.Context, object client.Object) error
+
+// Migration is a single versioned migration.
+type Migration struct {
+	// Version is the semantic version an object is at once migrated.
+	Version string
+
+	// Description describes what the migration does for logging.
+	Description string
+
+	// Func performs the migration.
+	Func Func
+}
+
+// Progress is reported after each object has been processed.
+type Progress struct {
+	// Kind is the kind of resource being migrated.
+	Kind string
+
+	// Total is the number of resources of this kind.
+	Total int
+
+	// Processed is the number of resources processed so far.
+	Processed int
+
+	// Migrated is the number of resources processed so far that had
+	// migrations applied.
+	Migrated int
+}
+
+// ProgressFunc is called as migrations are run.
+type ProgressFunc func(Progress)
+
+// resourceMigrations are the migrations for a single resource type.
+type resourceMigrations struct {
+	// list is used to list all resources of the type.
+	list client.ObjectList
+
+	// migrations are the migrations to apply.
+	migrations []Migration
+}
+
+// Registry holds all migrations for a controller and applies them.
+type Registry struct {
+	// resources are registered in the order they were added.
+	resources []*resourceMigrations
+
+	// dryRun performs server side dry run updates only.
+	dryRun bool
+
+	// progress, if set, is called after each object is processed.
+	progress ProgressFunc
+}
+
+// NewRegistry creates an empty migration registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds migrations for all resources returned by listing the provided
+// type, for example &unikornv1.HelmApplicationList{}.  Migrations may be
+// specified in any order, they are applied in version order.
+func (r *Registry) Register(list client.ObjectList, migrations ...Migration) *Registry {
+	r.resources = append(r.resources, &resourceMigrations{
+		list:       list,
+		migrations: migrations,
+	})
+
+	return r
+}
+
+// WithDryRun reports what would be migrated without persisting anything.
+func (r *Registry) WithDryRun(dryRun bool) *Registry {
+	r.dryRun = dryRun
+
+	return r
+}
+
+// WithProgress reports progress to the provided callback.
+func (r *Registry) WithProgress(progress ProgressFunc) *Registry {
+	r.progress = progress
+
+	return r
+}
+
+// versionedMigration is a migration with its version parsed.
+type versionedMigration struct {
+	Migration
+
+	version *semver.Version
+}
+
+// sortMigrations parses and sorts migrations into the order they should be applied.
+func sortMigrations(migrations []Migration) ([]*versionedMigration, error) {
+	out := make([]*versionedMigration, len(migrations))
+
+	for i := range migrations {
+		version, err := semver.StrictNewVersion(migrations[i].Version)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrVersion, migrations[i].Version, err)
+		}
+
+		out[i] = &versionedMigration{
+			Migration: migrations[i],
+			version:   version,
+		}
+	}
+
+	slices.SortFunc(out, func(a, b *versionedMigration) int {
+		return a.version.Compare(b.version)
+	})
+
+	for i := 1; i < len(out); i++ {
+		if out[i].version.Equal(out[i-1].version) {
+			return nil, fmt.Errorf("%w: duplicate version %s", ErrVersion, out[i].Migration.Version)
+		}
+	}
+
+	return out, nil
+}
+
+// objectVersion returns the version of the last migration applied to the object.
+func objectVersion(object client.Object) (*semver.Version, error) {
+	value, ok := object.GetAnnotations()[constants.MigrationVersionAnnotation]
+	if !ok {
+		return semver.New(0, 0, 0, "", ""), nil
+	}
+
+	version, err := semver.StrictNewVersion(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s/%s has version %s: %w", ErrVersion, object.GetNamespace(), object.GetName(), value, err)
+	}
+
+	return version, nil
+}
+
+// Run applies all pending migrations.  Any error should prevent the controller
+// from starting, as it may rely on the migrated form.
+func (r *Registry) Run(ctx context.Context, c client.Client) error {
+	for _, resource := range r.resources {
+		if err := r.run(ctx, c, resource); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// run applies all pending migrations for a single resource type.
+func (r *Registry) run(ctx context.Context, c client.Client, resource *resourceMigrations) error {
+	migrations, err := sortMigrations(resource.migrations)
+	if err != nil {
+		return err
+	}
+
+	kind := fmt.Sprintf("%T", resource.list)
+
+	if gvk, err := apiutil.GVKForObject(resource.list, c.Scheme()); err == nil {
+		kind = strings.TrimSuffix(gvk.Kind, "List")
+	}
+
+	log := log.FromContext(ctx).WithValues("kind", kind, "dryRun", r.dryRun)
+
+	//nolint:forcetypeassert
+	list := resource.list.DeepCopyObject().(client.ObjectList)
+
+	if err := c.List(ctx, list); err != nil {
+		return err
+	}
+
+	objects, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+
+	progress := Progress{
+		Kind:  kind,
+		Total: len(objects),
+	}
+
+	log.Info("migrating resources", "total", progress.Total)
+
+	for _, o := range objects {
+		object, ok := o.(client.Object)
+		if !ok {
+			return fmt.Errorf("%w: %T is not an object", ErrMigration, o)
+		}
+
+		migrated, err := r.migrate(ctx, c, object, migrations)
+		if err != nil {
+			return err
+		}
+
+		progress.Processed++
+
+		if migrated {
+			progress.Migrated++
+		}
+
+		if r.progress != nil {
+			r.progress(progress)
+		}
+	}
+
+	log.Info("resources migrated", "total", progress.Total, "migrated", progress.Migrated)
+
+	return nil
+}
+
+// migrate applies any pending migrations to an object, persisting the object and
+// its new version after each one so a failure can be resumed from.
+func (r *Registry) migrate(ctx context.Context, c client.Client, object client.Object, migrations []*versionedMigration) (bool, error) {
+	log := log.FromContext(ctx).WithValues("namespace", object.GetNamespace(), "name", object.GetName())
+
+	current, err := objectVersion(object)
+	if err != nil {
+		return false, err
+	}
+
+	var options []client.UpdateOption
+
+	if r.dryRun {
+		options = append(options, client.DryRunAll)
+	}
+
+	migrated := false
+
+	for _, migration := range migrations {
+		if !migration.version.GreaterThan(current) {
+			continue
+		}
+
+		log.Info("applying migration", "version", migration.Migration.Version, "description", migration.Description, "dryRun", r.dryRun)
+
+		if err := migration.Func(ctx, object); err != nil {
+			return false, fmt.Errorf("%w: %s/%s to %s: %w", ErrMigration, object.GetNamespace(), object.GetName(), migration.Migration.Version, err)
+		}
+
+		annotations := object.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+
+		annotations[constants.MigrationVersionAnnotation] = migration.version.String()
+
+		object.SetAnnotations(annotations)
+
+		if err := c.Update(ctx, object, options...); err != nil {
+			return false, fmt.Errorf("%w: %s/%s to %s: %w", ErrMigration, object.GetNamespace(), object.GetName(), migration.Migration.Version, err)
+		}
+
+		migrated = true
+	}
+
+	return migrated, nil
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migration_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	coreclient "github.com/unikorn-cloud/core/pkg/client"
+	"github.com/unikorn-cloud/core/pkg/constants"
+	"github.com/unikorn-cloud/core/pkg/manager/migration"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	namespace = "default"
+)
+
+var (
+	errUnexpected = errors.New("unexpected")
+)
+
+func mustNewClient(t *testing.T) client.Client {
+	t.Helper()
+
+	scheme, err := coreclient.NewScheme()
+	require.NoError(t, err)
+
+	legacy := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      "legacy",
+		},
+	}
+
+	partial := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      "partial",
+			Annotations: map[string]string{
+				constants.MigrationVersionAnnotation: "1.0.0",
+			},
+		},
+	}
+
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(legacy, partial).Build()
+}
+
+// addLabel returns a migration function that adds the named label.
+func addLabel(name string) migration.Func {
+	return func(_ context.Context, object client.Object) error {
+		labels := object.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+
+		labels[name] = "true"
+
+		object.SetLabels(labels)
+
+		return nil
+	}
+}
+
+func newRegistry() *migration.Registry {
+	return migration.NewRegistry().Register(&corev1.ConfigMapList{},
+		migration.Migration{
+			Version:     "2.0.0",
+			Description: "add v2 label",
+			Func:        addLabel("v2"),
+		},
+		migration.Migration{
+			Version:     "1.0.0",
+			Description: "add v1 label",
+			Func:        addLabel("v1"),
+		},
+	)
+}
+
+func mustGetConfigMap(t *testing.T, c client.Client, name string) *corev1.ConfigMap {
+	t.Helper()
+
+	var configMap corev1.ConfigMap
+
+	require.NoError(t, c.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: name}, &configMap))
+
+	return &configMap
+}
+
+// TestMigrate tests only pending migrations are applied, in version order, and that
+// they are only applied once.
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	c := mustNewClient(t)
+
+	var progress migration.Progress
+
+	registry := newRegistry().WithProgress(func(p migration.Progress) { progress = p })
+
+	require.NoError(t, registry.Run(context.Background(), c))
+	assert.Equal(t, migration.Progress{Kind: "ConfigMap", Total: 2, Processed: 2, Migrated: 2}, progress)
+
+	legacy := mustGetConfigMap(t, c, "legacy")
+	assert.Equal(t, "2.0.0", legacy.Annotations[constants.MigrationVersionAnnotation])
+	assert.Contains(t, legacy.Labels, "v1")
+	assert.Contains(t, legacy.Labels, "v2")
+
+	partial := mustGetConfigMap(t, c, "partial")
+	assert.Equal(t, "2.0.0", partial.Annotations[constants.MigrationVersionAnnotation])
+	assert.NotContains(t, partial.Labels, "v1")
+	assert.Contains(t, partial.Labels, "v2")
+
+	require.NoError(t, registry.Run(context.Background(), c))
+	assert.Equal(t, 0, progress.Migrated)
+}
+
+// TestMigrateDryRun tests nothing is persisted in dry run mode.
+func TestMigrateDryRun(t *testing.T) {
+	t.Parallel()
+
+	c := mustNewClient(t)
+
+	require.NoError(t, newRegistry().WithDryRun(true).Run(context.Background(), c))
+
+	legacy := mustGetConfigMap(t, c, "legacy")
+	assert.NotContains(t, legacy.Annotations, constants.MigrationVersionAnnotation)
+	assert.Empty(t, legacy.Labels)
+}
+
+// TestMigrateError tests a failed migration is reported, and earlier migrations
+// are persisted so it can be resumed.
+func TestMigrateError(t *testing.T) {
+	t.Parallel()
+
+	c := mustNewClient(t)
+
+	fail := func(_ context.Context, _ client.Object) error {
+		return errUnexpected
+	}
+
+	registry := migration.NewRegistry().Register(&corev1.ConfigMapList{},
+		migration.Migration{
+			Version: "1.0.0",
+			Func:    addLabel("v1"),
+		},
+		migration.Migration{
+			Version: "1.1.0",
+			Func:    fail,
+		},
+	)
+
+	err := registry.Run(context.Background(), c)
+	require.ErrorIs(t, err, migration.ErrMigration)
+	require.ErrorIs(t, err, errUnexpected)
+
+	legacy := mustGetConfigMap(t, c, "legacy")
+	assert.Equal(t, "1.0.0", legacy.Annotations[constants.MigrationVersionAnnotation])
+	assert.Contains(t, legacy.Labels, "v1")
+}
+
+// TestMigrateInvalidVersion tests invalid and duplicate versions are rejected.
+func TestMigrateInvalidVersion(t *testing.T) {
+	t.Parallel()
+
+	c := mustNewClient(t)
+
+	invalid := migration.NewRegistry().Register(&corev1.ConfigMapList{},
+		migration.Migration{
+			Version: "v1",
+			Func:    addLabel("v1"),
+		},
+	)
+
+	require.ErrorIs(t, invalid.Run(context.Background(), c), migration.ErrVersion)
+
+	duplicate := migration.NewRegistry().Register(&corev1.ConfigMapList{},
+		migration.Migration{
+			Version: "1.0.0",
+			Func:    addLabel("v1"),
+		},
+		migration.Migration{
+			Version: "1.0.0",
+			Func:    addLabel("v1"),
+		},
+	)
+
+	require.ErrorIs(t, duplicate.Run(context.Background(), c), migration.ErrVersion)
+}
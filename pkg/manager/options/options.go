@@ -18,9 +18,13 @@ limitations under the License.
 package options
 
 import (
+	"time"
+
 	"github.com/spf13/pflag"
 
 	"github.com/unikorn-cloud/core/pkg/cd"
+	"github.com/unikorn-cloud/core/pkg/constants"
+	"github.com/unikorn-cloud/core/pkg/manager/maintenance"
 )
 
 // Options defines common controller options.
@@ -36,6 +40,63 @@ type Options struct {
 	// CDDriver defines the continuous-delivery backend driver to use
 	// to manage applications.
 	CDDriver cd.DriverKindFlag
+
+	// HealthProbeBindAddress is where liveness and readiness probes are
+	// served from.
+	HealthProbeBindAddress string
+
+	// MetricsBindAddress is where Prometheus metrics are served from.
+	MetricsBindAddress string
+
+	// ReadyWhenLeader reports the process as not ready until it has been
+	// elected as leader.  Be warned, this will stall rolling upgrades where
+	// the old process retains leadership until the new one is ready.
+	ReadyWhenLeader bool
+
+	// YieldRequeuePeriod is how long to wait before reconciling a resource
+	// that yielded.
+	YieldRequeuePeriod time.Duration
+
+	// YieldRequeueMaxPeriod caps exponential backoff for resources that
+	// continue to yield.  By default this is the same as the yield requeue
+	// period, so yields are retried at a constant rate.
+	YieldRequeueMaxPeriod time.Duration
+
+	// ErrorRequeuePeriod is how long to wait before reconciling a resource
+	// that failed unexpectedly.
+	ErrorRequeuePeriod time.Duration
+
+	// ErrorRequeueMaxPeriod caps exponential backoff for resources that
+	// continue to fail.
+	ErrorRequeueMaxPeriod time.Duration
+
+	// RequeueJitter is the maximum fraction of a requeue period that is
+	// randomly added, to avoid resources being reconciled in lockstep.
+	RequeueJitter float64
+
+	// EventDeduplicationPeriod is how long identical events raised by
+	// provisioners are suppressed for.
+	EventDeduplicationPeriod time.Duration
+
+	// MigrationDryRun reports what resource migrations would be applied, then
+	// exits without starting the controllers.
+	MigrationDryRun bool
+
+	// MaintenanceWindows define when disruptive changes, for example application
+	// upgrades, are allowed.  If none are specified, they always are.
+	MaintenanceWindows maintenance.Windows
+
+	// ChangeFreezes define periods where all resources are paused, other than
+	// those being deleted.
+	ChangeFreezes maintenance.Freezes
+
+	// WebhookPort is the port admission webhooks are served on, if any
+	// controller provides them.
+	WebhookPort int
+
+	// WebhookCertDir contains the webhook server's TLS certificate and key,
+	// named tls.crt and tls.key.
+	WebhookCertDir string
 }
 
 func (o *Options) AddFlags(flags *pflag.FlagSet) {
@@ -44,4 +105,18 @@ func (o *Options) AddFlags(flags *pflag.FlagSet) {
 	flags.StringVar(&o.Namespace, "namespace", "", "Namespace the process is running in")
 	flags.IntVar(&o.MaxConcurrentReconciles, "max-concurrency", 16, "Maximum number of requests to process at the same time")
 	flags.Var(&o.CDDriver, "cd-driver", "CD backend driver to use from [argocd]")
+	flags.StringVar(&o.HealthProbeBindAddress, "health-probe-bind-address", ":8081", "Address to serve liveness and readiness probes on")
+	flags.StringVar(&o.MetricsBindAddress, "metrics-bind-address", ":8080", "Address to serve metrics on, 0 disables the metrics server")
+	flags.BoolVar(&o.ReadyWhenLeader, "ready-when-leader", false, "Report not ready until elected leader")
+	flags.DurationVar(&o.YieldRequeuePeriod, "yield-requeue-period", constants.DefaultYieldTimeout, "Period to wait before reconciling a resource that yielded")
+	flags.DurationVar(&o.YieldRequeueMaxPeriod, "yield-requeue-max-period", constants.DefaultYieldTimeout, "Maximum period to back off to for resources that continue to yield")
+	flags.DurationVar(&o.ErrorRequeuePeriod, "error-requeue-period", constants.DefaultYieldTimeout, "Period to wait before reconciling a resource that failed")
+	flags.DurationVar(&o.ErrorRequeueMaxPeriod, "error-requeue-max-period", 5*time.Minute, "Maximum period to back off to for resources that continue to fail")
+	flags.Float64Var(&o.RequeueJitter, "requeue-jitter", 0.1, "Maximum fraction of a requeue period to randomly add")
+	flags.DurationVar(&o.EventDeduplicationPeriod, "event-deduplication-period", 10*time.Minute, "Period to suppress identical provisioner events for")
+	flags.BoolVar(&o.MigrationDryRun, "migration-dry-run", false, "Report resource migrations that would be applied, then exit")
+	flags.Var(&o.MaintenanceWindows, "maintenance-window", "Period where disruptive changes are allowed in the form '<cron>;<duration>' e.g. '0 2 * * 6;4h', may be specified multiple times")
+	flags.Var(&o.ChangeFreezes, "change-freeze", "Period where all changes, other than deletions, are paused in the form '<start>/<end>' using RFC3339 times, may be specified multiple times")
+	flags.IntVar(&o.WebhookPort, "webhook-port", 9443, "Port to serve admission webhooks on")
+	flags.StringVar(&o.WebhookCertDir, "webhook-cert-dir", "", "Directory containing the webhook TLS certificate and key, defaults to the controller-runtime location")
 }
@@ -0,0 +1,137 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	unikornv1 "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1"
+	"github.com/unikorn-cloud/core/pkg/constants"
+	"github.com/unikorn-cloud/core/pkg/manager/maintenance"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// pause describes why a resource is paused.
+type pause struct {
+	// reason is why the resource is paused.
+	reason string
+
+	// until, if set, is when the pause expires.
+	until time.Time
+}
+
+// message returns a human readable message for the Paused condition.
+func (p *pause) message() string {
+	if p.until.IsZero() {
+		return p.reason
+	}
+
+	return p.reason + " until " + p.until.Format(time.RFC3339)
+}
+
+// getPause returns whether the resource is paused, either explicitly or due to
+// a change freeze.  Expired pauses are ignored, and invalid expiry times are
+// treated as an indefinite pause as that's the safest option.  Change freezes
+// don't apply to resources being deleted, deletion is explicitly requested, and
+// blocking it would leave clients waiting until the freeze ends.
+func (r *Reconciler) getPause(ctx context.Context, object unikornv1.ManagableResourceInterface, now time.Time) *pause {
+	log := log.FromContext(ctx)
+
+	if freeze := r.options.ChangeFreezes.Active(now); freeze != nil && object.GetDeletionTimestamp() == nil {
+		return &pause{
+			reason: "Change freeze",
+			until:  freeze.End,
+		}
+	}
+
+	if object.Paused() {
+		return &pause{
+			reason: "Paused",
+		}
+	}
+
+	annotations := object.GetAnnotations()
+
+	reason, ok := annotations[constants.PausedAnnotation]
+	if !ok {
+		return nil
+	}
+
+	if reason == "" {
+		reason = "Paused"
+	}
+
+	p := &pause{
+		reason: reason,
+	}
+
+	if value, ok := annotations[constants.PausedUntilAnnotation]; ok {
+		until, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			log.Error(err, "invalid pause expiry, pausing indefinitely")
+
+			return p
+		}
+
+		if !now.Before(until) {
+			return nil
+		}
+
+		p.until = until
+	}
+
+	return p
+}
+
+// disruptionAllowed returns whether disruptive changes are allowed for the resource
+// and when that next changes.  Resources may override the controller's maintenance
+// windows, but invalid overrides are ignored.
+func (r *Reconciler) disruptionAllowed(ctx context.Context, object unikornv1.ManagableResourceInterface, now time.Time) (bool, time.Time) {
+	log := log.FromContext(ctx)
+
+	windows := r.options.MaintenanceWindows
+
+	if value, ok := object.GetAnnotations()[constants.MaintenanceWindowAnnotation]; ok {
+		window, err := maintenance.ParseWindow(value)
+		if err != nil {
+			log.Error(err, "invalid maintenance window, ignoring")
+		} else {
+			windows = maintenance.Windows{window}
+		}
+	}
+
+	return windows.Open(now)
+}
+
+// requeueBefore ensures the resource is reconciled again no later than the given
+// time, if set.
+func requeueBefore(result reconcile.Result, now, t time.Time) reconcile.Result {
+	if t.IsZero() {
+		return result
+	}
+
+	after := t.Sub(now)
+
+	if result.RequeueAfter == 0 || after < result.RequeueAfter {
+		result.RequeueAfter = after
+	}
+
+	return result
+}
@@ -21,20 +21,36 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	unikornv1 "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1"
 	"github.com/unikorn-cloud/core/pkg/cd"
 	"github.com/unikorn-cloud/core/pkg/cd/argocd"
+	cdtracing "github.com/unikorn-cloud/core/pkg/cd/tracing"
 	"github.com/unikorn-cloud/core/pkg/client"
 	"github.com/unikorn-cloud/core/pkg/constants"
 	coreerrors "github.com/unikorn-cloud/core/pkg/errors"
+	"github.com/unikorn-cloud/core/pkg/manager/maintenance"
 	"github.com/unikorn-cloud/core/pkg/manager/options"
+	"github.com/unikorn-cloud/core/pkg/manager/requeue"
 	"github.com/unikorn-cloud/core/pkg/provisioners"
 	"github.com/unikorn-cloud/core/pkg/provisioners/application"
+	"github.com/unikorn-cloud/core/pkg/provisioners/remotecluster"
+	"github.com/unikorn-cloud/core/pkg/util/otel"
 
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -47,6 +63,22 @@ var (
 	ErrResourceError = errors.New("unable to assert resource type")
 )
 
+// transitionEventMessages are the normal events raised when the Available
+// condition changes.
+//
+//nolint:gochecknoglobals
+var transitionEventMessages = map[unikornv1.ConditionReason]string{
+	unikornv1.ConditionReasonProvisioning:   "Provisioning started",
+	unikornv1.ConditionReasonProvisioned:    "Provisioned successfully",
+	unikornv1.ConditionReasonDeprovisioning: "Deprovisioning started",
+	unikornv1.ConditionReasonDeprovisioned:  "Deprovisioned successfully",
+}
+
+const (
+	// eventRecorderName is the component events are reported as coming from.
+	eventRecorderName = "unikorn-controller-manager"
+)
+
 // ProvisionerCreateFunc provides a type agnosic method to create a root provisioner.
 type ProvisionerCreateFunc func(ControllerOptions) provisioners.ManagerProvisioner
 
@@ -63,39 +95,138 @@ type Reconciler struct {
 
 	// controllerOptions are options to be passed to the reconciler.
 	controllerOptions ControllerOptions
+
+	// requeuePolicy decides when to reconcile again after a yield or error.
+	requeuePolicy requeue.Policy
+
+	// recorder records events for status condition transitions.
+	recorder record.EventRecorder
+
+	// events is shared by provisioners so events are deduplicated across
+	// reconciles.
+	events *provisioners.EventRecorder
 }
 
 // NewReconciler creates a new reconciler.
 func NewReconciler(options *options.Options, controllerOptions ControllerOptions, manager manager.Manager, createProvisioner ProvisionerCreateFunc) *Reconciler {
+	recorder := manager.GetEventRecorderFor(eventRecorderName)
+
 	return &Reconciler{
 		options:           options,
 		manager:           manager,
 		createProvisioner: createProvisioner,
 		controllerOptions: controllerOptions,
+		requeuePolicy:     newRequeuePolicy(options),
+		recorder:          recorder,
+		events:            provisioners.NewEventRecorder(recorder, options.EventDeduplicationPeriod),
+	}
+}
+
+// newRequeuePolicy creates the default requeue policy from the options.
+func newRequeuePolicy(o *options.Options) requeue.Policy {
+	yieldBackoff := requeue.Backoff{
+		Period:    o.YieldRequeuePeriod,
+		MaxPeriod: o.YieldRequeueMaxPeriod,
 	}
+
+	errorBackoff := requeue.Backoff{
+		Period:    o.ErrorRequeuePeriod,
+		MaxPeriod: o.ErrorRequeueMaxPeriod,
+	}
+
+	return requeue.NewExponential(yieldBackoff, errorBackoff, o.RequeueJitter)
+}
+
+// WithRequeuePolicy overrides the default requeue policy.
+func (r *Reconciler) WithRequeuePolicy(policy requeue.Policy) *Reconciler {
+	r.requeuePolicy = policy
+
+	return r
+}
+
+// requeue returns the result for a reconcile that completed, yielded, or failed.
+// NOTE: DO NOT return an error from the reconcile, the policy is in charge of
+// backoff, not controller-runtime.
+func (r *Reconciler) requeue(key types.NamespacedName, err error) reconcile.Result {
+	if err == nil {
+		r.requeuePolicy.Forget(key)
+
+		return reconcile.Result{}
+	}
+
+	if errors.Is(err, provisioners.ErrYield) {
+		hint, _ := provisioners.RequeueAfterHint(err)
+
+		return reconcile.Result{RequeueAfter: r.requeuePolicy.Yield(key, hint)}
+	}
+
+	return reconcile.Result{RequeueAfter: r.requeuePolicy.Error(key, err)}
 }
 
 // Ensure this implements the reconcile.Reconciler interface.
 var _ reconcile.Reconciler = &Reconciler{}
 
-func (r *Reconciler) getDriver() (cd.Driver, error) {
-	if r.options.CDDriver.Kind != cd.DriverKindArgoCD {
+// newDriver creates a CD driver as defined by the options.
+func newDriver(o *options.Options, client crclient.Client) (cd.Driver, error) {
+	if o.CDDriver.Kind != cd.DriverKindArgoCD {
 		return nil, coreerrors.ErrCDDriver
 	}
 
-	return argocd.New(r.manager.GetClient(), argocd.Options{}), nil
+	return cdtracing.New(argocd.New(client, argocd.Options{})), nil
+}
+
+func (r *Reconciler) getDriver() (cd.Driver, error) {
+	return newDriver(r.options, r.manager.GetClient())
 }
 
 // Reconcile is the top-level reconcile interface that controller-runtime will
 // dispatch to.  It initialises the provisioner, extracts the request object and
 // based on whether it exists or not, reconciles or deletes the object respectively.
+// Every reconcile is traced, with the provisioner tree and CD driver calls
+// recorded as child spans.
 func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
-	log := log.FromContext(ctx)
-
 	provisioner := r.createProvisioner(r.controllerOptions)
 
 	object := provisioner.Object()
 
+	kind := r.kind(object)
+
+	attr := []attribute.KeyValue{
+		attribute.String("k8s.resource.kind", kind),
+		attribute.String("k8s.namespace.name", request.Namespace),
+		attribute.String("k8s.resource.name", request.Name),
+	}
+
+	// Retain the controller's logging values e.g. the resource and reconcile ID
+	// in all spans.
+	ctx = otel.NewContextWithLogger(ctx, log.FromContext(ctx))
+
+	ctx, span := otel.StartSpan(ctx, "reconcile "+kind, trace.WithAttributes(attr...))
+	defer span.End()
+
+	result, err := r.reconcile(ctx, request, provisioner, object)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return result, err
+}
+
+// kind returns the resource kind for tracing and metrics.
+func (r *Reconciler) kind(object unikornv1.ManagableResourceInterface) string {
+	gvk, err := apiutil.GVKForObject(object, r.manager.GetClient().Scheme())
+	if err != nil {
+		return "unknown"
+	}
+
+	return gvk.Kind
+}
+
+// reconcile does the actual reconciliation.
+func (r *Reconciler) reconcile(ctx context.Context, request reconcile.Request, provisioner provisioners.ManagerProvisioner, object unikornv1.ManagableResourceInterface) (reconcile.Result, error) {
+	log := log.FromContext(ctx)
+
 	driver, err := r.getDriver()
 	if err != nil {
 		return reconcile.Result{}, err
@@ -112,10 +243,15 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 	// application bundles and definitions regardless of remote cluster scoping etc.
 	ctx = client.NewContextWithProvisionerClient(ctx, r.manager.GetClient())
 
+	// The uncached reader allows resources we don't watch to be read without
+	// starting cluster wide informers.
+	ctx = client.NewContextWithProvisionerReader(ctx, r.manager.GetAPIReader())
+
 	// The cluster context is updated as remote clusters are descended into.
 	clusterContext := &client.ClusterContext{
 		// TODO: cluster information.
-		Client: r.manager.GetClient(),
+		Client:     r.manager.GetClient(),
+		RESTConfig: r.manager.GetConfig(),
 	}
 
 	ctx = client.NewContextWithCluster(ctx, clusterContext)
@@ -127,21 +263,47 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 	// their creation.
 	ctx = application.NewContext(ctx, object)
 
+	// Remote cluster registration and deletion is tracked per reconcile.
+	ctx = remotecluster.NewContextWithInvocation(ctx)
+
+	// Provisioners may report status that's reflected in the resource conditions.
+	ctx = provisioners.NewContextWithReconcileStatus(ctx)
+
+	// Provisioners may raise events against the resource.
+	ctx = provisioners.NewContextWithEventRecorder(ctx, r.events, object)
+
 	// See if the object exists or not, if not it's been deleted.
 	if err := r.manager.GetClient().Get(ctx, request.NamespacedName, object); err != nil {
 		if kerrors.IsNotFound(err) {
 			log.Info("object deleted")
 
+			r.requeuePolicy.Forget(request.NamespacedName)
+
 			return reconcile.Result{}, nil
 		}
 
 		return reconcile.Result{}, err
 	}
 
-	if object.Paused() {
-		log.Info("reconcilication paused")
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("k8s.resource.uid", string(object.GetUID())),
+		attribute.Int64("k8s.resource.generation", object.GetGeneration()),
+	)
+
+	now := time.Now().UTC()
+
+	if pause := r.getPause(ctx, object, now); pause != nil {
+		log.Info("reconcilication paused", "reason", pause.reason)
+
+		mutate := func() {
+			writeCondition(object, unikornv1.ConditionPaused, corev1.ConditionTrue, unikornv1.ConditionReasonPaused, pause.message())
+		}
+
+		if err := r.updateStatus(ctx, object, mutate); err != nil {
+			return reconcile.Result{}, err
+		}
 
-		return reconcile.Result{}, nil
+		return requeueBefore(reconcile.Result{}, now, pause.until), nil
 	}
 
 	// If it's being deleted, ignore if there are no finalizers, Kubernetes is in
@@ -156,31 +318,41 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		return r.reconcileDelete(ctx, provisioner, object)
 	}
 
+	// Disruptive changes may only be allowed in maintenance windows, if we're
+	// outside of one, then reconcile again when it opens to pick them up.
+	allowed, boundary := r.disruptionAllowed(ctx, object, now)
+
+	ctx = maintenance.NewContext(ctx, allowed)
+
 	// Create or update the resource.
-	log.Info("reconciling object")
+	log.Info("reconciling object", "disruptionAllowed", allowed)
+
+	result, err := r.reconcileNormal(ctx, provisioner, object)
+
+	if !allowed {
+		result = requeueBefore(result, now, boundary)
+	}
 
-	return r.reconcileNormal(ctx, provisioner, object)
+	return result, err
 }
 
 // reconcileDelete handles object deletion.
 func (r *Reconciler) reconcileDelete(ctx context.Context, provisioner provisioners.Provisioner, object unikornv1.ManagableResourceInterface) (reconcile.Result, error) {
 	log := log.FromContext(ctx)
 
-	perr := provisioner.Deprovision(ctx)
+	perr := provisioners.Deprovision(ctx, provisioner)
 
 	if err := r.handleReconcileCondition(ctx, object, perr, true); err != nil {
 		return reconcile.Result{}, err
 	}
 
 	// If anything went wrong, requeue for another attempt.
-	// NOTE: DO NOT return an error, and use a constant period or you will
-	// suffer from an exponential back-off and kill performance.
 	if perr != nil {
 		if !errors.Is(perr, provisioners.ErrYield) {
 			log.Error(perr, "deprovisioning failed unexpectedly")
 		}
 
-		return reconcile.Result{RequeueAfter: constants.DefaultYieldTimeout}, nil
+		return r.requeue(crclient.ObjectKeyFromObject(object), perr), nil
 	}
 
 	// All good, signal the resource can be deleted.
@@ -190,7 +362,7 @@ func (r *Reconciler) reconcileDelete(ctx context.Context, provisioner provisione
 		}
 	}
 
-	return reconcile.Result{}, nil
+	return r.requeue(crclient.ObjectKeyFromObject(object), nil), nil
 }
 
 // reconcileNormal adds the application finalizer, provisions the resource and
@@ -205,7 +377,7 @@ func (r *Reconciler) reconcileNormal(ctx context.Context, provisioner provisione
 		}
 	}
 
-	perr := provisioner.Provision(ctx)
+	perr := provisioners.Provision(ctx, provisioner)
 
 	// Update the status conditionally, this will remove transient errors etc.
 	if err := r.handleReconcileCondition(ctx, object, perr, false); err != nil {
@@ -213,17 +385,103 @@ func (r *Reconciler) reconcileNormal(ctx context.Context, provisioner provisione
 	}
 
 	// If anything went wrong, requeue for another attempt.
-	// NOTE: DO NOT return an error, and use a constant period or you will
-	// suffer from an exponential back-off and kill performance.
-	if perr != nil {
-		if !errors.Is(perr, provisioners.ErrYield) {
-			log.Error(perr, "provisioning failed unexpectedly")
-		}
+	if perr != nil && !errors.Is(perr, provisioners.ErrYield) {
+		log.Error(perr, "provisioning failed unexpectedly")
+	}
+
+	return r.requeue(crclient.ObjectKeyFromObject(object), perr), nil
+}
+
+// writeCondition writes a condition and records the generation it was observed for.
+func writeCondition(object unikornv1.ManagableResourceInterface, t unikornv1.ConditionType, status corev1.ConditionStatus, reason unikornv1.ConditionReason, message string) {
+	object.StatusConditionWrite(t, status, reason, message)
+
+	if condition, err := object.StatusConditionRead(t); err == nil {
+		condition.ObservedGeneration = object.GetGeneration()
+	}
+}
+
+// conditionTrue returns whether the condition exists and is true.
+func conditionTrue(object unikornv1.ManagableResourceInterface, t unikornv1.ConditionType) bool {
+	condition, err := object.StatusConditionRead(t)
+	if err != nil {
+		return false
+	}
+
+	return condition.Status == corev1.ConditionTrue
+}
+
+// handleExtendedConditions sets the Healthy, Upgrading and Paused conditions based
+// on what was reported by provisioners during the reconcile.
+func handleExtendedConditions(ctx context.Context, object unikornv1.ManagableResourceInterface, err error) {
+	status := provisioners.ReconcileStatusFromContext(ctx)
+	if status == nil {
+		return
+	}
+
+	// A resource is healthy if it provisioned without anything reporting a problem,
+	// and it's unknown if we didn't make it to the end.  Yields leave the condition
+	// alone as nothing has been learned.
+	degraded := status.Degraded()
+
+	switch {
+	case len(degraded) != 0:
+		writeCondition(object, unikornv1.ConditionHealthy, corev1.ConditionFalse, unikornv1.ConditionReasonDegraded, strings.Join(degraded, ", "))
+	case err == nil:
+		writeCondition(object, unikornv1.ConditionHealthy, corev1.ConditionTrue, unikornv1.ConditionReasonHealthy, "Healthy")
+	case !errors.Is(err, provisioners.ErrYield):
+		writeCondition(object, unikornv1.ConditionHealthy, corev1.ConditionUnknown, unikornv1.ConditionReasonErrored, "Health unknown due to error")
+	}
 
-		return reconcile.Result{RequeueAfter: constants.DefaultYieldTimeout}, nil
+	// Upgrades will typically yield until complete, so are only done when
+	// provisioning completes without anything reporting an upgrade.
+	if upgrading := status.Upgrading(); len(upgrading) != 0 {
+		writeCondition(object, unikornv1.ConditionUpgrading, corev1.ConditionTrue, unikornv1.ConditionReasonUpgrading, "Upgrading "+strings.Join(upgrading, ", "))
+	} else if err == nil && conditionTrue(object, unikornv1.ConditionUpgrading) {
+		writeCondition(object, unikornv1.ConditionUpgrading, corev1.ConditionFalse, unikornv1.ConditionReasonUpgraded, "Upgraded")
 	}
 
-	return reconcile.Result{}, nil
+	// If we got this far, we're not paused.
+	if conditionTrue(object, unikornv1.ConditionPaused) {
+		writeCondition(object, unikornv1.ConditionPaused, corev1.ConditionFalse, unikornv1.ConditionReasonResumed, "Resumed")
+	}
+}
+
+// updateStatus applies the mutation to the object's status and persists it.  Most
+// reconciles, especially yields, don't change anything, so the write is skipped
+// entirely in that case.  Otherwise the status is patched using optimistic locking,
+// so we never overwrite conditions we haven't seen, and on conflict the object is
+// reread and the mutation applied again.
+func (r *Reconciler) updateStatus(ctx context.Context, object unikornv1.ManagableResourceInterface, mutate func()) error {
+	c := r.manager.GetClient()
+
+	first := true
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if !first {
+			if err := c.Get(ctx, crclient.ObjectKeyFromObject(object), object); err != nil {
+				return err
+			}
+		}
+
+		first = false
+
+		//nolint:forcetypeassert
+		original := object.DeepCopyObject().(crclient.Object)
+
+		mutate()
+
+		data, err := crclient.MergeFrom(original).Data(object)
+		if err != nil {
+			return err
+		}
+
+		if string(data) == "{}" {
+			return nil
+		}
+
+		return c.Status().Patch(ctx, object, crclient.MergeFromWithOptions(original, crclient.MergeFromWithOptimisticLock{}))
+	})
 }
 
 // handleReconcileCondition inspects the error, if any, that halted the provisioning and reports
@@ -264,11 +522,49 @@ func (r *Reconciler) handleReconcileCondition(ctx context.Context, object unikor
 		message = fmt.Sprintf("Unhandled error: %v", err)
 	}
 
-	object.StatusConditionWrite(unikornv1.ConditionAvailable, status, reason, message)
+	// Take a copy of the previous condition, the write may modify it in place.
+	var previous *unikornv1.Condition
+
+	if condition, err := object.StatusConditionRead(unikornv1.ConditionAvailable); err == nil {
+		previous = condition.DeepCopy()
+	}
 
-	if err := r.manager.GetClient().Status().Update(ctx, object); err != nil {
+	mutate := func() {
+		writeCondition(object, unikornv1.ConditionAvailable, status, reason, message)
+		writeCondition(object, unikornv1.ConditionReconciled, status, reason, message)
+
+		if !deprovision {
+			handleExtendedConditions(ctx, object, err)
+		}
+	}
+
+	recordReconcileMetrics(r.kind(object), previous, reason)
+
+	if err := r.updateStatus(ctx, object, mutate); err != nil {
 		return err
 	}
 
+	r.recordTransitionEvent(object, previous, reason, message)
+
 	return nil
 }
+
+// recordTransitionEvent raises an event when the Available condition changes.
+// Errors are reported each time the message changes so new problems are visible.
+// This is only called once the status has been persisted, otherwise a failed
+// update would cause the event to be raised again.
+func (r *Reconciler) recordTransitionEvent(object unikornv1.ManagableResourceInterface, previous *unikornv1.Condition, reason unikornv1.ConditionReason, message string) {
+	if previous != nil && previous.Reason == reason && (reason != unikornv1.ConditionReasonErrored || previous.Message == message) {
+		return
+	}
+
+	if reason == unikornv1.ConditionReasonErrored {
+		r.recorder.Event(object, corev1.EventTypeWarning, string(reason), message)
+
+		return
+	}
+
+	if message, ok := transitionEventMessages[reason]; ok {
+		r.recorder.Event(object, corev1.EventTypeNormal, string(reason), message)
+	}
+}
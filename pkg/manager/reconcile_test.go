@@ -22,7 +22,9 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"fmt"
 	"os"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -35,6 +37,7 @@ import (
 	coreclient "github.com/unikorn-cloud/core/pkg/client"
 	"github.com/unikorn-cloud/core/pkg/constants"
 	"github.com/unikorn-cloud/core/pkg/manager"
+	"github.com/unikorn-cloud/core/pkg/manager/maintenance"
 	mockmanager "github.com/unikorn-cloud/core/pkg/manager/mock"
 	"github.com/unikorn-cloud/core/pkg/manager/options"
 	"github.com/unikorn-cloud/core/pkg/provisioners"
@@ -44,9 +47,11 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	crmanager "sigs.k8s.io/controller-runtime/pkg/manager"
@@ -68,10 +73,18 @@ func TestMain(m *testing.M) {
 
 // testContext provides a common framework for test execution.
 type testContext struct {
-	client client.Client
+	client   client.Client
+	recorder *record.FakeRecorder
+
+	// statusWrites counts the number of status writes.
+	statusWrites atomic.Int64
+
+	// conflicts causes the next status writes to conflict with a concurrent
+	// update.
+	conflicts atomic.Int64
 }
 
-func mustNewTestContext(t *testing.T, objects ...client.Object) *testContext {
+func mustNewTestContext(t testing.TB, objects ...client.Object) *testContext {
 	t.Helper()
 
 	scheme, err := coreclient.NewScheme()
@@ -80,12 +93,54 @@ func mustNewTestContext(t *testing.T, objects ...client.Object) *testContext {
 	}
 
 	tc := &testContext{
-		client: fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&unikornv1fake.ManagedResource{}).WithObjects(objects...).Build(),
+		recorder: record.NewFakeRecorder(16),
+	}
+
+	funcs := interceptor.Funcs{
+		SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+			tc.statusWrites.Add(1)
+
+			return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+		},
+		SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+			tc.statusWrites.Add(1)
+
+			if tc.conflicts.Add(-1) >= 0 {
+				if err := bumpResourceVersion(ctx, c, obj); err != nil {
+					return err
+				}
+			}
+
+			return c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+		},
 	}
 
+	tc.client = fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&unikornv1fake.ManagedResource{}).WithObjects(objects...).WithInterceptorFuncs(funcs).Build()
+
 	return tc
 }
 
+// bumpResourceVersion simulates a concurrent update of the object.
+func bumpResourceVersion(ctx context.Context, c client.Client, obj client.Object) error {
+	//nolint:forcetypeassert
+	current := obj.DeepCopyObject().(client.Object)
+
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+		return err
+	}
+
+	labels := current.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	labels["bump"] = current.GetResourceVersion()
+
+	current.SetLabels(labels)
+
+	return c.Update(ctx, current)
+}
+
 func newNamespacedName(namespace, name string) types.NamespacedName {
 	namespacedName := types.NamespacedName{
 		Namespace: namespace,
@@ -108,6 +163,9 @@ func (tc *testContext) newManager(c *gomock.Controller) crmanager.Manager {
 	m := mockmanager.NewMockManager(c)
 
 	m.EXPECT().GetClient().Return(tc.client).AnyTimes()
+	m.EXPECT().GetConfig().Return(nil).AnyTimes()
+	m.EXPECT().GetAPIReader().Return(tc.client).AnyTimes()
+	m.EXPECT().GetEventRecorderFor(gomock.Any()).Return(tc.recorder).AnyTimes()
 
 	return m
 }
@@ -182,6 +240,7 @@ func TestReconcileCreate(t *testing.T) {
 
 	p := mockprovisioners.NewMockManagerProvisioner(c)
 	p.EXPECT().Object().Return(&unikornv1fake.ManagedResource{})
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
 	p.EXPECT().Provision(gomock.Any()).Return(nil)
 
 	reconciler := manager.NewReconciler(managerOptions(), nil, tc.newManager(c), func(_ manager.ControllerOptions) provisioners.ManagerProvisioner { return p })
@@ -217,6 +276,7 @@ func TestReconcileCreateYield(t *testing.T) {
 
 	p := mockprovisioners.NewMockManagerProvisioner(c)
 	p.EXPECT().Object().Return(&unikornv1fake.ManagedResource{})
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
 	p.EXPECT().Provision(gomock.Any()).Return(provisioners.ErrYield)
 
 	reconciler := manager.NewReconciler(managerOptions(), nil, tc.newManager(c), func(_ manager.ControllerOptions) provisioners.ManagerProvisioner { return p })
@@ -232,6 +292,202 @@ func TestReconcileCreateYield(t *testing.T) {
 	mustAssertStatus(t, &result, corev1.ConditionFalse, unikornv1.ConditionReasonProvisioning)
 }
 
+// TestReconcileCreateYieldHint tests the provisioner can suggest when to requeue.
+func TestReconcileCreateYieldHint(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	request := &unikornv1fake.ManagedResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testName,
+		},
+	}
+
+	tc := mustNewTestContext(t, request)
+	ctx := context.Background()
+
+	p := mockprovisioners.NewMockManagerProvisioner(c)
+	p.EXPECT().Object().Return(&unikornv1fake.ManagedResource{})
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Provision(gomock.Any()).Return(fmt.Errorf("%w: waiting", provisioners.YieldAfter(time.Minute)))
+
+	reconciler := manager.NewReconciler(managerOptions(), nil, tc.newManager(c), func(_ manager.ControllerOptions) provisioners.ManagerProvisioner { return p })
+
+	result, err := reconciler.Reconcile(ctx, newRequest(testNamespace, testName))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, result.RequeueAfter)
+
+	var resource unikornv1fake.ManagedResource
+
+	assert.NoError(t, tc.client.Get(ctx, newNamespacedName(testNamespace, testName), &resource))
+	mustAssertStatus(t, &resource, corev1.ConditionFalse, unikornv1.ConditionReasonProvisioning)
+}
+
+// TestReconcileCreateDegraded tests a resource is available but unhealthy when a
+// provisioner reports it's degraded, and that the observed generation is recorded.
+func TestReconcileCreateDegraded(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	request := &unikornv1fake.ManagedResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  testNamespace,
+			Name:       testName,
+			Generation: 3,
+		},
+	}
+
+	tc := mustNewTestContext(t, request)
+	ctx := context.Background()
+
+	degraded := func(ctx context.Context) error {
+		provisioners.ReportDegraded(ctx, "dns unavailable")
+
+		return nil
+	}
+
+	p := mockprovisioners.NewMockManagerProvisioner(c)
+	p.EXPECT().Object().Return(&unikornv1fake.ManagedResource{})
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Provision(gomock.Any()).DoAndReturn(degraded)
+
+	reconciler := manager.NewReconciler(managerOptions(), nil, tc.newManager(c), func(_ manager.ControllerOptions) provisioners.ManagerProvisioner { return p })
+
+	_, err := reconciler.Reconcile(ctx, newRequest(testNamespace, testName))
+	assert.NoError(t, err)
+
+	var result unikornv1fake.ManagedResource
+
+	assert.NoError(t, tc.client.Get(ctx, newNamespacedName(testNamespace, testName), &result))
+	mustAssertStatus(t, &result, corev1.ConditionTrue, unikornv1.ConditionReasonProvisioned)
+
+	healthy, err := result.StatusConditionRead(unikornv1.ConditionHealthy)
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.ConditionFalse, healthy.Status)
+	assert.Equal(t, unikornv1.ConditionReasonDegraded, healthy.Reason)
+	assert.Equal(t, "dns unavailable", healthy.Message)
+
+	reconciled, err := result.StatusConditionRead(unikornv1.ConditionReconciled)
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.ConditionTrue, reconciled.Status)
+	assert.Equal(t, int64(3), reconciled.ObservedGeneration)
+}
+
+// TestReconcileCreateUpgrading tests the upgrading condition is set while provisioners
+// report an upgrade, and cleared once provisioning completes.
+func TestReconcileCreateUpgrading(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	request := &unikornv1fake.ManagedResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testName,
+		},
+	}
+
+	tc := mustNewTestContext(t, request)
+	ctx := context.Background()
+
+	upgrading := func(ctx context.Context) error {
+		provisioners.ReportUpgrading(ctx, "cilium")
+
+		return provisioners.ErrYield
+	}
+
+	p := mockprovisioners.NewMockManagerProvisioner(c)
+	p.EXPECT().Object().DoAndReturn(func() unikornv1.ManagableResourceInterface { return &unikornv1fake.ManagedResource{} }).Times(2)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Provision(gomock.Any()).DoAndReturn(upgrading)
+	p.EXPECT().Provision(gomock.Any()).Return(nil)
+
+	reconciler := manager.NewReconciler(managerOptions(), nil, tc.newManager(c), func(_ manager.ControllerOptions) provisioners.ManagerProvisioner { return p })
+
+	mustAssertUpgrading := func(status corev1.ConditionStatus, reason unikornv1.ConditionReason) {
+		_, err := reconciler.Reconcile(ctx, newRequest(testNamespace, testName))
+		assert.NoError(t, err)
+
+		var result unikornv1fake.ManagedResource
+
+		assert.NoError(t, tc.client.Get(ctx, newNamespacedName(testNamespace, testName), &result))
+
+		condition, err := result.StatusConditionRead(unikornv1.ConditionUpgrading)
+		assert.NoError(t, err)
+		assert.Equal(t, status, condition.Status)
+		assert.Equal(t, reason, condition.Reason)
+	}
+
+	mustAssertUpgrading(corev1.ConditionTrue, unikornv1.ConditionReasonUpgrading)
+	mustAssertUpgrading(corev1.ConditionFalse, unikornv1.ConditionReasonUpgraded)
+}
+
+// drainEvents returns all events recorded so far.
+func drainEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+// TestReconcileEvents tests events are raised on condition transitions only, and
+// that provisioner events are deduplicated across reconciles.
+func TestReconcileEvents(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	request := &unikornv1fake.ManagedResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testName,
+		},
+	}
+
+	tc := mustNewTestContext(t, request)
+	ctx := context.Background()
+
+	yield := func(ctx context.Context) error {
+		provisioners.RecordEvent(ctx, corev1.EventTypeNormal, "Waiting", "Waiting for cluster")
+
+		return provisioners.ErrYield
+	}
+
+	p := mockprovisioners.NewMockManagerProvisioner(c)
+	p.EXPECT().Object().DoAndReturn(func() unikornv1.ManagableResourceInterface { return &unikornv1fake.ManagedResource{} }).Times(4)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Provision(gomock.Any()).DoAndReturn(yield).Times(2)
+	p.EXPECT().Provision(gomock.Any()).Return(errors.New("boom")) //nolint:err113
+	p.EXPECT().Provision(gomock.Any()).Return(nil)
+
+	reconciler := manager.NewReconciler(managerOptions(), nil, tc.newManager(c), func(_ manager.ControllerOptions) provisioners.ManagerProvisioner { return p })
+
+	mustReconcile := func() []string {
+		_, err := reconciler.Reconcile(ctx, newRequest(testNamespace, testName))
+		assert.NoError(t, err)
+
+		return drainEvents(tc.recorder)
+	}
+
+	assert.Equal(t, []string{"Normal Waiting Waiting for cluster", "Normal Provisioning Provisioning started"}, mustReconcile())
+	assert.Empty(t, mustReconcile())
+	assert.Equal(t, []string{"Warning Errored Unhandled error: boom"}, mustReconcile())
+	assert.Equal(t, []string{"Normal Provisioned Provisioned successfully"}, mustReconcile())
+}
+
 // TestReconcileCreateCancelled tests resource creation and the status when the context
 // is cancelled.
 func TestReconcileCreateCancelled(t *testing.T) {
@@ -254,6 +510,7 @@ func TestReconcileCreateCancelled(t *testing.T) {
 
 	p := mockprovisioners.NewMockManagerProvisioner(c)
 	p.EXPECT().Object().Return(&unikornv1fake.ManagedResource{})
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
 	p.EXPECT().Provision(gomock.Any()).Return(ctx.Err())
 
 	reconciler := manager.NewReconciler(managerOptions(), nil, tc.newManager(c), func(_ manager.ControllerOptions) provisioners.ManagerProvisioner { return p })
@@ -289,6 +546,7 @@ func TestReconcileCreateError(t *testing.T) {
 
 	p := mockprovisioners.NewMockManagerProvisioner(c)
 	p.EXPECT().Object().Return(&unikornv1fake.ManagedResource{})
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
 	p.EXPECT().Provision(gomock.Any()).Return(errUnhandled)
 
 	reconciler := manager.NewReconciler(managerOptions(), nil, tc.newManager(c), func(_ manager.ControllerOptions) provisioners.ManagerProvisioner { return p })
@@ -330,6 +588,7 @@ func TestReconcileDelete(t *testing.T) {
 
 	p := mockprovisioners.NewMockManagerProvisioner(c)
 	p.EXPECT().Object().Return(&unikornv1fake.ManagedResource{})
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
 	p.EXPECT().Deprovision(gomock.Any()).Return(nil)
 
 	reconciler := manager.NewReconciler(managerOptions(), nil, tc.newManager(c), func(_ manager.ControllerOptions) provisioners.ManagerProvisioner { return p })
@@ -372,6 +631,7 @@ func TestReconcileDeleteYield(t *testing.T) {
 
 	p := mockprovisioners.NewMockManagerProvisioner(c)
 	p.EXPECT().Object().Return(&unikornv1fake.ManagedResource{})
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
 	p.EXPECT().Deprovision(gomock.Any()).Return(provisioners.ErrYield)
 
 	reconciler := manager.NewReconciler(managerOptions(), nil, tc.newManager(c), func(_ manager.ControllerOptions) provisioners.ManagerProvisioner { return p })
@@ -415,6 +675,7 @@ func TestReconcileDeleteCancelled(t *testing.T) {
 
 	p := mockprovisioners.NewMockManagerProvisioner(c)
 	p.EXPECT().Object().Return(&unikornv1fake.ManagedResource{})
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
 	p.EXPECT().Deprovision(gomock.Any()).Return(ctx.Err())
 
 	reconciler := manager.NewReconciler(managerOptions(), nil, tc.newManager(c), func(_ manager.ControllerOptions) provisioners.ManagerProvisioner { return p })
@@ -456,6 +717,7 @@ func TestReconcileDeleteError(t *testing.T) {
 
 	p := mockprovisioners.NewMockManagerProvisioner(c)
 	p.EXPECT().Object().Return(&unikornv1fake.ManagedResource{})
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
 	p.EXPECT().Deprovision(gomock.Any()).Return(errUnhandled)
 
 	reconciler := manager.NewReconciler(managerOptions(), nil, tc.newManager(c), func(_ manager.ControllerOptions) provisioners.ManagerProvisioner { return p })
@@ -470,3 +732,353 @@ func TestReconcileDeleteError(t *testing.T) {
 	assert.Contains(t, result.Finalizers, constants.Finalizer)
 	mustAssertStatus(t, &result, corev1.ConditionFalse, unikornv1.ConditionReasonErrored)
 }
+
+// TestReconcileStatusNoop tests the status isn't written when nothing changes.
+func TestReconcileStatusNoop(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	request := &unikornv1fake.ManagedResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testName,
+		},
+	}
+
+	tc := mustNewTestContext(t, request)
+	ctx := context.Background()
+
+	p := mockprovisioners.NewMockManagerProvisioner(c)
+	p.EXPECT().Object().DoAndReturn(func() unikornv1.ManagableResourceInterface { return &unikornv1fake.ManagedResource{} }).Times(3)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Provision(gomock.Any()).Return(provisioners.ErrYield).Times(2)
+	p.EXPECT().Provision(gomock.Any()).Return(nil)
+
+	reconciler := manager.NewReconciler(managerOptions(), nil, tc.newManager(c), func(_ manager.ControllerOptions) provisioners.ManagerProvisioner { return p })
+
+	for _, writes := range []int64{1, 1, 2} {
+		_, err := reconciler.Reconcile(ctx, newRequest(testNamespace, testName))
+		assert.NoError(t, err)
+		assert.Equal(t, writes, tc.statusWrites.Load())
+	}
+}
+
+// TestReconcileStatusConflict tests the status write is retried when the resource
+// is concurrently modified.
+func TestReconcileStatusConflict(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	request := &unikornv1fake.ManagedResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testName,
+		},
+	}
+
+	tc := mustNewTestContext(t, request)
+	tc.conflicts.Store(2)
+
+	ctx := context.Background()
+
+	p := mockprovisioners.NewMockManagerProvisioner(c)
+	p.EXPECT().Object().Return(&unikornv1fake.ManagedResource{})
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Provision(gomock.Any()).Return(nil)
+
+	reconciler := manager.NewReconciler(managerOptions(), nil, tc.newManager(c), func(_ manager.ControllerOptions) provisioners.ManagerProvisioner { return p })
+
+	_, err := reconciler.Reconcile(ctx, newRequest(testNamespace, testName))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), tc.statusWrites.Load())
+
+	var result unikornv1fake.ManagedResource
+
+	assert.NoError(t, tc.client.Get(ctx, newNamespacedName(testNamespace, testName), &result))
+	assert.Contains(t, result.Labels, "bump")
+	mustAssertStatus(t, &result, corev1.ConditionTrue, unikornv1.ConditionReasonProvisioned)
+}
+
+// benchmarkFleet reconciles a fleet of resources that have already reached
+// a steady state, reporting the number of status writes per reconcile.  Prior
+// to no-op suppression, every reconcile resulted in a write.
+func benchmarkFleet(b *testing.B, perr error) {
+	b.Helper()
+
+	c := gomock.NewController(b)
+	defer c.Finish()
+
+	const size = 100
+
+	objects := make([]client.Object, size)
+
+	for i := range objects {
+		objects[i] = &unikornv1fake.ManagedResource{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: testNamespace,
+				Name:      fmt.Sprintf("resource-%d", i),
+			},
+		}
+	}
+
+	tc := mustNewTestContext(b, objects...)
+	ctx := context.Background()
+
+	p := mockprovisioners.NewMockManagerProvisioner(c)
+	p.EXPECT().Object().DoAndReturn(func() unikornv1.ManagableResourceInterface { return &unikornv1fake.ManagedResource{} }).AnyTimes()
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Provision(gomock.Any()).Return(perr).AnyTimes()
+
+	reconciler := manager.NewReconciler(managerOptions(), nil, tc.newManager(c), func(_ manager.ControllerOptions) provisioners.ManagerProvisioner { return p })
+
+	reconcile := func(i int) {
+		if _, err := reconciler.Reconcile(ctx, newRequest(testNamespace, fmt.Sprintf("resource-%d", i%size))); err != nil {
+			b.Fatal(err)
+		}
+
+		// Don't let events block the recorder.
+		drainEvents(tc.recorder)
+	}
+
+	// Get everything into a steady state.
+	for i := range size {
+		reconcile(i)
+	}
+
+	tc.statusWrites.Store(0)
+
+	b.ResetTimer()
+
+	for i := range b.N {
+		reconcile(i)
+	}
+
+	b.ReportMetric(float64(tc.statusWrites.Load())/float64(b.N), "writes/op")
+}
+
+// BenchmarkReconcileProvisionedFleet measures status writes for a fleet of
+// provisioned resources being periodically resynced.
+func BenchmarkReconcileProvisionedFleet(b *testing.B) {
+	benchmarkFleet(b, nil)
+}
+
+// BenchmarkReconcileYieldingFleet measures status writes for a fleet of resources
+// waiting on something, and yielding on every reconcile.
+func BenchmarkReconcileYieldingFleet(b *testing.B) {
+	benchmarkFleet(b, provisioners.ErrYield)
+}
+
+// TestReconcilePaused tests a resource paused with a reason and expiry reports
+// the Paused condition and is reconciled again when the pause expires.
+func TestReconcilePaused(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	until := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	request := &unikornv1fake.ManagedResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testName,
+			Annotations: map[string]string{
+				constants.PausedAnnotation:      "Investigating outage",
+				constants.PausedUntilAnnotation: until.Format(time.RFC3339),
+			},
+		},
+	}
+
+	tc := mustNewTestContext(t, request)
+	ctx := context.Background()
+
+	p := mockprovisioners.NewMockManagerProvisioner(c)
+	p.EXPECT().Object().Return(&unikornv1fake.ManagedResource{})
+
+	reconciler := manager.NewReconciler(managerOptions(), nil, tc.newManager(c), func(_ manager.ControllerOptions) provisioners.ManagerProvisioner { return p })
+
+	result, err := reconciler.Reconcile(ctx, newRequest(testNamespace, testName))
+	assert.NoError(t, err)
+	assert.Greater(t, result.RequeueAfter, 59*time.Minute)
+	assert.LessOrEqual(t, result.RequeueAfter, time.Hour)
+
+	var resource unikornv1fake.ManagedResource
+
+	assert.NoError(t, tc.client.Get(ctx, newNamespacedName(testNamespace, testName), &resource))
+
+	condition, err := resource.StatusConditionRead(unikornv1.ConditionPaused)
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.ConditionTrue, condition.Status)
+	assert.Equal(t, "Investigating outage until "+until.Format(time.RFC3339), condition.Message)
+}
+
+// TestReconcilePauseExpired tests a resource whose pause has expired is reconciled.
+func TestReconcilePauseExpired(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	request := &unikornv1fake.ManagedResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testName,
+			Annotations: map[string]string{
+				constants.PausedAnnotation:      "Investigating outage",
+				constants.PausedUntilAnnotation: time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+			},
+		},
+	}
+
+	tc := mustNewTestContext(t, request)
+	ctx := context.Background()
+
+	p := mockprovisioners.NewMockManagerProvisioner(c)
+	p.EXPECT().Object().Return(&unikornv1fake.ManagedResource{})
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Provision(gomock.Any()).Return(nil)
+
+	reconciler := manager.NewReconciler(managerOptions(), nil, tc.newManager(c), func(_ manager.ControllerOptions) provisioners.ManagerProvisioner { return p })
+
+	_, err := reconciler.Reconcile(ctx, newRequest(testNamespace, testName))
+	assert.NoError(t, err)
+
+	var resource unikornv1fake.ManagedResource
+
+	assert.NoError(t, tc.client.Get(ctx, newNamespacedName(testNamespace, testName), &resource))
+	mustAssertStatus(t, &resource, corev1.ConditionTrue, unikornv1.ConditionReasonProvisioned)
+}
+
+// TestReconcileChangeFreeze tests all resources are paused during a change freeze.
+func TestReconcileChangeFreeze(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	request := &unikornv1fake.ManagedResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testName,
+		},
+	}
+
+	tc := mustNewTestContext(t, request)
+	ctx := context.Background()
+
+	options := managerOptions()
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	assert.NoError(t, options.ChangeFreezes.Set(now.Add(-time.Hour).Format(time.RFC3339)+"/"+now.Add(time.Hour).Format(time.RFC3339)))
+
+	p := mockprovisioners.NewMockManagerProvisioner(c)
+	p.EXPECT().Object().Return(&unikornv1fake.ManagedResource{})
+
+	reconciler := manager.NewReconciler(options, nil, tc.newManager(c), func(_ manager.ControllerOptions) provisioners.ManagerProvisioner { return p })
+
+	result, err := reconciler.Reconcile(ctx, newRequest(testNamespace, testName))
+	assert.NoError(t, err)
+	assert.NotZero(t, result.RequeueAfter)
+
+	var resource unikornv1fake.ManagedResource
+
+	assert.NoError(t, tc.client.Get(ctx, newNamespacedName(testNamespace, testName), &resource))
+
+	condition, err := resource.StatusConditionRead(unikornv1.ConditionPaused)
+	assert.NoError(t, err)
+	assert.Equal(t, unikornv1.ConditionReasonPaused, condition.Reason)
+}
+
+// TestReconcileChangeFreezeDelete tests resources being deleted are deprovisioned
+// during a change freeze.
+func TestReconcileChangeFreezeDelete(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	request := &unikornv1fake.ManagedResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testName,
+			Finalizers: []string{
+				constants.Finalizer,
+			},
+			DeletionTimestamp: &metav1.Time{
+				Time: time.Now(),
+			},
+		},
+	}
+
+	tc := mustNewTestContext(t, request)
+	ctx := context.Background()
+
+	options := managerOptions()
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	assert.NoError(t, options.ChangeFreezes.Set(now.Add(-time.Hour).Format(time.RFC3339)+"/"+now.Add(time.Hour).Format(time.RFC3339)))
+
+	p := mockprovisioners.NewMockManagerProvisioner(c)
+	p.EXPECT().Object().Return(&unikornv1fake.ManagedResource{})
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Deprovision(gomock.Any()).Return(nil)
+
+	reconciler := manager.NewReconciler(options, nil, tc.newManager(c), func(_ manager.ControllerOptions) provisioners.ManagerProvisioner { return p })
+
+	_, err := reconciler.Reconcile(ctx, newRequest(testNamespace, testName))
+	assert.NoError(t, err)
+
+	var apiError kerrors.APIStatus
+
+	assert.ErrorAs(t, tc.client.Get(ctx, newNamespacedName(testNamespace, testName), &unikornv1fake.ManagedResource{}), &apiError)
+	assert.Equal(t, metav1.StatusReasonNotFound, apiError.Status().Reason)
+}
+
+// TestReconcileMaintenanceWindow tests disruptive changes are disallowed outside
+// of a maintenance window, and the resource is reconciled again when it opens.
+func TestReconcileMaintenanceWindow(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	// Pick a window that opened an hour ago, so it's definitely closed.
+	opened := time.Now().UTC().Add(-time.Hour)
+
+	request := &unikornv1fake.ManagedResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testName,
+			Annotations: map[string]string{
+				constants.MaintenanceWindowAnnotation: fmt.Sprintf("%d %d * * *;30m", opened.Minute(), opened.Hour()),
+			},
+		},
+	}
+
+	tc := mustNewTestContext(t, request)
+	ctx := context.Background()
+
+	provision := func(ctx context.Context) error {
+		assert.False(t, maintenance.DisruptionAllowed(ctx))
+
+		return nil
+	}
+
+	p := mockprovisioners.NewMockManagerProvisioner(c)
+	p.EXPECT().Object().Return(&unikornv1fake.ManagedResource{})
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Provision(gomock.Any()).DoAndReturn(provision)
+
+	reconciler := manager.NewReconciler(managerOptions(), nil, tc.newManager(c), func(_ manager.ControllerOptions) provisioners.ManagerProvisioner { return p })
+
+	result, err := reconciler.Reconcile(ctx, newRequest(testNamespace, testName))
+	assert.NoError(t, err)
+	assert.Greater(t, result.RequeueAfter, 22*time.Hour)
+	assert.LessOrEqual(t, result.RequeueAfter, 23*time.Hour)
+}
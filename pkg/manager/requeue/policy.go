@@ -0,0 +1,156 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package requeue
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/unikorn-cloud/core/pkg/constants"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Policy decides how long to wait before reconciling a resource again.
+// Policies are shared across all reconciles so must be safe for concurrent use.
+type Policy interface {
+	// Yield is called when a reconcile yields.  The hint is the provisioner's
+	// suggested requeue period, zero if none was given.
+	Yield(key types.NamespacedName, hint time.Duration) time.Duration
+
+	// Error is called when a reconcile fails unexpectedly.
+	Error(key types.NamespacedName, err error) time.Duration
+
+	// Forget is called when a reconcile succeeds, or the resource is gone,
+	// and resets any state associated with it.
+	Forget(key types.NamespacedName)
+}
+
+// Backoff defines an exponential backoff.
+type Backoff struct {
+	// Period is the initial requeue period.
+	Period time.Duration
+
+	// MaxPeriod caps the requeue period.
+	MaxPeriod time.Duration
+}
+
+// period returns the requeue period for the given number of consecutive attempts.
+func (b Backoff) period(attempts int) time.Duration {
+	period := b.Period
+
+	if period <= 0 {
+		period = constants.DefaultYieldTimeout
+	}
+
+	maxPeriod := max(period, b.MaxPeriod)
+
+	// Avoid overflow, anything beyond this is going to be capped anyway.
+	exponent := min(attempts, 32)
+
+	return time.Duration(math.Min(float64(period)*math.Pow(2, float64(exponent)), float64(maxPeriod)))
+}
+
+// Exponential backs off exponentially while a resource continues to yield or
+// error, with separate backoffs for each.  Any change in outcome resets the
+// backoff.  Jitter is applied so that resources created together don't all
+// requeue together.
+type Exponential struct {
+	// yieldBackoff defines backoff for yields.
+	yieldBackoff Backoff
+
+	// errorBackoff defines backoff for errors.
+	errorBackoff Backoff
+
+	// jitter is the maximum fraction of the period to randomly add.
+	jitter float64
+
+	// lock provides synchronization around concurrency.
+	lock sync.Mutex
+
+	// yields counts consecutive yields by resource.
+	yields map[types.NamespacedName]int
+
+	// errors counts consecutive errors by resource.
+	errors map[types.NamespacedName]int
+}
+
+// Ensure the Policy interface is implemented.
+var _ Policy = &Exponential{}
+
+// NewExponential returns a new exponential backoff policy.
+func NewExponential(yieldBackoff, errorBackoff Backoff, jitter float64) *Exponential {
+	return &Exponential{
+		yieldBackoff: yieldBackoff,
+		errorBackoff: errorBackoff,
+		jitter:       jitter,
+		yields:       map[types.NamespacedName]int{},
+		errors:       map[types.NamespacedName]int{},
+	}
+}
+
+// withJitter randomly extends the period.
+func (p *Exponential) withJitter(period time.Duration) time.Duration {
+	if p.jitter <= 0 {
+		return period
+	}
+
+	//nolint:gosec
+	return period + time.Duration(rand.Float64()*p.jitter*float64(period))
+}
+
+// Yield implements the Policy interface.
+func (p *Exponential) Yield(key types.NamespacedName, hint time.Duration) time.Duration {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.errors, key)
+
+	// Provisioners know best, use the hint verbatim.
+	if hint > 0 {
+		return hint
+	}
+
+	attempts := p.yields[key]
+	p.yields[key] = attempts + 1
+
+	return p.withJitter(p.yieldBackoff.period(attempts))
+}
+
+// Error implements the Policy interface.
+func (p *Exponential) Error(key types.NamespacedName, _ error) time.Duration {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.yields, key)
+
+	attempts := p.errors[key]
+	p.errors[key] = attempts + 1
+
+	return p.withJitter(p.errorBackoff.period(attempts))
+}
+
+// Forget implements the Policy interface.
+func (p *Exponential) Forget(key types.NamespacedName) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.yields, key)
+	delete(p.errors, key)
+}
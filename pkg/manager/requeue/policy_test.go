@@ -0,0 +1,107 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package requeue_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/unikorn-cloud/core/pkg/constants"
+	"github.com/unikorn-cloud/core/pkg/manager/requeue"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var (
+	errUnhandled = errors.New("unhandled")
+
+	//nolint:gochecknoglobals
+	key = types.NamespacedName{Namespace: "foo", Name: "bar"}
+)
+
+// TestDefaults tests a zero policy behaves like the legacy constant yield.
+func TestDefaults(t *testing.T) {
+	t.Parallel()
+
+	p := requeue.NewExponential(requeue.Backoff{}, requeue.Backoff{}, 0)
+
+	assert.Equal(t, constants.DefaultYieldTimeout, p.Yield(key, 0))
+	assert.Equal(t, constants.DefaultYieldTimeout, p.Yield(key, 0))
+	assert.Equal(t, constants.DefaultYieldTimeout, p.Error(key, errUnhandled))
+	assert.Equal(t, constants.DefaultYieldTimeout, p.Error(key, errUnhandled))
+}
+
+// TestErrorBackoff tests errors back off exponentially up to a cap, and are reset
+// by other outcomes.
+func TestErrorBackoff(t *testing.T) {
+	t.Parallel()
+
+	errorBackoff := requeue.Backoff{
+		Period:    time.Second,
+		MaxPeriod: 5 * time.Second,
+	}
+
+	p := requeue.NewExponential(requeue.Backoff{}, errorBackoff, 0)
+
+	assert.Equal(t, time.Second, p.Error(key, errUnhandled))
+	assert.Equal(t, 2*time.Second, p.Error(key, errUnhandled))
+	assert.Equal(t, 4*time.Second, p.Error(key, errUnhandled))
+	assert.Equal(t, 5*time.Second, p.Error(key, errUnhandled))
+
+	for range 100 {
+		p.Error(key, errUnhandled)
+	}
+
+	assert.Equal(t, 5*time.Second, p.Error(key, errUnhandled))
+
+	// Other resources are unaffected.
+	assert.Equal(t, time.Second, p.Error(types.NamespacedName{Name: "baz"}, errUnhandled))
+
+	p.Forget(key)
+
+	assert.Equal(t, time.Second, p.Error(key, errUnhandled))
+
+	p.Yield(key, 0)
+
+	assert.Equal(t, time.Second, p.Error(key, errUnhandled))
+}
+
+// TestYieldHint tests a provisioner's hint is used verbatim.
+func TestYieldHint(t *testing.T) {
+	t.Parallel()
+
+	p := requeue.NewExponential(requeue.Backoff{}, requeue.Backoff{}, 0.5)
+
+	assert.Equal(t, time.Hour, p.Yield(key, time.Hour))
+}
+
+// TestJitter tests jitter stays within bounds.
+func TestJitter(t *testing.T) {
+	t.Parallel()
+
+	p := requeue.NewExponential(requeue.Backoff{Period: time.Minute}, requeue.Backoff{}, 0.5)
+
+	for range 100 {
+		period := p.Yield(key, 0)
+
+		assert.GreaterOrEqual(t, period, time.Minute)
+		assert.LessOrEqual(t, period, 90*time.Second)
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// WebhookRegisterer may be optionally implemented by a ControllerFactory to
+// serve admission webhooks, for example helmapplication.Register.  The webhook
+// server is only started if a controller implements this.
+type WebhookRegisterer interface {
+	// RegisterWebhooks adds any webhooks to the manager.
+	RegisterWebhooks(manager manager.Manager) error
+}
+
+// registerWebhooks registers webhooks for any controllers that provide them, and
+// adds a readiness check so traffic isn't routed until the server is listening.
+func registerWebhooks(manager manager.Manager, controllers []*controllerInstance) error {
+	registered := false
+
+	for _, c := range controllers {
+		registerer, ok := c.factory.(WebhookRegisterer)
+		if !ok {
+			continue
+		}
+
+		if err := registerer.RegisterWebhooks(manager); err != nil {
+			return fmt.Errorf("%s: %w", c.name, err)
+		}
+
+		registered = true
+	}
+
+	if !registered {
+		return nil
+	}
+
+	return manager.AddReadyzCheck("webhook", manager.GetWebhookServer().StartedChecker())
+}
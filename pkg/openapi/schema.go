@@ -18,35 +18,36 @@ import (
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xYbW/juBH+KwR7wLWo4t27awvUXw7pe9ArLsimV7Tr1BiJI5u71FDLFyfawP+9GFKy",
-	"ZUdOsk3QT/2SWOJwOG/PM0Pdy8o2rSWk4OX8Xjr0rSWP6QGqCtuA6qp/ye8U+srpNmhLci6v1ygcforo",
-	"g1iDFyUiiWGbAFLiVhsjShR1NLU2ht/6jqq1s2SjN91sQf+0UTTQidYaI0LS6G10FSYFjSUdrBM6eOED",
-	"hOhFbZ1gsw2yGTO5LWQJ6irbMTa2shSQQvKlbY2ugDe8+eDZ+HuJd8BK0k/nrJNzqWkDRqtl75Qs8sry",
-	"0O3B5dKqTvRb2ApfrbEB1veVw1rO5c/e7MP7Jq/6N/ms7XZbHEXzaqy2Bs3RyptEOiJZXwjr+ihlaWXR",
-	"C7JBsLegaUGwi+OnqB0qUWs0yqdAVZZqo6sXhmnQciI+sE/hrQ7rZIyHBgXxHzAOQXUC77QP/lXi1h82",
-	"mOXzsUA2rNEVIvoIxnQirLUXDQJ5NqkTa9jgoXEpRrV1pVYK6WVB2qk5EaXo0YnKoUIKGowXyqY87qza",
-	"5a91eqMNrtC/YpXdghcKSaMSZScghrV12vc1liMFHUO3guizEBt1ILigYD8iDWZrWh0a7ivbYsIrkDi/",
-	"vNgVb/KdK5e+3ju8IMIKvQfXjVwWltKW1tmNVuhEayDU1jUpV5oCOgLzDt0G3R/Z6ZdlzSdFy/w4nbge",
-	"msGK7H1lQDevkJlzEpHwrsWK2TOJCVtV0TlUhymBA8nggLxGCv0eILUglvSxqhAVR5AhGVw3Exd11qRT",
-	"6DmwFXgsRGsQPKeutS4IHQR4PkZ7HzMqyIY/2UjqZeElG5Y1qzkR2xGzodrTyI7kEmm8Qqz/TlAa5BzW",
-	"mpTYM1byNVJf5p/xhf5yM/R+mYF2ii5jWDMHZG09879GPU3pHRCYDesrmHs33rWM2Znc7k72I0+OW/+f",
-	"kdDpqi+5hmG7wiL1ZQiaY5s42LJz385kIVtnW3RB42Naz0VA57HX6oNjSsG7Fkjxr54K/nJ9fdmLVFbh",
-	"TCTcewEORQk+VzwL/sgh+Fb4Fitd93EoRBlDEs16UWVL2T6nMTD79IMGK8/jxvnlhRepm4iwBlZuPQ56",
-	"Mznms9hTpNjI+fuJYWJcV8vKMGZl8aBGIvnYMgyR9+bqW4auRVnsdCZmlcUxXQVsWuvAadMtI8EGtOE6",
-	"H23cnTq8WDmgcHRqejccOYbsqPE3GNZWLXkVjLG3D0xvUGkYlOyb4U0h07u5zOnlQp9AxXFl/ISu5Jj3",
-	"lSbyajm0nKSBg3+ke1vIoRtxSk7T+t4sW37AKnHMx1iiIwzof4ASzU9gIk7VbAqk+GssMQkLw9L8NmIh",
-	"QtfqKo0fqY1yNe1ojUcOnkUgiApIlLggTQrvUAmdK1hBAC7phCAI3OzkXP77/duz356f/QvOPt/8/Pv5",
-	"/ulsObu5f1v85pvtSOIX338lJ4Ju3QpIf06geMflpIZB6gpB/Q0D8OGJ64z5sZbz94/zkJvavS3uj5A/",
-	"PvZCTd8pxjJCpwGp1ugObwclGksrprCn83506MNk32wTQ/HDa8TimbF9GJ3ehlOB6ZdfJSb7o6bDMSgd",
-	"e39i8G56kR4JaeAzhllzbxoP/T7fCZ0O6B/2hEfxfz12c7TUD0Y2PSSYQVw1nIwUmXTjSPzeWJduCAHv",
-	"wmwKDyz6VK+dZIRtIQOs/FN7A6x+SMPLUR7SueOAX/Kk67UlTat3qROdLIadXGpZKGx9MMrse1Gkj2Rv",
-	"Kcd8t2v8mPhb4dFyJswpznYvAgibq6ur4xJ7CAiF+Zp/rZsTHyCCbvAQBflqYzDwOJN6TwNBzqWCgGcs",
-	"PpX+djLqzyG8iXxNYO1YZAJ0xRfiK0FqNk7GPxha/wfs/wiwHpsNTk6xHhvgyVts0Pn0keqgfW8WC/XL",
-	"xWI2+jfZok+g5Itb8iP4qhxCQPW7bjqD6aJ+u7ailzsA2mRWkuB/Adj+gOcDVp/okZH0pzhSfvGHSTsb",
-	"q9Ls/qTnsVXP83zQ+ITncOh3r/65fh/Vok5T+Tjkz+CV6/wdrKcA7Q+G0n4e/RB9f9suEgcoS1+H4cvO",
-	"goC6w/7DMmsEE9b97Snfs3gkrnUQtbONAF4iBen+s6CdBdnv2YLkxBAeYDWBMBLgSh0c39YCrPovi6Ty",
-	"1P2QqgauOAZqXyyDism8bqbnfk5oWuKuy8URYPX01JUMGXTeTPubWGfCWKN9SIfBKrGxDtg8h8WS2nwO",
-	"OAdd/ligqba8OehgeOn3tmls+kzHd5bM9z19ybl8O/tm9qtfp3tDiwStlnP53ezt7LvMbGu2Y7v9TwAA",
+	"H4sIAAAAAAAC/+xYbW/juBH+K4R6QFtU6927awvUXw7p+6JX7CKbXtGuU2MkjmzuUkMtOXLiDfzfiyEp",
+	"W3bkTa4J+um+JJY4HM7b88xQd0Xt2s4REodifld4DJ2jgPEB6ho7Rn2ZX8o7jaH2pmPjqJgXV2tUHj/1",
+	"GFitIagKkdSwTQFpdWOsVRWqpreNsVbehi3Va+/I9cFuZwv6l+tVC1vVOWsVR43B9b7GqKB1ZNh5ZTio",
+	"wMB9UI3zSsy2KGbMil1ZVKAvkx1jY2tHjMTRl66zpgbZ8PJDEOPvCrwFURJ/eu98MS8MbcAavcxOFWVa",
+	"WR67PbhcOb1VeYtYEeo1tiD6vvLYFPPiZy8P4X2ZVsPLdNZutytPonk5VtuAkWilTSoeEa0vlfM5Skla",
+	"OwyKHCvxFgwtCPZx/NQbj1o1Bq0OMVC1o8aa+olhGrSciQ8cUnhjeB2NCdCiIvkD1iPorcJbEzg8S9zy",
+	"YYNZIR0L5HiNvlR96MHareK1CapFoCAmbdUaNnhsXIxR43xltEZ6WpD2as5EqQ/oVe1RI7EBG5R2MY97",
+	"q/b567zZGIsrDM9YZTcQlEYyqFW1VdDz2nkTco2lSMFWoFtDH5KQGHUkuCB2H5EGsw2tjg0Ptesw4hVI",
+	"Xbx9vS/e6LtULv384PCCCGsMAfx25LJyFLd03m2MRq86C9w438ZcGWL0BPYd+g36P4nTT8taiIqW6XE6",
+	"cRma7FTyvrZg2mfIzAWpnvC2w5pRqyimXF333qM+TgkcSbIHCgaJ8x4gvSCRDH1dI2qJoECS/XamXjdJ",
+	"k4mhl8DWELBUnUUIqDx2zrMyrCDIMSaEPqGCHP/Z9aSfFl5yvGxEzZnYjpgN9YFG9iQXSeMZYv0Pgsqi",
+	"5LAxpNWBsaKvPeUy/4xP9FeaYQjLBLRzdNnzGomztsz8z1FPU3oHBCbDcgVL78bbTjA7K3b7k8PIk9PW",
+	"/xck9KbOJdcKbFdYxr4MbCS2kYOdOPfNrCiLzrsOPRv8ktYLxegDZq2BvVAK3nZAWn5lKvjr1dXbLFI7",
+	"jTMVcR8UeFQVBNSD4BsJwTcqdFibJsehVFXPUTTpRZ0sFfu8QRb2yYOGKE/jxsXb10HFbqJ4DaLcBRz0",
+	"JnJ8czF4itS3xfz9xDAxrqtlbQ2SvD2tkZ5C3wkMUfam6lvytsOi3OuMzFqUp3TF2HbOgzd2u+wJNmCs",
+	"1Plo4/7U4cXKA/HJqfHdcOQYsqPG3yKvnV7KKljrbu6Z3qI2MCg5NMPrsojv5kVKrxT6BCpOK+MH9JXE",
+	"PFeaSqvV0HKihllxT/euLIZuJCk5T+sHs1z1AevIMR/7Cj0hY/geKrQ/gO1xqmZjINXf+gqjsLIiLW97",
+	"LBVvO1PH8SO2UammPa3JyCGzCLCqgVSFCzKk8Ra1MqmCNTBISUcEATN6OfI/71+9+N3Fi3/Di8/Xv/hu",
+	"fnh6sZxd370qf/v1biTxy+++KiaC7vwKyHyOoHgn5aSHQeoSQf8dGeTwyHXWvmmK+fsv85Cf2r0r706Q",
+	"Pz72tZ6+U4xllIkDUmPQH98OKrSOVkGxezjvJ4feT/b1LjKUPDxHLB4Z2/vRyTacC0xefpaYHI6aDseg",
+	"dOz9mcG7zSIZCXHgs1ZY82CaR9Ah3Qm9YQz3e8IX8X81dnO0lAcjFx8izKBftZKMGJl444j83jofbwiM",
+	"tzybwoOIPtRrJxlhVxYMq/DQXobV93F4OclDPHcc8Lcy6QbjyNDqXexEZ4thLxdbFirXHI0yh17U00dy",
+	"N5Rivt81foz8rfFkeSBMjSsPOnF8Jz/z7ng3mOR0/yQAiTumvjwtwfuA0Zg+A1yZ9swHCjYtHqMkXX0s",
+	"sow7sTe1wMW80MD4QsSnyqObzMpjCHEinxNYPBWZAGX5I/EXITcbJ+Of3vBPgP5/ATpgu8HJKTdgC8Sm",
+	"Vhv0IX7EOmrvm8VC/2qxmI3+TbbwMyj50S37C/iqPQKj/v12OoPxIn+zdirLHQFtMitR8H8AbD7g8YA1",
+	"Z3poT+ZTP1L++o+TdrZOx9n+Qc/7Tj/O80HjA57Dsd9Z/WP9PqlFE6f2ccgfwStX6TtZpgATjobWPK9+",
+	"6EO+jZeRA7ST7wn56AUBbY/7k8isESyv8+0q3cMqJGwMq8a7VoEskYZ4P1rQ3oLk92xBxcSQzrCaQBgp",
+	"8JVhL7c5hlX+8kg6TeX3qWrgilOg5mIZVEzmdTN9L5CExiXpylIcDKuHp7JoyKDzetrfyDoTxloTOB4G",
+	"q8jGhrF9DIsVu/054D1s08cEQ42TzWzYytIfXNu6+BlP7jSJ7zN9FfPi1ezr2a9/I5pchwSdKebFt7NX",
+	"s28Ts63Fjt3uvwMAeQBRQXAYAAA=",
 	"AP//4ZGr91AYAAA=",
 }
 
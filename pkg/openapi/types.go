@@ -28,11 +28,14 @@ const (
 
 // Defines values for ResourceProvisioningStatus.
 const (
+	ResourceProvisioningStatusDegraded       ResourceProvisioningStatus = "degraded"
 	ResourceProvisioningStatusDeprovisioning ResourceProvisioningStatus = "deprovisioning"
 	ResourceProvisioningStatusError          ResourceProvisioningStatus = "error"
+	ResourceProvisioningStatusPaused         ResourceProvisioningStatus = "paused"
 	ResourceProvisioningStatusProvisioned    ResourceProvisioningStatus = "provisioned"
 	ResourceProvisioningStatusProvisioning   ResourceProvisioningStatus = "provisioning"
 	ResourceProvisioningStatusUnknown        ResourceProvisioningStatus = "unknown"
+	ResourceProvisioningStatusUpgrading      ResourceProvisioningStatus = "upgrading"
 )
 
 // Error Generic error message, compatible with oauth2.
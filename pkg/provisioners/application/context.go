@@ -19,8 +19,10 @@ package application
 
 import (
 	"context"
+	"fmt"
 
 	unikornv1 "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1"
+	"github.com/unikorn-cloud/core/pkg/errors"
 )
 
 type key int
@@ -36,3 +38,13 @@ func FromContext(ctx context.Context) unikornv1.ManagableResourceInterface {
 	//nolint:forcetypeassert
 	return ctx.Value(resourceKey).(unikornv1.ManagableResourceInterface)
 }
+
+// ResourceFromContext returns the resource being reconciled, or an error if not
+// set, for use where the context may not be populated by the manager.
+func ResourceFromContext(ctx context.Context) (unikornv1.ManagableResourceInterface, error) {
+	if value, ok := ctx.Value(resourceKey).(unikornv1.ManagableResourceInterface); ok {
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("%w: resource not set", errors.ErrInvalidContext)
+}
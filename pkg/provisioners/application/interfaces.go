@@ -35,15 +35,25 @@ type ReleaseNamer interface {
 }
 
 // Paramterizer is an interface that allows generators to supply a list of parameters
-// to Helm.  These are in addition to those defined by the application template.  At
-// present, there is nothing special about overriding, it just appends, so ensure the
-// explicit and implicit sets don't overlap.
+// to Helm.  These are in addition to those defined by the application template, and
+// are appended in name order.  Overlapping with the application template's parameters
+// is an error, use ParameterOverrider to explicitly replace them.
 type Paramterizer interface {
 	Parameters(ctx context.Context, version unikornv1.SemanticVersion) (map[string]string, error)
 }
 
+// ParameterOverrider is an interface that allows generators to explicitly replace
+// parameters defined by the application template.  Overrides retain the ordering of
+// the parameters they replace, and any that don't replace an existing parameter are
+// appended in name order.
+type ParameterOverrider interface {
+	ParameterOverrides(ctx context.Context, version unikornv1.SemanticVersion) (map[string]string, error)
+}
+
 // ValuesGenerator is an interface that allows generators to supply a raw values.yaml
-// file to Helm.  This accepts an object that can be marshaled to YAML.
+// file to Helm.  This accepts an object that can be marshaled to YAML.  If the application
+// template defines static values, then the generated values must marshal to an object
+// and are deep-merged on top of them.
 type ValuesGenerator interface {
 	Values(ctx context.Context, version unikornv1.SemanticVersion) (interface{}, error)
 }
@@ -54,6 +64,20 @@ type Customizer interface {
 	Customize(version unikornv1.SemanticVersion) ([]cd.HelmApplicationField, error)
 }
 
+// PreProvisionHook is an interface that lets an application provisioner run
+// a callback before the application is created or updated.
+type PreProvisionHook interface {
+	PreProvision(ctx context.Context) error
+}
+
+// UpgradeHook is an interface that lets an application provisioner run a callback
+// before the application is updated to a new version e.g. to take backups or migrate
+// CRDs.  The previous version is that reported by the CD driver, or failing that, the
+// version last recorded on the resource.  It is not called on initial installation.
+type UpgradeHook interface {
+	Upgrade(ctx context.Context, from, to unikornv1.SemanticVersion) error
+}
+
 // PostProvisionHook is an interface that lets an application provisioner run
 // a callback when provisioning has completed successfully.
 type PostProvisionHook interface {
@@ -66,3 +90,17 @@ type PostProvisionHook interface {
 type PreDeprovisionHook interface {
 	PreDeprovision(ctx context.Context) error
 }
+
+// PostDeprovisionHook is an interface that lets an application deprovisioner run
+// a callback after an application has been deleted e.g. to clean up persistent
+// volumes and namespaces left behind by the chart.
+type PostDeprovisionHook interface {
+	PostDeprovision(ctx context.Context) error
+}
+
+// YieldHook is an interface that lets an application provisioner run a callback
+// when provisioning or deprovisioning yields e.g. to collect diagnostics.  It cannot
+// affect the outcome of the provisioner.
+type YieldHook interface {
+	Yield(ctx context.Context, err error)
+}
@@ -19,16 +19,24 @@ package application
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"slices"
 
+	"github.com/Masterminds/semver/v3"
+
 	unikornv1 "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1"
 	"github.com/unikorn-cloud/core/pkg/cd"
 	clientlib "github.com/unikorn-cloud/core/pkg/client"
 	"github.com/unikorn-cloud/core/pkg/constants"
+	"github.com/unikorn-cloud/core/pkg/manager/maintenance"
 	"github.com/unikorn-cloud/core/pkg/provisioners"
 	"github.com/unikorn-cloud/core/pkg/provisioners/remotecluster"
 	"github.com/unikorn-cloud/core/pkg/util"
 
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -56,10 +64,24 @@ type Provisioner struct {
 	// applicationGetter is responsible for fetching an application.
 	applicationGetter GetterFunc
 
+	// application is the application definition.
+	application *unikornv1.HelmApplication
+
 	// applicationVersion is a reference to a versioned application.
 	applicationVersion *unikornv1.HelmApplicationVersion
+
+	// applicationNamespace is where the application is defined, and where
+	// any resources it references can be found.
+	applicationNamespace string
+
+	// namespaceProvisioner, if set, manages the application namespace in place
+	// of the CD driver when it is to be created.
+	namespaceProvisioner NamespaceProvisionerFunc
 }
 
+// NamespaceProvisionerFunc returns a provisioner that manages the named namespace.
+type NamespaceProvisionerFunc func(namespace string) provisioners.Provisioner
+
 // New returns a new initialized provisioner object.
 func New(applicationGetter GetterFunc) *Provisioner {
 	return &Provisioner{
@@ -92,6 +114,27 @@ func (p *Provisioner) AllowDegraded() *Provisioner {
 	return p
 }
 
+// WithNamespaceProvisioner manages the application namespace with a provisioner,
+// rather than having the CD driver create a bare namespace, when the application
+// requests namespace creation.  This allows policy, e.g. pod security and quotas,
+// to be applied to the namespace.  The namespace is provisioned before the
+// application, and deprovisioned after it.
+func (p *Provisioner) WithNamespaceProvisioner(f NamespaceProvisionerFunc) *Provisioner {
+	p.namespaceProvisioner = f
+
+	return p
+}
+
+// getNamespaceProvisioner returns a provisioner for the application namespace if
+// one is required.
+func (p *Provisioner) getNamespaceProvisioner() provisioners.Provisioner {
+	if p.namespaceProvisioner == nil || p.applicationVersion.CreateNamespace == nil || !*p.applicationVersion.CreateNamespace {
+		return nil
+	}
+
+	return p.namespaceProvisioner(p.getNamespace())
+}
+
 func (p *Provisioner) getResourceID(ctx context.Context) (*cd.ResourceIdentifier, error) {
 	id := &cd.ResourceIdentifier{
 		Name: p.Name,
@@ -142,64 +185,6 @@ func (p *Provisioner) getReleaseName(ctx context.Context) string {
 	return name
 }
 
-// getParameters constructs a full list of Helm parameters by taking those provided
-// in the application spec, and appending any that the generator yields.
-func (p *Provisioner) getParameters(ctx context.Context) ([]cd.HelmApplicationParameter, error) {
-	parameters := make([]cd.HelmApplicationParameter, 0, len(p.applicationVersion.Parameters))
-
-	for _, parameter := range p.applicationVersion.Parameters {
-		parameters = append(parameters, cd.HelmApplicationParameter{
-			Name:  parameter.Name,
-			Value: parameter.Value,
-		})
-	}
-
-	if p.generator != nil {
-		if parameterizer, ok := p.generator.(Paramterizer); ok {
-			p, err := parameterizer.Parameters(ctx, p.applicationVersion.Version)
-			if err != nil {
-				return nil, err
-			}
-
-			for name, value := range p {
-				parameters = append(parameters, cd.HelmApplicationParameter{
-					Name:  name,
-					Value: value,
-				})
-			}
-		}
-	}
-
-	// Makes gomock happy as "nil" != "[]foo{}".
-	if len(parameters) == 0 {
-		return nil, nil
-	}
-
-	return parameters, nil
-}
-
-// getValues delegates to the generator to get an option values.yaml file to
-// pass to Helm.
-func (p *Provisioner) getValues(ctx context.Context) (interface{}, error) {
-	if p.generator == nil {
-		//nolint:nilnil
-		return nil, nil
-	}
-
-	valuesGenerator, ok := p.generator.(ValuesGenerator)
-	if !ok {
-		//nolint:nilnil
-		return nil, nil
-	}
-
-	values, err := valuesGenerator.Values(ctx, p.applicationVersion.Version)
-	if err != nil {
-		return nil, err
-	}
-
-	return values, nil
-}
-
 // getClusterID returns the destination cluster name.
 func (p *Provisioner) getClusterID(ctx context.Context) (*cd.ResourceIdentifier, error) {
 	clusterContext, err := clientlib.ClusterFromContext(ctx)
@@ -236,6 +221,11 @@ func (p *Provisioner) generateApplication(ctx context.Context) (*cd.HelmApplicat
 		return nil, err
 	}
 
+	// Fail fast with a precise error rather than leaving it to the CD driver.
+	if err := p.validate(ctx, parameters, values); err != nil {
+		return nil, err
+	}
+
 	clusterID, err := p.getClusterID(ctx)
 	if err != nil {
 		return nil, err
@@ -264,7 +254,9 @@ func (p *Provisioner) generateApplication(ctx context.Context) (*cd.HelmApplicat
 		cdApplication.Path = *p.applicationVersion.Path
 	}
 
-	if p.applicationVersion.CreateNamespace != nil {
+	// When namespace creation is managed by a provisioner, don't let the CD
+	// driver get involved.
+	if p.applicationVersion.CreateNamespace != nil && p.namespaceProvisioner == nil {
 		cdApplication.CreateNamespace = *p.applicationVersion.CreateNamespace
 	}
 
@@ -301,13 +293,174 @@ func (p *Provisioner) initialize(ctx context.Context) error {
 		return err
 	}
 
+	p.application = application
+	p.applicationVersion = applicationVersion
+	p.applicationNamespace = application.Namespace
+
+	return nil
+}
+
+// getPreviousVersion returns the version of the application that is currently
+// installed, or nil if it's not installed.  The CD driver is used where possible
+// falling back to the version recorded on the resource.
+func (p *Provisioner) getPreviousVersion(ctx context.Context, id *cd.ResourceIdentifier) (*unikornv1.SemanticVersion, error) {
+	applications, err := cd.FromContext(ctx).ListHelmApplications(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for applicationID, application := range applications {
+		if applicationID.Name != id.Name || application.Version == "" {
+			continue
+		}
+
+		// Git sourced applications may be referenced by branch or hash,
+		// so fall back to the annotation.
+		version, err := semver.NewVersion(application.Version)
+		if err != nil {
+			break
+		}
+
+		return &unikornv1.SemanticVersion{Version: *version}, nil
+	}
+
+	recorded, ok := FromContext(ctx).GetAnnotations()[constants.ApplicationVersionAnnotationPrefix+p.Name]
+	if !ok {
+		//nolint:nilnil
+		return nil, nil
+	}
+
+	version, err := semver.NewVersion(recorded)
+	if err != nil {
+		return nil, err
+	}
+
+	return &unikornv1.SemanticVersion{Version: *version}, nil
+}
+
+// recordVersion remembers the version that was successfully provisioned on the
+// resource.  A copy is patched so that any in-memory modifications made by other
+// provisioners are preserved, and the resource version is propagated back so
+// the subsequent status update doesn't conflict.
+func (p *Provisioner) recordVersion(ctx context.Context) error {
+	resource := FromContext(ctx)
+
+	key := constants.ApplicationVersionAnnotationPrefix + p.Name
+	value := p.applicationVersion.Version.Original()
+
+	if resource.GetAnnotations()[key] == value {
+		return nil
+	}
+
+	c, err := clientlib.ProvisionerClientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	//nolint:forcetypeassert
+	updated := resource.DeepCopyObject().(client.Object)
+
+	annotations := updated.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	annotations[key] = value
+
+	updated.SetAnnotations(annotations)
+
+	if err := c.Patch(ctx, updated, client.MergeFrom(resource)); err != nil {
+		return err
+	}
+
+	resource.SetAnnotations(updated.GetAnnotations())
+	resource.SetResourceVersion(updated.GetResourceVersion())
+
+	return nil
+}
+
+// deferUpgrade keeps the currently installed version of the application when
+// disruptive changes aren't allowed, for example outside of a maintenance window.
+// The upgrade is picked up once they are.  If the installed version is no longer
+// in the catalog this yields, leaving the installed application untouched.
+func (p *Provisioner) deferUpgrade(ctx context.Context, previous *unikornv1.SemanticVersion) error {
+	log := log.FromContext(ctx)
+
+	if maintenance.DisruptionAllowed(ctx) {
+		return nil
+	}
+
+	if previous == nil || previous.Equal(&p.applicationVersion.Version) {
+		return nil
+	}
+
+	// If the installed version has been removed from the catalog it cannot be
+	// regenerated, so leave it untouched until disruption is allowed, at which
+	// point the upgrade will happen.
+	applicationVersion, err := p.application.GetVersion(*previous)
+	if err != nil {
+		if !errors.Is(err, unikornv1.ErrVersionNotFound) {
+			return err
+		}
+
+		log.Info("installed application version unavailable, awaiting maintenance window", "application", p.Name, "from", previous.Original(), "to", p.applicationVersion.Version.Original())
+
+		provisioners.RecordEventf(ctx, corev1.EventTypeWarning, "UpgradeDeferred", "Installed version %s of %s is no longer available, upgrade to %s deferred until the next maintenance window", previous.Original(), p.Name, p.applicationVersion.Version.Original())
+
+		return fmt.Errorf("%w: installed version %s of %s is no longer available, upgrade to %s deferred until the next maintenance window", provisioners.ErrYield, previous.Original(), p.Name, p.applicationVersion.Version.Original())
+	}
+
+	log.Info("deferring application upgrade", "application", p.Name, "from", previous.Original(), "to", p.applicationVersion.Version.Original())
+
+	provisioners.RecordEventf(ctx, corev1.EventTypeNormal, "UpgradeDeferred", "Upgrade of %s to %s deferred until the next maintenance window", p.Name, p.applicationVersion.Version.Original())
+
 	p.applicationVersion = applicationVersion
 
 	return nil
 }
 
+// upgrade reports the application as upgrading, and runs any upgrade hook, if the
+// application version is changing.
+func (p *Provisioner) upgrade(ctx context.Context, previous *unikornv1.SemanticVersion) error {
+	log := log.FromContext(ctx)
+
+	if previous == nil || previous.Equal(&p.applicationVersion.Version) {
+		return nil
+	}
+
+	log.Info("upgrading application", "application", p.Name, "from", previous.Original(), "to", p.applicationVersion.Version.Original())
+
+	provisioners.ReportUpgrading(ctx, p.Name)
+
+	if hook, ok := p.generator.(UpgradeHook); ok {
+		return hook.Upgrade(ctx, *previous, p.applicationVersion.Version)
+	}
+
+	return nil
+}
+
+// yield runs any yield hook if the error indicates a yield.
+func (p *Provisioner) yield(ctx context.Context, err error) {
+	if p.generator == nil || !errors.Is(err, provisioners.ErrYield) {
+		return
+	}
+
+	if hook, ok := p.generator.(YieldHook); ok {
+		hook.Yield(ctx, err)
+	}
+}
+
 // Provision implements the Provision interface.
 func (p *Provisioner) Provision(ctx context.Context) error {
+	err := p.provision(ctx)
+
+	p.yield(ctx, err)
+
+	return err
+}
+
+//nolint:cyclop
+func (p *Provisioner) provision(ctx context.Context) error {
 	log := log.FromContext(ctx)
 
 	if err := p.initialize(ctx); err != nil {
@@ -322,17 +475,50 @@ func (p *Provisioner) Provision(ctx context.Context) error {
 		return err
 	}
 
+	previous, err := p.getPreviousVersion(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := p.deferUpgrade(ctx, previous); err != nil {
+		return err
+	}
+
 	application, err := p.generateApplication(ctx)
 	if err != nil {
 		return err
 	}
 
+	if p.generator != nil {
+		if hook, ok := p.generator.(PreProvisionHook); ok {
+			if err := hook.PreProvision(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := p.upgrade(ctx, previous); err != nil {
+		return err
+	}
+
+	if namespace := p.getNamespaceProvisioner(); namespace != nil {
+		if err := provisioners.Provision(ctx, namespace); err != nil {
+			return err
+		}
+	}
+
 	if err := cd.FromContext(ctx).CreateOrUpdateHelmApplication(ctx, id, application); err != nil {
 		return err
 	}
 
 	log.Info("application provisioned", "application", p.Name)
 
+	if _, ok := p.generator.(UpgradeHook); ok {
+		if err := p.recordVersion(ctx); err != nil {
+			return err
+		}
+	}
+
 	if p.generator != nil {
 		if hook, ok := p.generator.(PostProvisionHook); ok {
 			if err := hook.PostProvision(ctx); err != nil {
@@ -346,6 +532,14 @@ func (p *Provisioner) Provision(ctx context.Context) error {
 
 // Deprovision implements the Provision interface.
 func (p *Provisioner) Deprovision(ctx context.Context) error {
+	err := p.deprovision(ctx)
+
+	p.yield(ctx, err)
+
+	return err
+}
+
+func (p *Provisioner) deprovision(ctx context.Context) error {
 	log := log.FromContext(ctx)
 
 	if p.generator != nil {
@@ -371,5 +565,19 @@ func (p *Provisioner) Deprovision(ctx context.Context) error {
 		return err
 	}
 
+	if namespace := p.getNamespaceProvisioner(); namespace != nil {
+		if err := provisioners.Deprovision(ctx, namespace); err != nil {
+			return err
+		}
+	}
+
+	if p.generator != nil {
+		if hook, ok := p.generator.(PostDeprovisionHook); ok {
+			if err := hook.PostDeprovision(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
@@ -31,6 +31,7 @@ import (
 	"github.com/unikorn-cloud/core/pkg/cd/mock"
 	coreclient "github.com/unikorn-cloud/core/pkg/client"
 	"github.com/unikorn-cloud/core/pkg/constants"
+	"github.com/unikorn-cloud/core/pkg/manager/maintenance"
 	"github.com/unikorn-cloud/core/pkg/provisioners"
 	"github.com/unikorn-cloud/core/pkg/provisioners/application"
 
@@ -167,6 +168,7 @@ func TestApplicationCreateHelm(t *testing.T) {
 	ctx = cd.NewContext(ctx, driver)
 	ctx = application.NewContext(ctx, owner)
 
+	driver.EXPECT().ListHelmApplications(ctx, driverAppID).Return(nil, nil)
 	driver.EXPECT().CreateOrUpdateHelmApplication(ctx, driverAppID, driverApp).Return(provisioners.ErrYield)
 
 	provisioner := application.New(applicationGetter(app))
@@ -273,6 +275,7 @@ func TestApplicationCreateHelmExtended(t *testing.T) {
 	ctx = cd.NewContext(ctx, driver)
 	ctx = application.NewContext(ctx, owner)
 
+	driver.EXPECT().ListHelmApplications(ctx, driverAppID).Return(nil, nil)
 	driver.EXPECT().CreateOrUpdateHelmApplication(ctx, driverAppID, driverApp).Return(provisioners.ErrYield)
 
 	provisioner := application.New(applicationGetter(app)).AllowDegraded()
@@ -341,6 +344,7 @@ func TestApplicationCreateGit(t *testing.T) {
 	ctx = cd.NewContext(ctx, driver)
 	ctx = application.NewContext(ctx, owner)
 
+	driver.EXPECT().ListHelmApplications(ctx, driverAppID).Return(nil, nil)
 	driver.EXPECT().CreateOrUpdateHelmApplication(ctx, driverAppID, driverApp).Return(provisioners.ErrYield)
 
 	provisioner := application.New(applicationGetter(app))
@@ -484,6 +488,7 @@ func TestApplicationCreateMutate(t *testing.T) {
 	ctx = cd.NewContext(ctx, driver)
 	ctx = application.NewContext(ctx, owner)
 
+	driver.EXPECT().ListHelmApplications(ctx, driverAppID).Return(nil, nil)
 	driver.EXPECT().CreateOrUpdateHelmApplication(ctx, driverAppID, driverApp).Return(nil)
 
 	mutator := &mutator{}
@@ -544,3 +549,372 @@ func TestApplicationDeleteNotFound(t *testing.T) {
 
 	assert.ErrorIs(t, provisioner.Deprovision(ctx), provisioners.ErrYield)
 }
+
+// hooks records lifecycle hook invocations.
+type hooks struct {
+	calls []string
+	from  *unikornv1.SemanticVersion
+	to    *unikornv1.SemanticVersion
+}
+
+func (h *hooks) PreProvision(_ context.Context) error {
+	h.calls = append(h.calls, "PreProvision")
+
+	return nil
+}
+
+func (h *hooks) Upgrade(_ context.Context, from, to unikornv1.SemanticVersion) error {
+	h.calls = append(h.calls, "Upgrade")
+	h.from = &from
+	h.to = &to
+
+	return nil
+}
+
+func (h *hooks) PostProvision(_ context.Context) error {
+	h.calls = append(h.calls, "PostProvision")
+
+	return nil
+}
+
+func (h *hooks) PostDeprovision(_ context.Context) error {
+	h.calls = append(h.calls, "PostDeprovision")
+
+	return nil
+}
+
+func (h *hooks) Yield(_ context.Context, _ error) {
+	h.calls = append(h.calls, "Yield")
+}
+
+func newHookApplication() *unikornv1.HelmApplication {
+	return &unikornv1.HelmApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: baseNamespace,
+			Name:      applicationID,
+			Labels: map[string]string{
+				constants.NameLabel: applicationName,
+			},
+		},
+		Spec: unikornv1.HelmApplicationSpec{
+			Versions: []unikornv1.HelmApplicationVersion{
+				{
+					Repo:    ptr.To(repo),
+					Chart:   ptr.To(chart),
+					Version: version,
+				},
+			},
+		},
+	}
+}
+
+// TestApplicationUpgradeHooks tests hooks are called in order, the previous version
+// is derived from the driver, and the new version recorded.
+func TestApplicationUpgradeHooks(t *testing.T) {
+	t.Parallel()
+
+	tc := mustNewTestContext(t)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	driverAppID := &cd.ResourceIdentifier{
+		Name:   applicationName,
+		Labels: newManagedResourceLabels(),
+	}
+
+	driver := mock.NewMockDriver(c)
+
+	owner := newManagedResource()
+	assert.NoError(t, tc.client.Create(context.Background(), owner))
+
+	ctx := context.Background()
+	ctx = coreclient.NewContextWithProvisionerClient(ctx, tc.client)
+	ctx = coreclient.NewContextWithCluster(ctx, &coreclient.ClusterContext{Client: tc.client})
+	ctx = cd.NewContext(ctx, driver)
+	ctx = application.NewContext(ctx, owner)
+
+	installed := map[*cd.ResourceIdentifier]*cd.HelmApplication{
+		{Name: "other"}:         {Version: "0.0.1"},
+		{Name: applicationName}: {Version: "1.0.0"},
+	}
+
+	driver.EXPECT().ListHelmApplications(ctx, driverAppID).Return(installed, nil)
+	driver.EXPECT().CreateOrUpdateHelmApplication(ctx, driverAppID, gomock.Any()).Return(nil)
+
+	h := &hooks{}
+
+	assert.NoError(t, application.New(applicationGetter(newHookApplication())).WithGenerator(h).Provision(ctx))
+	assert.Equal(t, []string{"PreProvision", "Upgrade", "PostProvision"}, h.calls)
+	assert.Equal(t, "1.0.0", h.from.Original())
+	assert.Equal(t, version.Original(), h.to.Original())
+
+	var updated unikornv1fake.ManagedResource
+
+	assert.NoError(t, tc.client.Get(ctx, client.ObjectKeyFromObject(owner), &updated))
+	assert.Equal(t, version.Original(), updated.Annotations[constants.ApplicationVersionAnnotationPrefix+applicationName])
+	assert.Equal(t, updated.ResourceVersion, owner.ResourceVersion)
+}
+
+// TestApplicationUpgradeFromAnnotation tests the previous version is derived from
+// the resource when the driver cannot provide it, and upgrade hooks aren't called
+// when the version is unchanged.
+func TestApplicationUpgradeFromAnnotation(t *testing.T) {
+	t.Parallel()
+
+	tc := mustNewTestContext(t)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	driver := mock.NewMockDriver(c)
+
+	owner := newManagedResource()
+	owner.Annotations = map[string]string{
+		constants.ApplicationVersionAnnotationPrefix + applicationName: "1.1.0",
+	}
+
+	assert.NoError(t, tc.client.Create(context.Background(), owner))
+
+	ctx := context.Background()
+	ctx = coreclient.NewContextWithProvisionerClient(ctx, tc.client)
+	ctx = coreclient.NewContextWithCluster(ctx, &coreclient.ClusterContext{Client: tc.client})
+	ctx = cd.NewContext(ctx, driver)
+	ctx = application.NewContext(ctx, owner)
+
+	driver.EXPECT().ListHelmApplications(ctx, gomock.Any()).Return(nil, nil).Times(2)
+	driver.EXPECT().CreateOrUpdateHelmApplication(ctx, gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	h := &hooks{}
+
+	provisioner := application.New(applicationGetter(newHookApplication())).WithGenerator(h)
+
+	assert.NoError(t, provisioner.Provision(ctx))
+	assert.Equal(t, "1.1.0", h.from.Original())
+
+	h.calls = nil
+
+	assert.NoError(t, provisioner.Provision(ctx))
+	assert.Equal(t, []string{"PreProvision", "PostProvision"}, h.calls)
+}
+
+// TestApplicationUpgradeReported tests version changes are reported as upgrades
+// even when the generator has no upgrade hook, but fresh installs are not.
+func TestApplicationUpgradeReported(t *testing.T) {
+	t.Parallel()
+
+	tc := mustNewTestContext(t)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	driver := mock.NewMockDriver(c)
+
+	ctx := context.Background()
+	ctx = coreclient.NewContextWithProvisionerClient(ctx, tc.client)
+	ctx = coreclient.NewContextWithCluster(ctx, &coreclient.ClusterContext{Client: tc.client})
+	ctx = cd.NewContext(ctx, driver)
+	ctx = application.NewContext(ctx, newManagedResource())
+
+	installed := map[*cd.ResourceIdentifier]*cd.HelmApplication{
+		{Name: applicationName}: {Version: "1.0.0"},
+	}
+
+	gomock.InOrder(
+		driver.EXPECT().ListHelmApplications(gomock.Any(), gomock.Any()).Return(nil, nil),
+		driver.EXPECT().ListHelmApplications(gomock.Any(), gomock.Any()).Return(installed, nil),
+	)
+
+	driver.EXPECT().CreateOrUpdateHelmApplication(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	provisioner := application.New(applicationGetter(newHookApplication()))
+
+	installCtx := provisioners.NewContextWithReconcileStatus(ctx)
+
+	assert.NoError(t, provisioner.Provision(installCtx))
+	assert.Empty(t, provisioners.ReconcileStatusFromContext(installCtx).Upgrading())
+
+	upgradeCtx := provisioners.NewContextWithReconcileStatus(ctx)
+
+	assert.NoError(t, provisioner.Provision(upgradeCtx))
+	assert.Equal(t, []string{applicationName}, provisioners.ReconcileStatusFromContext(upgradeCtx).Upgrading())
+}
+
+// TestApplicationUpgradeDeferred tests the installed version is retained when
+// disruptive changes aren't allowed.
+func TestApplicationUpgradeDeferred(t *testing.T) {
+	t.Parallel()
+
+	tc := mustNewTestContext(t)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	driver := mock.NewMockDriver(c)
+
+	owner := newManagedResource()
+	assert.NoError(t, tc.client.Create(context.Background(), owner))
+
+	ctx := context.Background()
+	ctx = coreclient.NewContextWithProvisionerClient(ctx, tc.client)
+	ctx = coreclient.NewContextWithCluster(ctx, &coreclient.ClusterContext{Client: tc.client})
+	ctx = cd.NewContext(ctx, driver)
+	ctx = application.NewContext(ctx, owner)
+	ctx = maintenance.NewContext(ctx, false)
+
+	installed := map[*cd.ResourceIdentifier]*cd.HelmApplication{
+		{Name: applicationName}: {Version: "1.0.0"},
+	}
+
+	var provisioned *cd.HelmApplication
+
+	create := func(_ context.Context, _ *cd.ResourceIdentifier, app *cd.HelmApplication) error {
+		provisioned = app
+
+		return nil
+	}
+
+	driver.EXPECT().ListHelmApplications(ctx, gomock.Any()).Return(installed, nil)
+	driver.EXPECT().CreateOrUpdateHelmApplication(ctx, gomock.Any(), gomock.Any()).DoAndReturn(create)
+
+	app := newHookApplication()
+	app.Spec.Versions = append(app.Spec.Versions, unikornv1.HelmApplicationVersion{
+		Repo:    ptr.To(repo),
+		Chart:   ptr.To(chart),
+		Version: unikornv1.SemanticVersion{Version: *semver.MustParse("1.0.0")},
+	})
+
+	h := &hooks{}
+
+	assert.NoError(t, application.New(applicationGetter(app)).WithGenerator(h).Provision(ctx))
+	assert.Equal(t, []string{"PreProvision", "PostProvision"}, h.calls)
+	assert.Equal(t, "1.0.0", provisioned.Version)
+	assert.Equal(t, "1.0.0", owner.Annotations[constants.ApplicationVersionAnnotationPrefix+applicationName])
+}
+
+// TestApplicationUpgradeDeferredUnavailable tests the installed application is left
+// alone, and provisioning yields, when the installed version has been removed from
+// the catalog and disruptive changes aren't allowed.
+func TestApplicationUpgradeDeferredUnavailable(t *testing.T) {
+	t.Parallel()
+
+	tc := mustNewTestContext(t)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	driver := mock.NewMockDriver(c)
+
+	ctx := context.Background()
+	ctx = coreclient.NewContextWithProvisionerClient(ctx, tc.client)
+	ctx = coreclient.NewContextWithCluster(ctx, &coreclient.ClusterContext{Client: tc.client})
+	ctx = cd.NewContext(ctx, driver)
+	ctx = application.NewContext(ctx, newManagedResource())
+	ctx = maintenance.NewContext(ctx, false)
+
+	installed := map[*cd.ResourceIdentifier]*cd.HelmApplication{
+		{Name: applicationName}: {Version: "1.0.0"},
+	}
+
+	driver.EXPECT().ListHelmApplications(ctx, gomock.Any()).Return(installed, nil)
+
+	h := &hooks{}
+
+	err := application.New(applicationGetter(newHookApplication())).WithGenerator(h).Provision(ctx)
+	assert.ErrorIs(t, err, provisioners.ErrYield)
+	assert.Contains(t, err.Error(), "1.0.0")
+	assert.Equal(t, []string{"Yield"}, h.calls)
+}
+
+// TestApplicationDeprovisionHooks tests the yield hook is called while deletion is
+// in progress, and the post deprovision hook once complete.
+func TestApplicationDeprovisionHooks(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	driver := mock.NewMockDriver(c)
+
+	ctx := context.Background()
+	ctx = cd.NewContext(ctx, driver)
+	ctx = application.NewContext(ctx, newManagedResource())
+
+	gomock.InOrder(
+		driver.EXPECT().DeleteHelmApplication(ctx, gomock.Any(), false).Return(provisioners.ErrYield),
+		driver.EXPECT().DeleteHelmApplication(ctx, gomock.Any(), false).Return(nil),
+	)
+
+	h := &hooks{}
+
+	provisioner := application.New(applicationGetter(newHookApplication())).WithGenerator(h)
+
+	assert.ErrorIs(t, provisioner.Deprovision(ctx), provisioners.ErrYield)
+	assert.Equal(t, []string{"Yield"}, h.calls)
+
+	assert.NoError(t, provisioner.Deprovision(ctx))
+	assert.Equal(t, []string{"Yield", "PostDeprovision"}, h.calls)
+}
+
+// namespaceProvisioner records the namespace it was asked to manage.
+type namespaceProvisioner struct {
+	provisioners.Metadata
+
+	calls []string
+}
+
+func (p *namespaceProvisioner) Provision(_ context.Context) error {
+	p.calls = append(p.calls, "Provision "+p.Name)
+
+	return nil
+}
+
+func (p *namespaceProvisioner) Deprovision(_ context.Context) error {
+	p.calls = append(p.calls, "Deprovision "+p.Name)
+
+	return nil
+}
+
+// TestApplicationNamespaceProvisioner tests namespace creation is delegated to the
+// namespace provisioner rather than the CD driver.
+func TestApplicationNamespaceProvisioner(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	driver := mock.NewMockDriver(c)
+
+	ctx := context.Background()
+	ctx = coreclient.NewContextWithCluster(ctx, &coreclient.ClusterContext{})
+	ctx = cd.NewContext(ctx, driver)
+	ctx = application.NewContext(ctx, newManagedResource())
+
+	app := newHookApplication()
+	app.Spec.Versions[0].Namespace = ptr.To("tenant")
+	app.Spec.Versions[0].CreateNamespace = ptr.To(true)
+
+	namespace := &namespaceProvisioner{}
+
+	factory := func(name string) provisioners.Provisioner {
+		namespace.Name = name
+
+		return namespace
+	}
+
+	driver.EXPECT().ListHelmApplications(ctx, gomock.Any()).Return(nil, nil)
+	driver.EXPECT().CreateOrUpdateHelmApplication(ctx, gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ *cd.ResourceIdentifier, app *cd.HelmApplication) error {
+		assert.False(t, app.CreateNamespace)
+		assert.Equal(t, []string{"Provision tenant"}, namespace.calls)
+
+		return nil
+	})
+
+	driver.EXPECT().DeleteHelmApplication(ctx, gomock.Any(), false).Return(nil)
+
+	provisioner := application.New(applicationGetter(app)).WithNamespaceProvisioner(factory)
+
+	assert.NoError(t, provisioner.Provision(ctx))
+	assert.NoError(t, provisioner.Deprovision(ctx))
+	assert.Equal(t, []string{"Provision tenant", "Deprovision tenant"}, namespace.calls)
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"context"
+	"time"
+
+	unikornv1 "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1"
+	clientlib "github.com/unikorn-cloud/core/pkg/client"
+	"github.com/unikorn-cloud/core/pkg/constants"
+	"github.com/unikorn-cloud/core/pkg/provisioners"
+	"github.com/unikorn-cloud/core/pkg/provisioners/application"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// decision is the outcome of consulting a rollout.
+type decision int
+
+const (
+	// decisionFrom means the resource should remain on the old version.
+	decisionFrom decision = iota
+
+	// decisionTo means the resource should use the new version.
+	decisionTo
+
+	// decisionYield means the resource has just picked up the new version
+	// and should yield so its status reflects the upgrade.
+	decisionYield
+)
+
+// lookup finds a rollout for the application and version, if one exists.
+func lookup(ctx context.Context, c client.Client, app *unikornv1.HelmApplication, version *unikornv1.SemanticVersion) (*unikornv1.ApplicationRollout, error) {
+	var rollouts unikornv1.ApplicationRolloutList
+
+	if err := c.List(ctx, &rollouts, client.InNamespace(app.Namespace)); err != nil {
+		return nil, err
+	}
+
+	for i := range rollouts.Items {
+		rollout := &rollouts.Items[i]
+
+		if rollout.Spec.Application == app.Name && rollout.Spec.To.Equal(version) {
+			return rollout, nil
+		}
+	}
+
+	//nolint:nilnil
+	return nil, nil
+}
+
+// admitted returns true if the resource has been admitted to the rollout.  The
+// annotation is written by the Reconciler when it admits the resource, see
+// Reconciler.notify, and outlives the resource's entry in the rollout's targets,
+// which is removed once it is healthy.
+func admitted(rollout *unikornv1.ApplicationRollout, resource client.Object) bool {
+	return rollout.UID != "" && resource.GetAnnotations()[constants.RolloutAdmittedAnnotation] == string(rollout.UID)
+}
+
+// decide works out what version a resource should use, updating the rollout
+// status when the resource first picks up the new version.  Resources are only
+// known to the rollout once admitted, and healthy ones are forgotten, so the
+// resource's annotation is the source of truth for admission.
+func decide(rollout *unikornv1.ApplicationRollout, resource client.Object) (decision, bool) {
+	if !admitted(rollout, resource) {
+		return decisionFrom, false
+	}
+
+	target := findTarget(rollout, resource.GetUID())
+	if target == nil || target.State != unikornv1.ApplicationRolloutTargetStateAdmitted {
+		return decisionTo, false
+	}
+
+	if resource.GetDeletionTimestamp() != nil {
+		return decisionFrom, false
+	}
+
+	target.State = unikornv1.ApplicationRolloutTargetStateUpgrading
+	target.UpgradeTime = &metav1.Time{Time: time.Now()}
+
+	return decisionYield, true
+}
+
+// NewGetter wraps an application getter so that a version change is staged across
+// all resources as defined by any ApplicationRollout for that application and
+// version.  Where no rollout is defined, the getter's version is used as is.
+// Resources remain on the rollout's original version until admitted by the
+// Reconciler.  When a resource first picks up the new version it yields, so that
+// its Available condition reflects the outcome of the upgrade and can be used for
+// health gating.  This is the only time the rollout is written to, all other
+// reconciles of the resource only read it.
+func NewGetter(getter application.GetterFunc) application.GetterFunc {
+	return func(ctx context.Context) (*unikornv1.HelmApplication, *unikornv1.SemanticVersion, error) {
+		log := log.FromContext(ctx)
+
+		app, version, err := getter(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		c, err := clientlib.ProvisionerClientFromContext(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		rollout, err := lookup(ctx, c, app, version)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if rollout == nil || rollout.Status.Phase == unikornv1.ApplicationRolloutPhaseComplete {
+			return app, version, nil
+		}
+
+		resource := application.FromContext(ctx)
+
+		var result decision
+
+		update := func() error {
+			original := rollout.DeepCopy()
+
+			var changed bool
+
+			result, changed = decide(rollout, resource)
+			if !changed {
+				return nil
+			}
+
+			if err := c.Status().Patch(ctx, rollout, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil {
+				// Refresh for the next attempt.
+				if gerr := c.Get(ctx, client.ObjectKeyFromObject(rollout), rollout); gerr != nil {
+					return gerr
+				}
+
+				return err
+			}
+
+			return nil
+		}
+
+		if err := retry.RetryOnConflict(retry.DefaultRetry, update); err != nil {
+			return nil, nil, err
+		}
+
+		switch result {
+		case decisionFrom:
+			log.Info("application awaiting rollout", "application", app.Name, "rollout", rollout.Name, "version", rollout.Spec.From.Original())
+
+			return app, &rollout.Spec.From, nil
+		case decisionYield:
+			log.Info("application admitted to rollout", "application", app.Name, "rollout", rollout.Name, "version", rollout.Spec.To.Original())
+
+			return nil, nil, provisioners.ErrYield
+		case decisionTo:
+		}
+
+		return app, version, nil
+	}
+}
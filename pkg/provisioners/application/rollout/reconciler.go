@@ -0,0 +1,394 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"slices"
+
+	unikornv1 "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1"
+	"github.com/unikorn-cloud/core/pkg/constants"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// defaultCanaryPercent is used when the canary percentage is not set.
+	defaultCanaryPercent = 10
+
+	// defaultBatchPercent is used when the batch percentage is not set.
+	defaultBatchPercent = 25
+)
+
+// findTarget returns the target for a resource, or nil if it's not known.
+func findTarget(rollout *unikornv1.ApplicationRollout, uid types.UID) *unikornv1.ApplicationRolloutTarget {
+	for i := range rollout.Status.Targets {
+		if rollout.Status.Targets[i].UID == uid {
+			return &rollout.Status.Targets[i]
+		}
+	}
+
+	return nil
+}
+
+// hash gives a stable, but evenly distributed, admission order to resources.
+func hash(uid types.UID) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(uid))
+
+	return h.Sum32()
+}
+
+// Reconciler drives application rollouts.  It evaluates the health of resources
+// that have been upgraded, using their Available condition, and admits further
+// batches of resources when all those in flight are healthy.  Only the controller
+// that manages the resources referred to by a rollout should run this.
+type Reconciler struct {
+	client client.Client
+}
+
+// Ensure the reconcile.Reconciler interface is implemented.
+var _ reconcile.Reconciler = &Reconciler{}
+
+// NewReconciler returns a new rollout reconciler.
+func NewReconciler(client client.Client) *Reconciler {
+	return &Reconciler{
+		client: client,
+	}
+}
+
+// SetupWithManager registers the reconciler with a manager.
+func (r *Reconciler) SetupWithManager(m manager.Manager) error {
+	return builder.ControllerManagedBy(m).For(&unikornv1.ApplicationRollout{}).Named("applicationrollout").Complete(r)
+}
+
+// availableCondition returns the Available condition from a resource.
+func availableCondition(object *unstructured.Unstructured) (*unikornv1.Condition, error) {
+	conditions, _, err := unstructured.NestedSlice(object.Object, "status", "conditions")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range conditions {
+		data, err := json.Marshal(c)
+		if err != nil {
+			return nil, err
+		}
+
+		var condition unikornv1.Condition
+
+		if err := json.Unmarshal(data, &condition); err != nil {
+			return nil, err
+		}
+
+		if condition.Type == unikornv1.ConditionAvailable {
+			return &condition, nil
+		}
+	}
+
+	//nolint:nilnil
+	return nil, nil
+}
+
+// resources returns all live resources taking part in the rollout, keyed by UID.
+func (r *Reconciler) resources(ctx context.Context, rollout *unikornv1.ApplicationRollout) (map[types.UID]*unstructured.Unstructured, error) {
+	selector := labels.Everything()
+
+	if rollout.Spec.Resources.Selector != nil {
+		s, err := metav1.LabelSelectorAsSelector(rollout.Spec.Resources.Selector)
+		if err != nil {
+			return nil, err
+		}
+
+		selector = s
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.FromAPIVersionAndKind(rollout.Spec.Resources.APIVersion, rollout.Spec.Resources.Kind+"List"))
+
+	if err := r.client.List(ctx, list, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return nil, err
+	}
+
+	resources := make(map[types.UID]*unstructured.Unstructured, len(list.Items))
+
+	for i := range list.Items {
+		resource := &list.Items[i]
+
+		if resource.GetDeletionTimestamp() != nil {
+			continue
+		}
+
+		resources[resource.GetUID()] = resource
+	}
+
+	return resources, nil
+}
+
+// evaluate updates the health of upgraded resources.  Resources that have gone
+// are forgotten, as are healthy ones, so the targets list only ever contains those
+// in flight or failed.
+func evaluate(rollout *unikornv1.ApplicationRollout, resources map[types.UID]*unstructured.Unstructured) error {
+	targets := make([]unikornv1.ApplicationRolloutTarget, 0, len(rollout.Status.Targets))
+
+	for _, target := range rollout.Status.Targets {
+		object, ok := resources[target.UID]
+		if !ok {
+			continue
+		}
+
+		if target.State == unikornv1.ApplicationRolloutTargetStateUpgrading || target.State == unikornv1.ApplicationRolloutTargetStateFailed {
+			condition, err := availableCondition(object)
+			if err != nil {
+				return err
+			}
+
+			// Only consider conditions that have transitioned since the upgrade
+			// was picked up, otherwise they are stale.  Times are serialized with
+			// second granularity, so a transition in the same second counts.
+			if condition != nil && target.UpgradeTime != nil && !condition.LastTransitionTime.Before(target.UpgradeTime) {
+				if condition.Status == corev1.ConditionTrue && condition.Reason == unikornv1.ConditionReasonProvisioned {
+					continue
+				}
+
+				if condition.Reason == unikornv1.ConditionReasonErrored {
+					target.State = unikornv1.ApplicationRolloutTargetStateFailed
+				}
+			}
+		}
+
+		targets = append(targets, target)
+	}
+
+	rollout.Status.Targets = targets
+
+	return nil
+}
+
+// quota returns the number of resources that should be admitted for the given
+// percentage, at least one resource is admitted.
+func quota(percent, total int) int {
+	return max(1, min(total, (percent*total+99)/100))
+}
+
+// progress updates the rollout phase, and admits further resources when all
+// admitted ones are healthy.  The total is computed from all resources taking part
+// in the rollout, not just those that have been seen, so the rollout can only
+// complete once every one has upgraded.
+//
+//nolint:cyclop
+func progress(rollout *unikornv1.ApplicationRollout, resources map[types.UID]*unstructured.Unstructured) {
+	status := &rollout.Status
+
+	counts := map[unikornv1.ApplicationRolloutTargetState]int{}
+
+	for _, target := range status.Targets {
+		counts[target.State]++
+	}
+
+	// Resources may have been annotated but not recorded in the status if
+	// the status update failed, so consider both.
+	var pending []*unstructured.Unstructured
+
+	total := len(resources)
+	admittedCount := 0
+
+	for _, resource := range resources {
+		if admitted(rollout, resource) || findTarget(rollout, resource.GetUID()) != nil {
+			admittedCount++
+
+			continue
+		}
+
+		pending = append(pending, resource)
+	}
+
+	status.Total = total
+	status.Admitted = admittedCount
+
+	if total > 0 && len(pending) == 0 && len(status.Targets) == 0 {
+		status.Phase = unikornv1.ApplicationRolloutPhaseComplete
+		status.AdmittedPercent = 100
+
+		return
+	}
+
+	if counts[unikornv1.ApplicationRolloutTargetStateFailed] > 0 && ptr.Deref(rollout.Spec.PauseOnFailure, true) {
+		status.Phase = unikornv1.ApplicationRolloutPhaseFailed
+
+		return
+	}
+
+	if rollout.Spec.Paused {
+		status.Phase = unikornv1.ApplicationRolloutPhasePaused
+
+		return
+	}
+
+	status.Phase = unikornv1.ApplicationRolloutPhaseProgressing
+
+	// Wait for everything in flight to finish.
+	if counts[unikornv1.ApplicationRolloutTargetStateAdmitted] > 0 || counts[unikornv1.ApplicationRolloutTargetStateUpgrading] > 0 {
+		return
+	}
+
+	if len(pending) == 0 {
+		return
+	}
+
+	// For small totals a batch may round down to nothing, so keep going until
+	// something is admitted.
+	if status.AdmittedPercent == 0 {
+		status.AdmittedPercent = ptr.Deref(rollout.Spec.CanaryPercent, defaultCanaryPercent)
+	} else {
+		for status.AdmittedPercent < 100 && admittedCount >= quota(status.AdmittedPercent, total) {
+			status.AdmittedPercent = min(100, status.AdmittedPercent+ptr.Deref(rollout.Spec.BatchPercent, defaultBatchPercent))
+		}
+	}
+
+	slices.SortFunc(pending, func(a, b *unstructured.Unstructured) int {
+		if c := cmp.Compare(hash(a.GetUID()), hash(b.GetUID())); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.GetUID(), b.GetUID())
+	})
+
+	count := min(len(pending), max(0, quota(status.AdmittedPercent, total)-admittedCount))
+
+	for _, resource := range pending[:count] {
+		apiVersion, kind := resource.GroupVersionKind().ToAPIVersionAndKind()
+
+		status.Targets = append(status.Targets, unikornv1.ApplicationRolloutTarget{
+			APIVersion: apiVersion,
+			Kind:       kind,
+			Namespace:  resource.GetNamespace(),
+			Name:       resource.GetName(),
+			UID:        resource.GetUID(),
+			State:      unikornv1.ApplicationRolloutTargetStateAdmitted,
+		})
+	}
+
+	status.Admitted += count
+}
+
+// notify triggers a reconcile of an admitted resource so it picks up the new version.
+// The annotation also records the admission, so healthy resources can be forgotten.
+func (r *Reconciler) notify(ctx context.Context, rollout *unikornv1.ApplicationRollout, target *unikornv1.ApplicationRolloutTarget) error {
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(schema.FromAPIVersionAndKind(target.APIVersion, target.Kind))
+	object.SetNamespace(target.Namespace)
+	object.SetName(target.Name)
+
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				constants.RolloutAdmittedAnnotation: string(rollout.UID),
+			},
+		},
+	}
+
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+
+	if err := r.client.Patch(ctx, object, client.RawPatch(types.MergePatchType, data)); err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+// Reconcile implements the reconcile.Reconciler interface.
+func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	log := log.FromContext(ctx)
+
+	rollout := &unikornv1.ApplicationRollout{}
+
+	if err := r.client.Get(ctx, request.NamespacedName, rollout); err != nil {
+		if kerrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+
+		return reconcile.Result{}, err
+	}
+
+	if rollout.Status.Phase == unikornv1.ApplicationRolloutPhaseComplete {
+		return reconcile.Result{}, nil
+	}
+
+	resources, err := r.resources(ctx, rollout)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	updated := rollout.DeepCopy()
+
+	if err := evaluate(updated, resources); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	progress(updated, resources)
+
+	if !equality.Semantic.DeepEqual(rollout.Status, updated.Status) {
+		if err := r.client.Status().Update(ctx, updated); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		log.Info("rollout updated", "phase", updated.Status.Phase, "admittedPercent", updated.Status.AdmittedPercent)
+	}
+
+	// Admission is recorded before notifying, so this will retry any that
+	// failed on a previous reconcile.
+	for i := range updated.Status.Targets {
+		target := &updated.Status.Targets[i]
+
+		if target.State != unikornv1.ApplicationRolloutTargetStateAdmitted || admitted(updated, resources[target.UID]) {
+			continue
+		}
+
+		log.Info("resource admitted", "kind", target.Kind, "namespace", target.Namespace, "name", target.Name)
+
+		if err := r.notify(ctx, updated, target); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if updated.Status.Phase == unikornv1.ApplicationRolloutPhaseComplete {
+		return reconcile.Result{}, nil
+	}
+
+	return reconcile.Result{RequeueAfter: constants.DefaultYieldTimeout}, nil
+}
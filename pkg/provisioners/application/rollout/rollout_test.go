@@ -0,0 +1,377 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	unikornv1 "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1"
+	unikornv1fake "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1/fake"
+	coreclient "github.com/unikorn-cloud/core/pkg/client"
+	"github.com/unikorn-cloud/core/pkg/constants"
+	"github.com/unikorn-cloud/core/pkg/provisioners"
+	"github.com/unikorn-cloud/core/pkg/provisioners/application"
+	"github.com/unikorn-cloud/core/pkg/provisioners/application/rollout"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	namespace     = "base"
+	applicationID = "c785837a-7412-49a6-ac7e-6d75ab6ca577"
+	rolloutName   = "upgrade"
+)
+
+func mustNewClient(t *testing.T, objects ...client.Object) client.Client {
+	t.Helper()
+
+	scheme, err := coreclient.NewScheme()
+	require.NoError(t, err)
+
+	return fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&unikornv1.ApplicationRollout{}, &unikornv1fake.ManagedResource{}).WithObjects(objects...).Build()
+}
+
+func newVersion(v string) unikornv1.SemanticVersion {
+	return unikornv1.SemanticVersion{
+		Version: *semver.MustParse(v),
+	}
+}
+
+// getter always returns the latest version of the application.
+func getter(_ context.Context) (*unikornv1.HelmApplication, *unikornv1.SemanticVersion, error) {
+	app := &unikornv1.HelmApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      applicationID,
+		},
+	}
+
+	version := newVersion("2.0.0")
+
+	return app, &version, nil
+}
+
+func newRollout() *unikornv1.ApplicationRollout {
+	return &unikornv1.ApplicationRollout{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      rolloutName,
+			UID:       "6d2b7a4e-31b5-4a8e-9f0c-2b1e6f0c8d11",
+		},
+		Spec: unikornv1.ApplicationRolloutSpec{
+			Application: applicationID,
+			From:        newVersion("1.0.0"),
+			To:          newVersion("2.0.0"),
+			Resources: unikornv1.ApplicationRolloutResources{
+				APIVersion: unikornv1fake.Group,
+				Kind:       "ManagedResource",
+			},
+		},
+	}
+}
+
+func newResource(i int) *unikornv1fake.ManagedResource {
+	return &unikornv1fake.ManagedResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      fmt.Sprintf("resource-%d", i),
+			UID:       types.UID(fmt.Sprintf("uid-%d", i)),
+		},
+	}
+}
+
+func newContext(c client.Client, resource unikornv1.ManagableResourceInterface) context.Context {
+	ctx := coreclient.NewContextWithProvisionerClient(context.Background(), c)
+
+	return application.NewContext(ctx, resource)
+}
+
+func mustGetRollout(t *testing.T, c client.Client) *unikornv1.ApplicationRollout {
+	t.Helper()
+
+	var r unikornv1.ApplicationRollout
+
+	require.NoError(t, c.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: rolloutName}, &r))
+
+	return &r
+}
+
+func mustReconcile(t *testing.T, c client.Client) {
+	t.Helper()
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: namespace,
+			Name:      rolloutName,
+		},
+	}
+
+	_, err := rollout.NewReconciler(c).Reconcile(context.Background(), request)
+	require.NoError(t, err)
+}
+
+func countStates(r *unikornv1.ApplicationRollout) map[unikornv1.ApplicationRolloutTargetState]int {
+	counts := map[unikornv1.ApplicationRolloutTargetState]int{}
+
+	for _, target := range r.Status.Targets {
+		counts[target.State]++
+	}
+
+	return counts
+}
+
+// upgrade simulates admitted resources picking up the new version, and reporting
+// the provided condition reason.
+func upgrade(t *testing.T, c client.Client, reason unikornv1.ConditionReason) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	r := mustGetRollout(t, c)
+
+	for i := range r.Status.Targets {
+		target := &r.Status.Targets[i]
+
+		if target.State != unikornv1.ApplicationRolloutTargetStateAdmitted {
+			continue
+		}
+
+		target.State = unikornv1.ApplicationRolloutTargetStateUpgrading
+		target.UpgradeTime = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+
+		var resource unikornv1fake.ManagedResource
+
+		require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: target.Namespace, Name: target.Name}, &resource))
+
+		status := corev1.ConditionTrue
+		if reason != unikornv1.ConditionReasonProvisioned {
+			status = corev1.ConditionFalse
+		}
+
+		resource.Status.Conditions = []unikornv1.Condition{
+			{
+				Type:               unikornv1.ConditionAvailable,
+				Status:             status,
+				Reason:             reason,
+				LastTransitionTime: metav1.Now(),
+			},
+		}
+
+		require.NoError(t, c.Status().Update(ctx, &resource))
+	}
+
+	require.NoError(t, c.Status().Update(ctx, r))
+}
+
+// TestRolloutNone tests the version is unchanged when there is no rollout.
+func TestRolloutNone(t *testing.T) {
+	t.Parallel()
+
+	c := mustNewClient(t)
+
+	_, version, err := rollout.NewGetter(getter)(newContext(c, newResource(0)))
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", version.Original())
+}
+
+// TestRolloutLifecycle tests a resource is admitted, upgraded and health checked,
+// and that the rollout is only written to when the new version is picked up.
+func TestRolloutLifecycle(t *testing.T) {
+	t.Parallel()
+
+	resource := newResource(0)
+
+	c := mustNewClient(t, newRollout(), resource)
+	ctx := newContext(c, resource)
+
+	get := rollout.NewGetter(getter)
+
+	// Not yet admitted, and the rollout isn't modified.
+	resourceVersion := mustGetRollout(t, c).ResourceVersion
+
+	_, version, err := get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", version.Original())
+	assert.Equal(t, resourceVersion, mustGetRollout(t, c).ResourceVersion)
+
+	// Admitted, and notified.
+	mustReconcile(t, c)
+
+	r := mustGetRollout(t, c)
+	assert.Equal(t, unikornv1.ApplicationRolloutPhaseProgressing, r.Status.Phase)
+	assert.Equal(t, 1, r.Status.Total)
+	assert.Equal(t, 1, r.Status.Admitted)
+	assert.Equal(t, 1, countStates(r)[unikornv1.ApplicationRolloutTargetStateAdmitted])
+
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(resource), resource))
+	assert.Equal(t, string(r.UID), resource.Annotations[constants.RolloutAdmittedAnnotation])
+
+	// Picks up the new version.
+	_, _, err = get(ctx)
+	require.ErrorIs(t, err, provisioners.ErrYield)
+
+	resourceVersion = mustGetRollout(t, c).ResourceVersion
+
+	_, version, err = get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", version.Original())
+	assert.Equal(t, resourceVersion, mustGetRollout(t, c).ResourceVersion)
+
+	// Not yet healthy.
+	mustReconcile(t, c)
+	assert.Equal(t, 1, countStates(mustGetRollout(t, c))[unikornv1.ApplicationRolloutTargetStateUpgrading])
+
+	resource.Status.Conditions = []unikornv1.Condition{
+		{
+			Type:               unikornv1.ConditionAvailable,
+			Status:             corev1.ConditionTrue,
+			Reason:             unikornv1.ConditionReasonProvisioned,
+			LastTransitionTime: metav1.NewTime(time.Now().Add(time.Minute)),
+		},
+	}
+
+	require.NoError(t, c.Status().Update(ctx, resource))
+
+	// Healthy resources are forgotten, but still use the new version.
+	mustReconcile(t, c)
+
+	r = mustGetRollout(t, c)
+	assert.Equal(t, unikornv1.ApplicationRolloutPhaseComplete, r.Status.Phase)
+	assert.Empty(t, r.Status.Targets)
+
+	_, version, err = get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", version.Original())
+}
+
+// TestRolloutSameSecond tests a resource that becomes healthy in the same second
+// as it picked up the new version, which is indistinguishable once serialized, is
+// considered upgraded.
+func TestRolloutSameSecond(t *testing.T) {
+	t.Parallel()
+
+	resource := newResource(0)
+
+	c := mustNewClient(t, newRollout(), resource)
+	ctx := newContext(c, resource)
+
+	mustReconcile(t, c)
+
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(resource), resource))
+
+	_, _, err := rollout.NewGetter(getter)(ctx)
+	require.ErrorIs(t, err, provisioners.ErrYield)
+
+	r := mustGetRollout(t, c)
+	require.Len(t, r.Status.Targets, 1)
+	require.NotNil(t, r.Status.Targets[0].UpgradeTime)
+
+	resource.Status.Conditions = []unikornv1.Condition{
+		{
+			Type:               unikornv1.ConditionAvailable,
+			Status:             corev1.ConditionTrue,
+			Reason:             unikornv1.ConditionReasonProvisioned,
+			LastTransitionTime: *r.Status.Targets[0].UpgradeTime,
+		},
+	}
+
+	require.NoError(t, c.Status().Update(ctx, resource))
+
+	mustReconcile(t, c)
+
+	assert.Equal(t, unikornv1.ApplicationRolloutPhaseComplete, mustGetRollout(t, c).Status.Phase)
+}
+
+// TestRolloutBatches tests a canary is admitted first, followed by batches, and
+// that failures pause the rollout.
+func TestRolloutBatches(t *testing.T) {
+	t.Parallel()
+
+	objects := []client.Object{newRollout()}
+
+	for i := range 10 {
+		objects = append(objects, newResource(i))
+	}
+
+	c := mustNewClient(t, objects...)
+
+	// Canary.
+	mustReconcile(t, c)
+
+	r := mustGetRollout(t, c)
+	assert.Equal(t, 10, r.Status.Total)
+	assert.Equal(t, 10, r.Status.AdmittedPercent)
+	assert.Equal(t, 1, countStates(r)[unikornv1.ApplicationRolloutTargetStateAdmitted])
+
+	// Nothing happens while in flight.
+	mustReconcile(t, c)
+	assert.Equal(t, 1, countStates(mustGetRollout(t, c))[unikornv1.ApplicationRolloutTargetStateAdmitted])
+
+	// Next batch, the healthy canary is forgotten.
+	upgrade(t, c, unikornv1.ConditionReasonProvisioned)
+	mustReconcile(t, c)
+
+	r = mustGetRollout(t, c)
+	assert.Equal(t, 35, r.Status.AdmittedPercent)
+	assert.Equal(t, 4, r.Status.Admitted)
+	assert.Len(t, r.Status.Targets, 3)
+	assert.Equal(t, 3, countStates(r)[unikornv1.ApplicationRolloutTargetStateAdmitted])
+
+	// Failures pause the rollout.
+	upgrade(t, c, unikornv1.ConditionReasonErrored)
+	mustReconcile(t, c)
+
+	r = mustGetRollout(t, c)
+	assert.Equal(t, unikornv1.ApplicationRolloutPhaseFailed, r.Status.Phase)
+	assert.Equal(t, 3, countStates(r)[unikornv1.ApplicationRolloutTargetStateFailed])
+	assert.Equal(t, 4, r.Status.Admitted)
+}
+
+// TestRolloutUnseen tests the rollout does not complete while there are resources
+// that have not been upgraded, even if they have never been reconciled.
+func TestRolloutUnseen(t *testing.T) {
+	t.Parallel()
+
+	c := mustNewClient(t, newRollout(), newResource(0), newResource(1))
+
+	mustReconcile(t, c)
+	upgrade(t, c, unikornv1.ConditionReasonProvisioned)
+	mustReconcile(t, c)
+
+	r := mustGetRollout(t, c)
+	assert.Equal(t, unikornv1.ApplicationRolloutPhaseProgressing, r.Status.Phase)
+	assert.Equal(t, 2, r.Status.Total)
+	assert.Equal(t, 2, r.Status.Admitted)
+
+	upgrade(t, c, unikornv1.ConditionReasonProvisioned)
+	mustReconcile(t, c)
+
+	assert.Equal(t, unikornv1.ApplicationRolloutPhaseComplete, mustGetRollout(t, c).Status.Phase)
+}
@@ -0,0 +1,287 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/unikorn-cloud/core/pkg/cd"
+	clientlib "github.com/unikorn-cloud/core/pkg/client"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// ErrSchema is raised when the schema cannot be loaded.
+	ErrSchema = errors.New("schema error")
+
+	// ErrSchemaValidation is raised when values don't conform to the schema.
+	ErrSchemaValidation = errors.New("values failed schema validation")
+)
+
+// getSchema loads the application's JSON schema if one is defined.
+func (p *Provisioner) getSchema(ctx context.Context) (*jsonschema.Schema, error) {
+	schema := p.applicationVersion.Schema
+
+	if schema == nil {
+		//nolint:nilnil
+		return nil, nil
+	}
+
+	var data []byte
+
+	switch {
+	case schema.Inline != nil:
+		data = schema.Inline.Raw
+	case schema.ConfigMapRef != nil:
+		c, err := clientlib.ProvisionerClientFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		var configMap corev1.ConfigMap
+
+		if err := c.Get(ctx, client.ObjectKey{Namespace: p.applicationNamespace, Name: schema.ConfigMapRef.Name}, &configMap); err != nil {
+			return nil, err
+		}
+
+		key := schema.ConfigMapRef.Key
+		if key == "" {
+			key = "values.schema.json"
+		}
+
+		value, ok := configMap.Data[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: config map %s has no key %s", ErrSchema, schema.ConfigMapRef.Name, key)
+		}
+
+		data = []byte(value)
+	default:
+		return nil, fmt.Errorf("%w: no schema source defined", ErrSchema)
+	}
+
+	return parseSchema(data)
+}
+
+// schemaURL is the location schemas are registered at, any references are
+// relative to this.
+const schemaURL = "file:///values.schema.json"
+
+// schemaLoader prevents schemas from loading external resources, schemas are
+// expected to be self-contained as Helm's are.
+type schemaLoader struct{}
+
+func (schemaLoader) Load(url string) (any, error) {
+	return nil, fmt.Errorf("%w: only local references are supported, got %s", ErrSchema, url)
+}
+
+// parseSchema compiles a JSON schema, as used by Helm, for validation.  Schemas
+// without a $schema keyword are assumed to be draft-07, which is what Helm uses.
+func parseSchema(data []byte) (*jsonschema.Schema, error) {
+	document, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSchema, err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft7)
+	compiler.UseLoader(schemaLoader{})
+
+	if err := compiler.AddResource(schemaURL, document); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSchema, err)
+	}
+
+	schema, err := compiler.Compile(schemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSchema, err)
+	}
+
+	return schema, nil
+}
+
+// parameterValue types a parameter value as Helm does for --set.
+func parameterValue(value string) interface{} {
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+
+	// Leading zeros are retained as strings, as they are likely to be
+	// significant e.g. file modes or identifiers.
+	if value == "0" || !strings.HasPrefix(value, "0") {
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		}
+	}
+
+	return value
+}
+
+// parameterPath splits a parameter name into its path segments, honouring escaped
+// dots.  List indices are not supported and are treated as literal keys.
+func parameterPath(name string) []string {
+	var segments []string
+
+	var segment strings.Builder
+
+	for i := 0; i < len(name); i++ {
+		switch {
+		case name[i] == '\\' && i+1 < len(name) && name[i+1] == '.':
+			segment.WriteByte('.')
+
+			i++
+		case name[i] == '.':
+			segments = append(segments, segment.String())
+			segment.Reset()
+		default:
+			segment.WriteByte(name[i])
+		}
+	}
+
+	return append(segments, segment.String())
+}
+
+// applyParameters sets parameters in the values, as Helm would do.
+func applyParameters(values map[string]interface{}, parameters []cd.HelmApplicationParameter) {
+	for _, parameter := range parameters {
+		path := parameterPath(parameter.Name)
+
+		current := values
+
+		for _, segment := range path[:len(path)-1] {
+			next, ok := current[segment].(map[string]interface{})
+			if !ok {
+				next = map[string]interface{}{}
+
+				current[segment] = next
+			}
+
+			current = next
+		}
+
+		current[path[len(path)-1]] = parameterValue(parameter.Value)
+	}
+}
+
+// validate checks the final values, with parameters applied, against the application's
+// schema if one is defined.  Errors report JSON pointers to the offending values.
+func (p *Provisioner) validate(ctx context.Context, parameters []cd.HelmApplicationParameter, values interface{}) error {
+	schema, err := p.getSchema(ctx)
+	if err != nil {
+		return err
+	}
+
+	if schema == nil {
+		return nil
+	}
+
+	merged := map[string]interface{}{}
+
+	if values != nil {
+		m, err := toMap(values)
+		if err != nil {
+			return err
+		}
+
+		if m != nil {
+			merged = m
+		}
+	}
+
+	applyParameters(merged, parameters)
+
+	// Round trip through JSON so all types are canonical e.g. float64 for numbers.
+	var document interface{}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, &document); err != nil {
+		return err
+	}
+
+	if err := schema.Validate(document); err != nil {
+		return schemaError(err)
+	}
+
+	return nil
+}
+
+//nolint:gochecknoglobals
+var schemaErrorPrinter = message.NewPrinter(language.English)
+
+// schemaErrorMessages recursively gathers the messages from the leaves of the
+// validation error tree, the rest just describe which schema failed.
+func schemaErrorMessages(err *jsonschema.ValidationError, messages []string) []string {
+	if len(err.Causes) == 0 {
+		pointer := make([]string, len(err.InstanceLocation))
+
+		for i := range err.InstanceLocation {
+			pointer[i] = jsonPointerEscaper.Replace(err.InstanceLocation[i])
+		}
+
+		location := "/" + strings.Join(pointer, "/")
+
+		message := fmt.Sprintf("%s: %s", location, err.ErrorKind.LocalizedString(schemaErrorPrinter))
+
+		if slices.Contains(messages, message) {
+			return messages
+		}
+
+		return append(messages, message)
+	}
+
+	for _, cause := range err.Causes {
+		messages = schemaErrorMessages(cause, messages)
+	}
+
+	return messages
+}
+
+//nolint:gochecknoglobals
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// schemaError flattens validation errors into a single error that is suitable
+// for reporting in a status condition e.g. "/image/tag: got number, want string".
+func schemaError(err error) error {
+	var validationError *jsonschema.ValidationError
+
+	if !errors.As(err, &validationError) {
+		return fmt.Errorf("%w: %w", ErrSchemaValidation, err)
+	}
+
+	return fmt.Errorf("%w: %s", ErrSchemaValidation, strings.Join(schemaErrorMessages(validationError, nil), "; "))
+}
@@ -0,0 +1,300 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	unikornv1 "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1"
+	"github.com/unikorn-cloud/core/pkg/cd"
+	"github.com/unikorn-cloud/core/pkg/cd/mock"
+	coreclient "github.com/unikorn-cloud/core/pkg/client"
+	"github.com/unikorn-cloud/core/pkg/provisioners/application"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	schema = `{
+  "type": "object",
+  "properties": {
+    "image": {
+      "type": "object",
+      "properties": {
+        "tag": {
+          "type": "string"
+        }
+      },
+      "required": ["tag"]
+    },
+    "replicas": {
+      "type": "integer",
+      "minimum": 1
+    }
+  }
+}`
+)
+
+// TestSchemaValidationInline tests merged values and parameters are validated
+// against an inline schema.
+func TestSchemaValidationInline(t *testing.T) {
+	t.Parallel()
+
+	app := newHookApplication()
+	app.Spec.Versions[0].Schema = &unikornv1.HelmApplicationSchema{
+		Inline: &runtime.RawExtension{Raw: []byte(schema)},
+	}
+	app.Spec.Versions[0].Parameters = []unikornv1.HelmApplicationParameter{
+		{Name: "replicas", Value: "3"},
+	}
+
+	generator := &valuesGenerator{
+		values: map[string]interface{}{
+			"image": map[string]interface{}{
+				"tag": "1.0",
+			},
+		},
+	}
+
+	_, err := provisionAndCapture(t, app, generator)
+	require.NoError(t, err)
+
+	app.Spec.Versions[0].Parameters = []unikornv1.HelmApplicationParameter{
+		{Name: "replicas", Value: "0"},
+	}
+
+	generator.values = map[string]interface{}{
+		"image": map[string]interface{}{
+			"tag": 1,
+		},
+	}
+
+	_, err = provisionAndCapture(t, app, generator)
+	require.ErrorIs(t, err, application.ErrSchemaValidation)
+	assert.Contains(t, err.Error(), "/image/tag:")
+	assert.Contains(t, err.Error(), "/replicas:")
+}
+
+// TestSchemaValidationConfigMap tests schemas can be loaded from a config map
+// in the application's namespace.
+func TestSchemaValidationConfigMap(t *testing.T) {
+	t.Parallel()
+
+	tc := mustNewTestContext(t)
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: baseNamespace,
+			Name:      "schema",
+		},
+		Data: map[string]string{
+			"values.schema.json": schema,
+		},
+	}
+
+	require.NoError(t, tc.client.Create(context.Background(), configMap))
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	driver := mock.NewMockDriver(c)
+
+	ctx := context.Background()
+	ctx = coreclient.NewContextWithProvisionerClient(ctx, tc.client)
+	ctx = coreclient.NewContextWithCluster(ctx, &coreclient.ClusterContext{Client: tc.client})
+	ctx = cd.NewContext(ctx, driver)
+	ctx = application.NewContext(ctx, newManagedResource())
+
+	driver.EXPECT().ListHelmApplications(ctx, gomock.Any()).Return(nil, nil)
+
+	app := newHookApplication()
+	app.Spec.Versions[0].Schema = &unikornv1.HelmApplicationSchema{
+		ConfigMapRef: &unikornv1.HelmApplicationSchemaConfigMapReference{
+			Name: "schema",
+		},
+	}
+	app.Spec.Versions[0].Parameters = []unikornv1.HelmApplicationParameter{
+		{Name: "image.tag", Value: "true"},
+	}
+
+	err := application.New(applicationGetter(app)).Provision(ctx)
+	require.ErrorIs(t, err, application.ErrSchemaValidation)
+	assert.Contains(t, err.Error(), "/image/tag:")
+}
+
+// TestSchemaValidationReference tests local references are resolved.
+func TestSchemaValidationReference(t *testing.T) {
+	t.Parallel()
+
+	app := newHookApplication()
+	app.Spec.Versions[0].Schema = &unikornv1.HelmApplicationSchema{
+		Inline: &runtime.RawExtension{Raw: []byte(`{"$schema":"http://json-schema.org/draft-07/schema#","properties":{"a":{"$ref":"#/definitions/x"}},"definitions":{"x":{"type":"string"}}}`)},
+	}
+
+	generator := &valuesGenerator{
+		values: map[string]interface{}{
+			"a": "foo",
+		},
+	}
+
+	_, err := provisionAndCapture(t, app, generator)
+	require.NoError(t, err)
+
+	generator.values = map[string]interface{}{
+		"a": 1,
+	}
+
+	_, err = provisionAndCapture(t, app, generator)
+	require.ErrorIs(t, err, application.ErrSchemaValidation)
+	assert.Contains(t, err.Error(), "/a:")
+}
+
+// TestSchemaValidationUnsupported tests schemas that cannot be loaded are rejected,
+// rather than silently accepting everything.
+func TestSchemaValidationUnsupported(t *testing.T) {
+	t.Parallel()
+
+	schemas := []string{
+		`{"properties":{"a":{"$ref":"https://example.com/schema.json"}}}`,
+		`{"properties":{"a":{"$ref":"#/definitions/missing"}}}`,
+		`{"properties":{"a":{"type":"wibble"}}}`,
+		`not json`,
+	}
+
+	for _, schema := range schemas {
+		app := newHookApplication()
+		app.Spec.Versions[0].Schema = &unikornv1.HelmApplicationSchema{
+			Inline: &runtime.RawExtension{Raw: []byte(schema)},
+		}
+
+		_, err := provisionAndCapture(t, app, &valuesGenerator{})
+		require.ErrorIs(t, err, application.ErrSchema, schema)
+	}
+}
+
+// TestSchemaValidationChart tests a typical chart values.schema.json, using draft-07
+// features, e.g. conditionals, numeric exclusive bounds, constants and pattern
+// properties, is loaded and validated correctly.
+func TestSchemaValidationChart(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile("testdata/values.schema.json")
+	require.NoError(t, err)
+
+	app := newHookApplication()
+	app.Spec.Versions[0].Schema = &unikornv1.HelmApplicationSchema{
+		Inline: &runtime.RawExtension{Raw: data},
+	}
+	app.Spec.Versions[0].Parameters = []unikornv1.HelmApplicationParameter{
+		{Name: "replicaCount", Value: "2"},
+		{Name: "podAnnotations.prometheus\\.io/scrape", Value: "enabled"},
+	}
+
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "v1",
+			"image": map[string]interface{}{
+				"repository": "nginx",
+				"tag":        "1.27",
+				"pullPolicy": "IfNotPresent",
+			},
+			"service": map[string]interface{}{
+				"type":     "NodePort",
+				"port":     80,
+				"nodePort": 30080,
+			},
+			"resources": map[string]interface{}{
+				"limits": map[string]interface{}{
+					"cpu":    "500m",
+					"memory": "128Mi",
+				},
+			},
+		}
+	}
+
+	generator := &valuesGenerator{
+		values: valid(),
+	}
+
+	_, err = provisionAndCapture(t, app, generator)
+	require.NoError(t, err)
+
+	// Parameters are typed as Helm would, so this is a boolean, not a string.
+	app.Spec.Versions[0].Parameters[1].Value = "true"
+
+	_, err = provisionAndCapture(t, app, generator)
+	require.ErrorIs(t, err, application.ErrSchemaValidation)
+	assert.Contains(t, err.Error(), "/podAnnotations/prometheus.io~1scrape:")
+
+	app.Spec.Versions[0].Parameters[1].Value = "enabled"
+
+	invalid := []struct {
+		pointer string
+		mutate  func(map[string]interface{})
+	}{
+		{
+			pointer: "/apiVersion",
+			mutate: func(v map[string]interface{}) {
+				v["apiVersion"] = "v2"
+			},
+		},
+		{
+			pointer: "/service/port",
+			mutate: func(v map[string]interface{}) {
+				v["service"].(map[string]interface{})["port"] = 0
+			},
+		},
+		{
+			pointer: "/service",
+			mutate: func(v map[string]interface{}) {
+				delete(v["service"].(map[string]interface{}), "nodePort")
+			},
+		},
+		{
+			pointer: "/resources/limits",
+			mutate: func(v map[string]interface{}) {
+				v["resources"].(map[string]interface{})["limits"].(map[string]interface{})["gpu"] = 1
+			},
+		},
+		{
+			pointer: "/",
+			mutate: func(v map[string]interface{}) {
+				v["unknown"] = true
+			},
+		},
+	}
+
+	for _, test := range invalid {
+		values := valid()
+
+		test.mutate(values)
+
+		generator.values = values
+
+		_, err := provisionAndCapture(t, app, generator)
+		require.ErrorIs(t, err, application.ErrSchemaValidation, test.pointer)
+		assert.Contains(t, err.Error(), test.pointer+":", test.pointer)
+	}
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/unikorn-cloud/core/pkg/cd"
+	"github.com/unikorn-cloud/core/pkg/util"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var (
+	// ErrParameterConflict is raised when parameters are defined more than once
+	// without an explicit override.
+	ErrParameterConflict = errors.New("parameter conflict")
+
+	// ErrValues is raised when values cannot be merged.
+	ErrValues = errors.New("values error")
+)
+
+// getParameters constructs a full list of Helm parameters.  Those provided in the
+// application spec come first, in the order defined, with any explicit overrides
+// from the generator applied.  Any remaining generated parameters are appended in
+// name order so the result is deterministic.  For compatibility, generated parameters
+// implicitly take precedence over those defined by the application, but this is
+// logged as the generator should use ParameterOverrider to make it explicit.
+//
+//nolint:cyclop
+func (p *Provisioner) getParameters(ctx context.Context) ([]cd.HelmApplicationParameter, error) {
+	log := log.FromContext(ctx)
+
+	var generated, overrides map[string]string
+
+	if p.generator != nil {
+		if parameterizer, ok := p.generator.(Paramterizer); ok {
+			parameters, err := parameterizer.Parameters(ctx, p.applicationVersion.Version)
+			if err != nil {
+				return nil, err
+			}
+
+			generated = parameters
+		}
+
+		if overrider, ok := p.generator.(ParameterOverrider); ok {
+			parameters, err := overrider.ParameterOverrides(ctx, p.applicationVersion.Version)
+			if err != nil {
+				return nil, err
+			}
+
+			overrides = parameters
+		}
+	}
+
+	for name := range overrides {
+		if _, ok := generated[name]; ok {
+			return nil, fmt.Errorf("%w: parameter %s is both generated and overridden", ErrParameterConflict, name)
+		}
+	}
+
+	parameters := make([]cd.HelmApplicationParameter, 0, len(p.applicationVersion.Parameters)+len(generated)+len(overrides))
+
+	defined := map[string]bool{}
+
+	for _, parameter := range p.applicationVersion.Parameters {
+		if defined[parameter.Name] {
+			return nil, fmt.Errorf("%w: parameter %s is defined more than once by the application", ErrParameterConflict, parameter.Name)
+		}
+
+		defined[parameter.Name] = true
+
+		value := parameter.Value
+
+		if generatedValue, ok := generated[parameter.Name]; ok {
+			log.Info("parameter defined by application and generator, generator takes precedence, use an explicit override", "application", p.Name, "parameter", parameter.Name)
+
+			value = generatedValue
+		}
+
+		if override, ok := overrides[parameter.Name]; ok {
+			value = override
+		}
+
+		parameters = append(parameters, cd.HelmApplicationParameter{
+			Name:  parameter.Name,
+			Value: value,
+		})
+	}
+
+	additional := map[string]string{}
+
+	for name, value := range generated {
+		if !defined[name] {
+			additional[name] = value
+		}
+	}
+
+	for name, value := range overrides {
+		if !defined[name] {
+			additional[name] = value
+		}
+	}
+
+	names := util.Keys(additional)
+	slices.Sort(names)
+
+	for _, name := range names {
+		parameters = append(parameters, cd.HelmApplicationParameter{
+			Name:  name,
+			Value: additional[name],
+		})
+	}
+
+	// Makes gomock happy as "nil" != "[]foo{}".
+	if len(parameters) == 0 {
+		return nil, nil
+	}
+
+	return parameters, nil
+}
+
+// toMap converts an arbitrary object into a generic map for merging.
+func toMap(in interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+
+	var out map[string]interface{}
+
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("%w: values must be an object: %w", ErrValues, err)
+	}
+
+	return out, nil
+}
+
+// mergeValues deep-merges the overlay on top of the base.  Objects are merged
+// recursively, and all other types replace what is in the base.  As with Helm, a
+// null value in the overlay deletes the key from the base.
+func mergeValues(base, overlay map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(base)+len(overlay))
+
+	for key, value := range base {
+		out[key] = value
+	}
+
+	for key, value := range overlay {
+		if value == nil {
+			delete(out, key)
+
+			continue
+		}
+
+		baseObject, baseOK := out[key].(map[string]interface{})
+		overlayObject, overlayOK := value.(map[string]interface{})
+
+		if baseOK && overlayOK {
+			out[key] = mergeValues(baseObject, overlayObject)
+
+			continue
+		}
+
+		out[key] = value
+	}
+
+	return out
+}
+
+// getGeneratedValues delegates to the generator to get an optional values.yaml file.
+func (p *Provisioner) getGeneratedValues(ctx context.Context) (interface{}, error) {
+	if p.generator == nil {
+		//nolint:nilnil
+		return nil, nil
+	}
+
+	valuesGenerator, ok := p.generator.(ValuesGenerator)
+	if !ok {
+		//nolint:nilnil
+		return nil, nil
+	}
+
+	values, err := valuesGenerator.Values(ctx, p.applicationVersion.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// getValues returns the values to pass to Helm.  Static values defined by the
+// application have the lowest precedence, and are deep-merged with any generated
+// values.  Where there are no static values, generated values are passed through
+// verbatim.
+func (p *Provisioner) getValues(ctx context.Context) (interface{}, error) {
+	generated, err := p.getGeneratedValues(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	static := p.applicationVersion.Values
+
+	if static == nil || len(static.Raw) == 0 {
+		return generated, nil
+	}
+
+	var base map[string]interface{}
+
+	if err := json.Unmarshal(static.Raw, &base); err != nil {
+		return nil, fmt.Errorf("%w: static values must be an object: %w", ErrValues, err)
+	}
+
+	if generated == nil {
+		return base, nil
+	}
+
+	overlay, err := toMap(generated)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeValues(base, overlay), nil
+}
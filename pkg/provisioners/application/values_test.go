@@ -0,0 +1,221 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	unikornv1 "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1"
+	"github.com/unikorn-cloud/core/pkg/cd"
+	"github.com/unikorn-cloud/core/pkg/cd/mock"
+	coreclient "github.com/unikorn-cloud/core/pkg/client"
+	"github.com/unikorn-cloud/core/pkg/provisioners/application"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// valuesGenerator provides generated parameters and values.
+type valuesGenerator struct {
+	parameters map[string]string
+	overrides  map[string]string
+	values     interface{}
+}
+
+func (g *valuesGenerator) Parameters(_ context.Context, _ unikornv1.SemanticVersion) (map[string]string, error) {
+	return g.parameters, nil
+}
+
+func (g *valuesGenerator) ParameterOverrides(_ context.Context, _ unikornv1.SemanticVersion) (map[string]string, error) {
+	return g.overrides, nil
+}
+
+func (g *valuesGenerator) Values(_ context.Context, _ unikornv1.SemanticVersion) (interface{}, error) {
+	return g.values, nil
+}
+
+// provisionAndCapture runs the provisioner and returns what was passed to the driver.
+func provisionAndCapture(t *testing.T, app *unikornv1.HelmApplication, generator interface{}) (*cd.HelmApplication, error) {
+	t.Helper()
+
+	tc := mustNewTestContext(t)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	driver := mock.NewMockDriver(c)
+
+	ctx := context.Background()
+	ctx = coreclient.NewContextWithCluster(ctx, &coreclient.ClusterContext{Client: tc.client})
+	ctx = cd.NewContext(ctx, driver)
+	ctx = application.NewContext(ctx, newManagedResource())
+
+	var captured *cd.HelmApplication
+
+	driver.EXPECT().ListHelmApplications(ctx, gomock.Any()).Return(nil, nil)
+	driver.EXPECT().CreateOrUpdateHelmApplication(ctx, gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ *cd.ResourceIdentifier, app *cd.HelmApplication) error {
+		captured = app
+
+		return nil
+	}).MaxTimes(1)
+
+	if err := application.New(applicationGetter(app)).WithGenerator(generator).Provision(ctx); err != nil {
+		return nil, err
+	}
+
+	return captured, nil
+}
+
+// TestValuesMerge tests static values are deep-merged with generated ones, with
+// the generator taking precedence.
+func TestValuesMerge(t *testing.T) {
+	t.Parallel()
+
+	app := newHookApplication()
+	app.Spec.Versions[0].Values = &runtime.RawExtension{
+		Raw: []byte(`{"image":{"repository":"foo","tag":"1.0"},"replicas":1,"debug":true}`),
+	}
+
+	generator := &valuesGenerator{
+		values: map[string]interface{}{
+			"image": map[string]interface{}{
+				"tag": "2.0",
+			},
+			"replicas": 3,
+			"debug":    nil,
+		},
+	}
+
+	captured, err := provisionAndCapture(t, app, generator)
+	require.NoError(t, err)
+
+	expected := map[string]interface{}{
+		"image": map[string]interface{}{
+			"repository": "foo",
+			"tag":        "2.0",
+		},
+		"replicas": float64(3),
+	}
+
+	assert.Equal(t, expected, captured.Values)
+}
+
+// TestValuesStaticOnly tests static values are passed through without a generator.
+func TestValuesStaticOnly(t *testing.T) {
+	t.Parallel()
+
+	app := newHookApplication()
+	app.Spec.Versions[0].Values = &runtime.RawExtension{
+		Raw: []byte(`{"replicas":1}`),
+	}
+
+	captured, err := provisionAndCapture(t, app, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"replicas": float64(1)}, captured.Values)
+}
+
+// TestParametersOrdering tests parameters are ordered deterministically with
+// overrides applied in place.
+func TestParametersOrdering(t *testing.T) {
+	t.Parallel()
+
+	app := newHookApplication()
+	app.Spec.Versions[0].Parameters = []unikornv1.HelmApplicationParameter{
+		{Name: "z", Value: "static"},
+		{Name: "a", Value: "static"},
+	}
+
+	generator := &valuesGenerator{
+		parameters: map[string]string{
+			"d": "generated",
+			"b": "generated",
+			"c": "generated",
+		},
+		overrides: map[string]string{
+			"z": "override",
+			"e": "override",
+		},
+	}
+
+	captured, err := provisionAndCapture(t, app, generator)
+	require.NoError(t, err)
+
+	expected := []cd.HelmApplicationParameter{
+		{Name: "z", Value: "override"},
+		{Name: "a", Value: "static"},
+		{Name: "b", Value: "generated"},
+		{Name: "c", Value: "generated"},
+		{Name: "d", Value: "generated"},
+		{Name: "e", Value: "override"},
+	}
+
+	assert.Equal(t, expected, captured.Parameters)
+}
+
+// TestParametersImplicitOverride tests generated parameters take precedence over
+// those defined by the application, as they always have.
+func TestParametersImplicitOverride(t *testing.T) {
+	t.Parallel()
+
+	app := newHookApplication()
+	app.Spec.Versions[0].Parameters = []unikornv1.HelmApplicationParameter{
+		{Name: "a", Value: "static"},
+		{Name: "b", Value: "static"},
+	}
+
+	generator := &valuesGenerator{
+		parameters: map[string]string{
+			"a": "generated",
+		},
+	}
+
+	captured, err := provisionAndCapture(t, app, generator)
+	require.NoError(t, err)
+
+	expected := []cd.HelmApplicationParameter{
+		{Name: "a", Value: "generated"},
+		{Name: "b", Value: "static"},
+	}
+
+	assert.Equal(t, expected, captured.Parameters)
+}
+
+// TestParametersConflict tests ambiguous parameters are reported.
+func TestParametersConflict(t *testing.T) {
+	t.Parallel()
+
+	app := newHookApplication()
+	app.Spec.Versions[0].Parameters = []unikornv1.HelmApplicationParameter{
+		{Name: "a", Value: "static"},
+	}
+
+	generator := &valuesGenerator{
+		parameters: map[string]string{
+			"b": "generated",
+		},
+		overrides: map[string]string{
+			"b": "override",
+		},
+	}
+
+	_, err := provisionAndCapture(t, app, generator)
+	require.ErrorIs(t, err, application.ErrParameterConflict)
+}
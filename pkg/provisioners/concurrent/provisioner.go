@@ -62,7 +62,7 @@ func (p *Provisioner) Provision(ctx context.Context) error {
 			// As errgroup only saves the first error, we may lose some
 			// logging information, so do this here when waiting on child
 			// tasks.
-			if err := provisioner.Provision(ctx); err != nil {
+			if err := provisioners.Provision(ctx, provisioner); err != nil {
 				log.Info("concurrency group member exited with error", "error", err, "group", p.Name, "provisioner", provisioner.ProvisionerName())
 
 				return err
@@ -100,7 +100,7 @@ func (p *Provisioner) Deprovision(ctx context.Context) error {
 			// As errgroup only saves the first error, we may lose some
 			// logging information, so do this here when waiting on child
 			// tasks.
-			if err := provisioner.Deprovision(ctx); err != nil {
+			if err := provisioners.Deprovision(ctx, provisioner); err != nil {
 				log.Info("concurrency group member exited with error", "error", err, "group", p.Name, "provisioner", provisioner.ProvisionerName())
 
 				return err
@@ -58,10 +58,12 @@ func TestConcurrentProvision(t *testing.T) {
 	ctx := context.Background()
 
 	p1 := mock.NewMockProvisioner(c)
-	p1.EXPECT().Provision(ctx).Return(nil)
+	p1.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p1.EXPECT().Provision(gomock.Any()).Return(nil)
 
 	p2 := mock.NewMockProvisioner(c)
-	p2.EXPECT().Provision(ctx).Return(nil)
+	p2.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p2.EXPECT().Provision(gomock.Any()).Return(nil)
 
 	assert.NoError(t, concurrent.New("test", p1, p2).Provision(ctx))
 }
@@ -77,11 +79,12 @@ func TestConcurrentProvisionYieldFirst(t *testing.T) {
 	ctx := context.Background()
 
 	p1 := mock.NewMockProvisioner(c)
-	p1.EXPECT().Provision(ctx).Return(provisioners.ErrYield)
-	p1.EXPECT().ProvisionerName().Return("")
+	p1.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p1.EXPECT().Provision(gomock.Any()).Return(provisioners.ErrYield)
 
 	p2 := mock.NewMockProvisioner(c)
-	p2.EXPECT().Provision(ctx).Return(nil)
+	p2.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p2.EXPECT().Provision(gomock.Any()).Return(nil)
 
 	assert.ErrorIs(t, provisioners.ErrYield, concurrent.New("test", p1, p2).Provision(ctx))
 }
@@ -97,11 +100,12 @@ func TestConcurrentProvisionYieldSecond(t *testing.T) {
 	ctx := context.Background()
 
 	p1 := mock.NewMockProvisioner(c)
-	p1.EXPECT().Provision(ctx).Return(nil)
+	p1.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p1.EXPECT().Provision(gomock.Any()).Return(nil)
 
 	p2 := mock.NewMockProvisioner(c)
-	p2.EXPECT().Provision(ctx).Return(provisioners.ErrYield)
-	p2.EXPECT().ProvisionerName().Return("")
+	p2.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p2.EXPECT().Provision(gomock.Any()).Return(provisioners.ErrYield)
 
 	assert.ErrorIs(t, provisioners.ErrYield, concurrent.New("test", p1, p2).Provision(ctx))
 }
@@ -117,10 +121,12 @@ func TestConcurrentDeprovision(t *testing.T) {
 	ctx := context.Background()
 
 	p1 := mock.NewMockProvisioner(c)
-	p1.EXPECT().Deprovision(ctx).Return(nil)
+	p1.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p1.EXPECT().Deprovision(gomock.Any()).Return(nil)
 
 	p2 := mock.NewMockProvisioner(c)
-	p2.EXPECT().Deprovision(ctx).Return(nil)
+	p2.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p2.EXPECT().Deprovision(gomock.Any()).Return(nil)
 
 	assert.NoError(t, concurrent.New("test", p1, p2).Deprovision(ctx))
 }
@@ -136,11 +142,12 @@ func TestConcurrentDeprovisionYieldFirst(t *testing.T) {
 	ctx := context.Background()
 
 	p1 := mock.NewMockProvisioner(c)
-	p1.EXPECT().Deprovision(ctx).Return(provisioners.ErrYield)
-	p1.EXPECT().ProvisionerName().Return("")
+	p1.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p1.EXPECT().Deprovision(gomock.Any()).Return(provisioners.ErrYield)
 
 	p2 := mock.NewMockProvisioner(c)
-	p2.EXPECT().Deprovision(ctx).Return(nil)
+	p2.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p2.EXPECT().Deprovision(gomock.Any()).Return(nil)
 
 	assert.ErrorIs(t, provisioners.ErrYield, concurrent.New("test", p1, p2).Deprovision(ctx))
 }
@@ -156,11 +163,12 @@ func TestConcurrentDeprovisionYieldSecond(t *testing.T) {
 	ctx := context.Background()
 
 	p1 := mock.NewMockProvisioner(c)
-	p1.EXPECT().Deprovision(ctx).Return(nil)
+	p1.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p1.EXPECT().Deprovision(gomock.Any()).Return(nil)
 
 	p2 := mock.NewMockProvisioner(c)
-	p2.EXPECT().Deprovision(ctx).Return(provisioners.ErrYield)
-	p2.EXPECT().ProvisionerName().Return("")
+	p2.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p2.EXPECT().Deprovision(gomock.Any()).Return(provisioners.ErrYield)
 
 	assert.ErrorIs(t, provisioners.ErrYield, concurrent.New("test", p1, p2).Deprovision(ctx))
 }
@@ -19,23 +19,95 @@ package conditional
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/unikorn-cloud/core/pkg/provisioners"
 
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+var (
+	// ErrResult is raised when a condition returns an invalid result.
+	ErrResult = errors.New("invalid condition result")
+)
+
+// Result is the outcome of evaluating a condition.
+type Result int
+
+const (
+	// False means the child provisioner is not required, what happens next
+	// is determined by the provisioner's Policy.
+	False Result = iota
+
+	// True means the child provisioner is required and will be provisioned.
+	True
+
+	// Unknown means the condition cannot be evaluated at present, for
+	// example a remote lookup is unavailable.  The provisioner will yield
+	// without touching the child provisioner.
+	Unknown
+)
+
+// FromBool converts a boolean into a Result.
+func FromBool(b bool) Result {
+	if b {
+		return True
+	}
+
+	return False
+}
+
+// ContextCondition is a context aware condition that is able to report
+// errors and an unknown state.
+type ContextCondition func(ctx context.Context) (Result, error)
+
+// Policy defines what happens when a condition evaluates to False.
+type Policy int
+
+const (
+	// PolicyDeprovision deprovisions the child provisioner when the condition
+	// is false.  This is the default behaviour.
+	PolicyDeprovision Policy = iota
+
+	// PolicyKeepExisting leaves the child provisioner alone when the condition
+	// is false, anything already provisioned is left in place.  Deprovisioning
+	// will always deprovision the child, regardless of the condition, so
+	// nothing is leaked.
+	PolicyKeepExisting
+
+	// PolicySkip treats the child provisioner as if it doesn't exist when
+	// the condition is false.  Unlike PolicyKeepExisting, this also skips
+	// deprovisioning when the condition is false, so must only be used when
+	// the child is either known not to have been provisioned, or its resources
+	// will be garbage collected by some other means e.g. cascading deletion.
+	PolicySkip
+)
+
 type Provisioner struct {
 	provisioners.Metadata
 
 	// condition will execute the provisioner if true.
-	condition func() bool
+	condition ContextCondition
+
+	// policy defines what to do when the condition is false.
+	policy Policy
 
 	// provisioner is the provisioner to provision.
 	provisioner provisioners.Provisioner
 }
 
 func New(name string, condition func() bool, provisioner provisioners.Provisioner) *Provisioner {
+	contextCondition := func(_ context.Context) (Result, error) {
+		return FromBool(condition()), nil
+	}
+
+	return NewWithContext(name, contextCondition, provisioner)
+}
+
+// NewWithContext returns a conditional provisioner whose condition has access
+// to the context and may return an error, or an unknown result.
+func NewWithContext(name string, condition ContextCondition, provisioner provisioners.Provisioner) *Provisioner {
 	return &Provisioner{
 		Metadata: provisioners.Metadata{
 			Name: name,
@@ -45,6 +117,13 @@ func New(name string, condition func() bool, provisioner provisioners.Provisione
 	}
 }
 
+// WithPolicy defines what happens when the condition is false.
+func (p *Provisioner) WithPolicy(policy Policy) *Provisioner {
+	p.policy = policy
+
+	return p
+}
+
 // Ensure the Provisioner interface is implemented.
 var _ provisioners.Provisioner = &Provisioner{}
 
@@ -52,16 +131,51 @@ var _ provisioners.Provisioner = &Provisioner{}
 func (p *Provisioner) Provision(ctx context.Context) error {
 	log := log.FromContext(ctx)
 
-	if !p.condition() {
+	result, err := p.condition(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch result {
+	case True:
+		return provisioners.Provision(ctx, p.provisioner)
+	case Unknown:
+		log.Info("conditional unknown, yielding", "provisioner", p.Name)
+
+		return provisioners.ErrYield
+	case False:
+		if p.policy != PolicyDeprovision {
+			log.Info("conditional skipped", "provisioner", p.Name)
+
+			return nil
+		}
+
 		log.Info("conditional deprovision", "provisioner", p.Name)
 
-		return p.provisioner.Deprovision(ctx)
+		return provisioners.Deprovision(ctx, p.provisioner)
+	default:
+		return fmt.Errorf("%w: %d", ErrResult, result)
 	}
-
-	return p.provisioner.Provision(ctx)
 }
 
 // Deprovision implements the Provision interface.
 func (p *Provisioner) Deprovision(ctx context.Context) error {
-	return p.provisioner.Deprovision(ctx)
+	log := log.FromContext(ctx)
+
+	// Only a definitive false will skip deprovisioning, when in doubt clean up
+	// to prevent resource leaks.
+	if p.policy == PolicySkip {
+		result, err := p.condition(ctx)
+		if err != nil {
+			return err
+		}
+
+		if result == False {
+			log.Info("conditional deprovision skipped", "provisioner", p.Name)
+
+			return nil
+		}
+	}
+
+	return provisioners.Deprovision(ctx, p.provisioner)
 }
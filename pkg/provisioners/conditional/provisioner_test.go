@@ -19,6 +19,7 @@ package conditional_test
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"os"
 	"testing"
@@ -65,7 +66,8 @@ func TestConditionalProvision(t *testing.T) {
 	ctx := context.Background()
 
 	p := mock.NewMockProvisioner(c)
-	p.EXPECT().Provision(ctx).Return(nil)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Provision(gomock.Any()).Return(nil)
 
 	assert.NoError(t, conditional.New("test", predicateTrue, p).Provision(ctx))
 }
@@ -81,7 +83,8 @@ func TestConditionalProvisionFalse(t *testing.T) {
 	ctx := context.Background()
 
 	p := mock.NewMockProvisioner(c)
-	p.EXPECT().Deprovision(ctx).Return(nil)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Deprovision(gomock.Any()).Return(nil)
 
 	assert.NoError(t, conditional.New("test", predicateFalse, p).Provision(ctx))
 }
@@ -97,7 +100,8 @@ func TestConditionalProvisionError(t *testing.T) {
 	ctx := context.Background()
 
 	p := mock.NewMockProvisioner(c)
-	p.EXPECT().Provision(ctx).Return(provisioners.ErrYield)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Provision(gomock.Any()).Return(provisioners.ErrYield)
 
 	assert.ErrorIs(t, conditional.New("test", predicateTrue, p).Provision(ctx), provisioners.ErrYield)
 }
@@ -113,7 +117,8 @@ func TestConditionalProvisionFalseError(t *testing.T) {
 	ctx := context.Background()
 
 	p := mock.NewMockProvisioner(c)
-	p.EXPECT().Deprovision(ctx).Return(provisioners.ErrYield)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Deprovision(gomock.Any()).Return(provisioners.ErrYield)
 
 	assert.ErrorIs(t, conditional.New("test", predicateFalse, p).Provision(ctx), provisioners.ErrYield)
 }
@@ -128,7 +133,8 @@ func TestConditionalDeprovision(t *testing.T) {
 	ctx := context.Background()
 
 	p := mock.NewMockProvisioner(c)
-	p.EXPECT().Deprovision(ctx).Return(nil)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Deprovision(gomock.Any()).Return(nil)
 
 	assert.NoError(t, conditional.New("test", predicateTrue, p).Deprovision(ctx))
 }
@@ -146,7 +152,8 @@ func TestConditionalDeprovisionFalse(t *testing.T) {
 	ctx := context.Background()
 
 	p := mock.NewMockProvisioner(c)
-	p.EXPECT().Deprovision(ctx).Return(nil)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Deprovision(gomock.Any()).Return(nil)
 
 	assert.NoError(t, conditional.New("test", predicateFalse, p).Deprovision(ctx))
 }
@@ -161,7 +168,8 @@ func TestConditionalDeprovisionError(t *testing.T) {
 	ctx := context.Background()
 
 	p := mock.NewMockProvisioner(c)
-	p.EXPECT().Deprovision(ctx).Return(provisioners.ErrYield)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Deprovision(gomock.Any()).Return(provisioners.ErrYield)
 
 	assert.ErrorIs(t, conditional.New("test", predicateTrue, p).Deprovision(ctx), provisioners.ErrYield)
 }
@@ -177,7 +185,162 @@ func TestConditionalDeprovisionFalseError(t *testing.T) {
 	ctx := context.Background()
 
 	p := mock.NewMockProvisioner(c)
-	p.EXPECT().Deprovision(ctx).Return(provisioners.ErrYield)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Deprovision(gomock.Any()).Return(provisioners.ErrYield)
 
 	assert.ErrorIs(t, conditional.New("test", predicateFalse, p).Deprovision(ctx), provisioners.ErrYield)
 }
+
+var errCondition = errors.New("condition error")
+
+func contextPredicate(result conditional.Result, err error) conditional.ContextCondition {
+	return func(_ context.Context) (conditional.Result, error) {
+		return result, err
+	}
+}
+
+// TestConditionalContextProvision tests that things are provisioned if asked to be.
+func TestConditionalContextProvision(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	ctx := context.Background()
+
+	p := mock.NewMockProvisioner(c)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Provision(gomock.Any()).Return(nil)
+
+	assert.NoError(t, conditional.NewWithContext("test", contextPredicate(conditional.True, nil), p).Provision(ctx))
+}
+
+// TestConditionalContextProvisionUnknown tests that an unknown result yields and
+// doesn't touch the child.
+func TestConditionalContextProvisionUnknown(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	ctx := context.Background()
+
+	p := mock.NewMockProvisioner(c)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+
+	assert.ErrorIs(t, conditional.NewWithContext("test", contextPredicate(conditional.Unknown, nil), p).Provision(ctx), provisioners.ErrYield)
+}
+
+// TestConditionalContextProvisionError tests that condition errors are propagated
+// and the child isn't touched.
+func TestConditionalContextProvisionError(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	ctx := context.Background()
+
+	p := mock.NewMockProvisioner(c)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+
+	assert.ErrorIs(t, conditional.NewWithContext("test", contextPredicate(conditional.False, errCondition), p).Provision(ctx), errCondition)
+}
+
+// TestConditionalContextProvisionInvalid tests an invalid result is an error, and
+// the child is left alone.
+func TestConditionalContextProvisionInvalid(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	ctx := context.Background()
+
+	p := mock.NewMockProvisioner(c)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+
+	assert.ErrorIs(t, conditional.NewWithContext("test", contextPredicate(conditional.Result(42), nil), p).Provision(ctx), conditional.ErrResult)
+}
+
+// TestConditionalContextProvisionFalseKeepExisting tests the child isn't deprovisioned
+// when false and the policy is to keep it.
+func TestConditionalContextProvisionFalseKeepExisting(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	ctx := context.Background()
+
+	p := mock.NewMockProvisioner(c)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+
+	assert.NoError(t, conditional.NewWithContext("test", contextPredicate(conditional.False, nil), p).WithPolicy(conditional.PolicyKeepExisting).Provision(ctx))
+}
+
+// TestConditionalContextProvisionFalseSkip tests the child isn't deprovisioned
+// when false and the policy is to skip it.
+func TestConditionalContextProvisionFalseSkip(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	ctx := context.Background()
+
+	p := mock.NewMockProvisioner(c)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+
+	assert.NoError(t, conditional.NewWithContext("test", contextPredicate(conditional.False, nil), p).WithPolicy(conditional.PolicySkip).Provision(ctx))
+}
+
+// TestConditionalContextDeprovisionFalseKeepExisting tests that deprovisioning
+// still cascades with a keep existing policy so nothing is leaked.
+func TestConditionalContextDeprovisionFalseKeepExisting(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	ctx := context.Background()
+
+	p := mock.NewMockProvisioner(c)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Deprovision(gomock.Any()).Return(nil)
+
+	assert.NoError(t, conditional.NewWithContext("test", contextPredicate(conditional.False, nil), p).WithPolicy(conditional.PolicyKeepExisting).Deprovision(ctx))
+}
+
+// TestConditionalContextDeprovisionFalseSkip tests that deprovisioning is skipped
+// with a skip policy when the condition is false.
+func TestConditionalContextDeprovisionFalseSkip(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	ctx := context.Background()
+
+	p := mock.NewMockProvisioner(c)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+
+	assert.NoError(t, conditional.NewWithContext("test", contextPredicate(conditional.False, nil), p).WithPolicy(conditional.PolicySkip).Deprovision(ctx))
+}
+
+// TestConditionalContextDeprovisionUnknownSkip tests that deprovisioning still
+// happens with a skip policy when the condition is unknown.
+func TestConditionalContextDeprovisionUnknownSkip(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	ctx := context.Background()
+
+	p := mock.NewMockProvisioner(c)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Deprovision(gomock.Any()).Return(nil)
+
+	assert.NoError(t, conditional.NewWithContext("test", contextPredicate(conditional.Unknown, nil), p).WithPolicy(conditional.PolicySkip).Deprovision(ctx))
+}
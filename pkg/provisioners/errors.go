@@ -19,6 +19,8 @@ package provisioners
 
 import (
 	"errors"
+	"fmt"
+	"time"
 )
 
 var (
@@ -34,3 +36,43 @@ var (
 	// ErrNotFound is when a resource is not found.
 	ErrNotFound = errors.New("resource not found")
 )
+
+// YieldError is a yield that carries a hint as to when the resource should be
+// reconciled again, for example when a provisioner knows an operation will take
+// minutes to complete.  It matches ErrYield with errors.Is, so may be used
+// anywhere a yield is expected.
+type YieldError struct {
+	// RequeueAfter is the suggested time to wait before trying again.
+	RequeueAfter time.Duration
+}
+
+// Ensure the error interface is implemented.
+var _ error = &YieldError{}
+
+// YieldAfter returns a yield error with a requeue hint.
+func YieldAfter(d time.Duration) error {
+	return &YieldError{
+		RequeueAfter: d,
+	}
+}
+
+// Error implements the error interface.
+func (e *YieldError) Error() string {
+	return fmt.Sprintf("%s: requeue after %v", ErrYield.Error(), e.RequeueAfter)
+}
+
+// Is allows the error to match ErrYield.
+func (e *YieldError) Is(target error) bool {
+	return target == ErrYield
+}
+
+// RequeueAfterHint returns any requeue hint contained in the error chain.
+func RequeueAfterHint(err error) (time.Duration, bool) {
+	var yield *YieldError
+
+	if !errors.As(err, &yield) {
+		return 0, false
+	}
+
+	return yield.RequeueAfter, true
+}
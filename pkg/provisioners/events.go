@@ -0,0 +1,156 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioners
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// DefaultEventDeduplicationPeriod is how long an identical event is
+	// suppressed for by default.
+	DefaultEventDeduplicationPeriod = 10 * time.Minute
+)
+
+// eventKey uniquely identifies an event for deduplication.
+type eventKey struct {
+	object    string
+	eventtype string
+	reason    string
+	message   string
+}
+
+// EventRecorder wraps a Kubernetes event recorder and suppresses identical
+// events for the same object within the deduplication period.  Provisioners
+// will be called on every yield, so without this they'd flood the event stream.
+// This is long lived and shared between reconciles.
+type EventRecorder struct {
+	// recorder does the actual event creation.
+	recorder record.EventRecorder
+
+	// period is how long identical events are suppressed for.
+	period time.Duration
+
+	// lock provides synchronization around concurrency.
+	lock sync.Mutex
+
+	// seen records when an event was last emitted.
+	seen map[eventKey]time.Time
+}
+
+// NewEventRecorder creates a deduplicating event recorder, if the period is
+// zero a default is used.
+func NewEventRecorder(recorder record.EventRecorder, period time.Duration) *EventRecorder {
+	if period <= 0 {
+		period = DefaultEventDeduplicationPeriod
+	}
+
+	return &EventRecorder{
+		recorder: recorder,
+		period:   period,
+		seen:     map[eventKey]time.Time{},
+	}
+}
+
+// objectID returns a unique identifier for an object, preferring the UID as
+// names may be reused once an object is deleted.
+func objectID(object runtime.Object) string {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return fmt.Sprintf("%p", object)
+	}
+
+	if uid := accessor.GetUID(); uid != "" {
+		return string(uid)
+	}
+
+	return fmt.Sprintf("%T/%s/%s", object, accessor.GetNamespace(), accessor.GetName())
+}
+
+// Event records an event against the object, unless an identical one has been
+// recorded within the deduplication period.
+func (r *EventRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	key := eventKey{
+		object:    objectID(object),
+		eventtype: eventtype,
+		reason:    reason,
+		message:   message,
+	}
+
+	now := time.Now()
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if last, ok := r.seen[key]; ok && now.Sub(last) < r.period {
+		return
+	}
+
+	// Prune anything expired so deleted objects don't leak memory.
+	for k, last := range r.seen {
+		if now.Sub(last) >= r.period {
+			delete(r.seen, k)
+		}
+	}
+
+	r.seen[key] = now
+
+	r.recorder.Event(object, eventtype, reason, message)
+}
+
+// Eventf is like Event, but with a formatted message.
+func (r *EventRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...any) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// objectEventRecorder binds an event recorder to the object being reconciled.
+type objectEventRecorder struct {
+	recorder *EventRecorder
+	object   runtime.Object
+}
+
+type eventRecorderKeyType int
+
+//nolint:gochecknoglobals
+var eventRecorderKey eventRecorderKeyType
+
+// NewContextWithEventRecorder adds an event recorder to the context, events
+// recorded by provisioners are attached to the given object.
+func NewContextWithEventRecorder(ctx context.Context, recorder *EventRecorder, object runtime.Object) context.Context {
+	return context.WithValue(ctx, eventRecorderKey, &objectEventRecorder{recorder: recorder, object: object})
+}
+
+// RecordEvent records an event against the resource being reconciled.  Identical
+// events are deduplicated, so this is safe to call on every reconcile.  This is a
+// no-op if there is no event recorder in the context.
+func RecordEvent(ctx context.Context, eventtype, reason, message string) {
+	if r, ok := ctx.Value(eventRecorderKey).(*objectEventRecorder); ok {
+		r.recorder.Event(r.object, eventtype, reason, message)
+	}
+}
+
+// RecordEventf is like RecordEvent, but with a formatted message.
+func RecordEventf(ctx context.Context, eventtype, reason, messageFmt string, args ...any) {
+	RecordEvent(ctx, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioners_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/unikorn-cloud/core/pkg/provisioners"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func newEventObject(name string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+		},
+	}
+}
+
+// TestEventDeduplication tests identical events for the same object are only
+// recorded once.
+func TestEventDeduplication(t *testing.T) {
+	t.Parallel()
+
+	fake := record.NewFakeRecorder(16)
+	recorder := provisioners.NewEventRecorder(fake, time.Hour)
+
+	foo := newEventObject("foo")
+	bar := newEventObject("bar")
+
+	recorder.Event(foo, corev1.EventTypeNormal, "Waiting", "Waiting for cluster")
+	recorder.Event(foo, corev1.EventTypeNormal, "Waiting", "Waiting for cluster")
+	recorder.Eventf(foo, corev1.EventTypeNormal, "Waiting", "Waiting for %s", "nodes")
+	recorder.Event(bar, corev1.EventTypeNormal, "Waiting", "Waiting for cluster")
+
+	assert.Len(t, fake.Events, 3)
+}
+
+// TestEventDeduplicationExpiry tests identical events are recorded again once
+// the deduplication period has elapsed.
+func TestEventDeduplicationExpiry(t *testing.T) {
+	t.Parallel()
+
+	fake := record.NewFakeRecorder(16)
+	recorder := provisioners.NewEventRecorder(fake, time.Millisecond)
+
+	foo := newEventObject("foo")
+
+	recorder.Event(foo, corev1.EventTypeNormal, "Waiting", "Waiting for cluster")
+	time.Sleep(2 * time.Millisecond)
+	recorder.Event(foo, corev1.EventTypeNormal, "Waiting", "Waiting for cluster")
+
+	assert.Len(t, fake.Events, 2)
+}
+
+// TestEventContext tests events are recorded against the object in the context,
+// and are ignored if there is no recorder.
+func TestEventContext(t *testing.T) {
+	t.Parallel()
+
+	fake := record.NewFakeRecorder(16)
+	recorder := provisioners.NewEventRecorder(fake, 0)
+
+	provisioners.RecordEvent(context.Background(), corev1.EventTypeNormal, "Ignored", "Ignored")
+
+	ctx := provisioners.NewContextWithEventRecorder(context.Background(), recorder, newEventObject("foo"))
+
+	provisioners.RecordEventf(ctx, corev1.EventTypeWarning, "Failed", "Failed to %s", "connect")
+
+	assert.Equal(t, "Warning Failed Failed to connect", <-fake.Events)
+	assert.Empty(t, fake.Events)
+}
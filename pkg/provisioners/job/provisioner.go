@@ -0,0 +1,404 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	clientlib "github.com/unikorn-cloud/core/pkg/client"
+	"github.com/unikorn-cloud/core/pkg/constants"
+	coreerrors "github.com/unikorn-cloud/core/pkg/errors"
+	"github.com/unikorn-cloud/core/pkg/provisioners"
+	"github.com/unikorn-cloud/core/pkg/provisioners/application"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var (
+	// ErrJobFailed is raised when the job has failed.
+	ErrJobFailed = errors.New("job failed")
+)
+
+const (
+	// defaultLogLines is the number of log lines to report from each failed
+	// container.
+	defaultLogLines = 20
+)
+
+// Provisioner runs a job to completion.  The job name is derived from a hash of
+// its specification and owning resource, so changing the specification, or
+// recreating the resource, will run a new job, and once that has completed, any
+// previous generations are deleted.  The job is retained
+// while the provisioner is active in order to remember that it has completed.
+type Provisioner struct {
+	provisioners.Metadata
+
+	// namespace is where the job will be run.
+	namespace string
+
+	// spec is the job specification.
+	spec batchv1.JobSpec
+
+	// retention, if set, retains completed jobs on deprovision for the given
+	// time for debugging purposes.
+	retention time.Duration
+
+	// logLines is the number of log lines to report from failed containers.
+	logLines int64
+
+	// clientset, if set, is used to read pods and their logs.
+	clientset kubernetes.Interface
+}
+
+// Ensure the Provisioner interface is implemented.
+var _ provisioners.Provisioner = &Provisioner{}
+
+// New returns a new job provisioner.
+func New(name, namespace string, spec batchv1.JobSpec) *Provisioner {
+	return &Provisioner{
+		Metadata: provisioners.Metadata{
+			Name: name,
+		},
+		namespace: namespace,
+		spec:      spec,
+		logLines:  defaultLogLines,
+	}
+}
+
+// WithRetention retains completed jobs for the specified time after deprovisioning
+// rather than deleting them immediately, cleanup is left to Kubernetes.
+func (p *Provisioner) WithRetention(retention time.Duration) *Provisioner {
+	p.retention = retention
+
+	return p
+}
+
+// WithLogLines sets the number of log lines reported from each failed container.
+func (p *Provisioner) WithLogLines(lines int64) *Provisioner {
+	p.logLines = lines
+
+	return p
+}
+
+// WithClientset sets the clientset used to read pods and their logs when a job
+// fails.  By default this is created from the cluster's REST configuration, so
+// reads go directly to the API rather than populating a cache with every pod.
+func (p *Provisioner) WithClientset(clientset kubernetes.Interface) *Provisioner {
+	p.clientset = clientset
+
+	return p
+}
+
+// truncate shortens a name so that, with a suffix appended, it fits in a
+// Kubernetes name or label value.
+func truncate(name string, suffixLength int) string {
+	if limit := validation.DNS1123LabelMaxLength - suffixLength; len(name) > limit {
+		return name[:limit]
+	}
+
+	return name
+}
+
+// owner returns the UID of the resource being reconciled, if known.
+func owner(ctx context.Context) string {
+	resource, err := application.ResourceFromContext(ctx)
+	if err != nil {
+		return ""
+	}
+
+	return string(resource.GetUID())
+}
+
+// jobName returns a deterministic name for the job based on its specification
+// and owner.  Jobs label their pods with their name, so this is limited to 63
+// characters.
+func (p *Provisioner) jobName(owner string) (string, error) {
+	data, err := json.Marshal(p.spec)
+	if err != nil {
+		return "", err
+	}
+
+	hash := fmt.Sprintf("%x", sha256.Sum256(append(data, owner...)))[:8]
+
+	return truncate(p.Name, len(hash)+1) + "-" + hash, nil
+}
+
+// label returns a value that identifies all generations of the job.  This is
+// the provisioner name where possible, otherwise it is truncated, and a hash of
+// the full name appended to keep it unique.
+func (p *Provisioner) label() string {
+	if len(p.Name) <= validation.DNS1123LabelMaxLength {
+		return p.Name
+	}
+
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(p.Name)))[:8]
+
+	return truncate(p.Name, len(hash)+1) + "-" + hash
+}
+
+// selector matches all generations of the job for the owner.
+func (p *Provisioner) selector(owner string) client.MatchingLabels {
+	selector := client.MatchingLabels{
+		constants.JobLabel: p.label(),
+	}
+
+	if owner != "" {
+		selector[constants.JobOwnerLabel] = owner
+	}
+
+	return selector
+}
+
+// generate creates the job.
+func (p *Provisioner) generate(name, owner string) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: p.namespace,
+			Name:      name,
+			Labels:    p.selector(owner),
+		},
+		Spec: *p.spec.DeepCopy(),
+	}
+}
+
+// jobCondition returns true if the job has the condition.
+func jobCondition(job *batchv1.Job, t batchv1.JobConditionType) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == t && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// finished returns true if the job has run to completion, successful or not.
+func finished(job *batchv1.Job) bool {
+	return jobCondition(job, batchv1.JobComplete) || jobCondition(job, batchv1.JobFailed)
+}
+
+// getClientset returns a clientset for reading pods and their logs.
+func (p *Provisioner) getClientset(cluster *clientlib.ClusterContext) (kubernetes.Interface, error) {
+	if p.clientset != nil {
+		return p.clientset, nil
+	}
+
+	if cluster.RESTConfig == nil {
+		return nil, fmt.Errorf("%w: cluster has no REST configuration", coreerrors.ErrInvalidContext)
+	}
+
+	return kubernetes.NewForConfig(cluster.RESTConfig)
+}
+
+// podLogs returns the logs for a container, if they can be retrieved.
+func (p *Provisioner) podLogs(ctx context.Context, clientset kubernetes.Interface, pod *corev1.Pod, container string) string {
+	options := &corev1.PodLogOptions{
+		Container: container,
+		TailLines: ptr.To(p.logLines),
+	}
+
+	data, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, options).DoRaw(ctx)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(data))
+}
+
+// failureDetails describes why a job failed from its pods' container statuses
+// and logs.  Pods are read directly from the API, a cached client would start
+// watching every pod in the cluster just to report an error.
+func (p *Provisioner) failureDetails(ctx context.Context, cluster *clientlib.ClusterContext, job *batchv1.Job) string {
+	clientset, err := p.getClientset(cluster)
+	if err != nil {
+		return fmt.Sprintf("unable to read pods: %v", err)
+	}
+
+	selector := labels.SelectorFromSet(labels.Set{batchv1.JobNameLabel: job.Name})
+
+	pods, err := clientset.CoreV1().Pods(job.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return fmt.Sprintf("unable to list pods: %v", err)
+	}
+
+	var details []string
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+
+		for _, status := range pod.Status.ContainerStatuses {
+			terminated := status.State.Terminated
+			if terminated == nil || terminated.ExitCode == 0 {
+				continue
+			}
+
+			detail := fmt.Sprintf("pod %s container %s exited with code %d (%s)", pod.Name, status.Name, terminated.ExitCode, terminated.Reason)
+
+			if terminated.Message != "" {
+				detail += ": " + terminated.Message
+			}
+
+			if logs := p.podLogs(ctx, clientset, pod, status.Name); logs != "" {
+				detail += "\n" + logs
+			}
+
+			details = append(details, detail)
+		}
+	}
+
+	if len(details) == 0 {
+		return "no failed containers found"
+	}
+
+	return strings.Join(details, "\n")
+}
+
+// cleanup removes any previous generations of the job.
+func (p *Provisioner) cleanup(ctx context.Context, cli client.Client, owner, current string) error {
+	var jobs batchv1.JobList
+
+	if err := cli.List(ctx, &jobs, client.InNamespace(p.namespace), p.selector(owner)); err != nil {
+		return err
+	}
+
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+
+		if job.Name == current {
+			continue
+		}
+
+		if err := cli.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !kerrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Provision implements the Provision interface.
+func (p *Provisioner) Provision(ctx context.Context) error {
+	log := log.FromContext(ctx)
+
+	cluster, err := clientlib.ClusterFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	owner := owner(ctx)
+
+	name, err := p.jobName(owner)
+	if err != nil {
+		return err
+	}
+
+	var job batchv1.Job
+
+	if err := cluster.Client.Get(ctx, client.ObjectKey{Namespace: p.namespace, Name: name}, &job); err != nil {
+		if !kerrors.IsNotFound(err) {
+			return err
+		}
+
+		log.Info("creating job", "job", name)
+
+		if err := cluster.Client.Create(ctx, p.generate(name, owner)); err != nil {
+			return err
+		}
+
+		return provisioners.ErrYield
+	}
+
+	if jobCondition(&job, batchv1.JobFailed) {
+		return fmt.Errorf("%w: %s: %s", ErrJobFailed, name, p.failureDetails(ctx, cluster, &job))
+	}
+
+	if !jobCondition(&job, batchv1.JobComplete) {
+		log.Info("awaiting job completion", "job", name)
+
+		return provisioners.ErrYield
+	}
+
+	return p.cleanup(ctx, cluster.Client, owner, name)
+}
+
+// Deprovision implements the Provision interface.
+func (p *Provisioner) Deprovision(ctx context.Context) error {
+	cluster, err := clientlib.ClusterFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	var jobs batchv1.JobList
+
+	if err := cluster.Client.List(ctx, &jobs, client.InNamespace(p.namespace), p.selector(owner(ctx))); err != nil {
+		return err
+	}
+
+	var deleting bool
+
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+
+		// Retained jobs are handed over to Kubernetes to clean up.
+		if p.retention > 0 && finished(job) {
+			ttl := int32(p.retention.Seconds())
+
+			if job.Spec.TTLSecondsAfterFinished == nil || *job.Spec.TTLSecondsAfterFinished != ttl {
+				updated := job.DeepCopy()
+				updated.Spec.TTLSecondsAfterFinished = &ttl
+
+				if err := cluster.Client.Patch(ctx, updated, client.MergeFrom(job)); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		deleting = true
+
+		if job.GetDeletionTimestamp() != nil {
+			continue
+		}
+
+		if err := cluster.Client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !kerrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	if deleting {
+		return provisioners.ErrYield
+	}
+
+	return nil
+}
@@ -0,0 +1,289 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	unikornv1fake "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1/fake"
+	coreclient "github.com/unikorn-cloud/core/pkg/client"
+	"github.com/unikorn-cloud/core/pkg/constants"
+	"github.com/unikorn-cloud/core/pkg/provisioners"
+	"github.com/unikorn-cloud/core/pkg/provisioners/application"
+	"github.com/unikorn-cloud/core/pkg/provisioners/job"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testNamespace = "foo"
+	testName      = "migrate"
+)
+
+func newSpec(image string) batchv1.JobSpec {
+	return batchv1.JobSpec{
+		Template: corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name:  "migrate",
+						Image: image,
+					},
+				},
+				RestartPolicy: corev1.RestartPolicyNever,
+			},
+		},
+	}
+}
+
+func newContext(c client.Client) context.Context {
+	return coreclient.NewContextWithCluster(context.Background(), &coreclient.ClusterContext{Client: c})
+}
+
+// newOwnedContext returns a context with a resource that owns the job.
+func newOwnedContext(c client.Client, uid types.UID) context.Context {
+	resource := &unikornv1fake.ManagedResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      "owner",
+			UID:       uid,
+		},
+	}
+
+	return application.NewContext(newContext(c), resource)
+}
+
+func mustListJobs(t *testing.T, c client.Client) []batchv1.Job {
+	t.Helper()
+
+	var jobs batchv1.JobList
+
+	require.NoError(t, c.List(context.Background(), &jobs, client.InNamespace(testNamespace)))
+
+	return jobs.Items
+}
+
+// setCondition marks the job with the provided condition.
+func setCondition(t *testing.T, c client.Client, job *batchv1.Job, conditionType batchv1.JobConditionType) {
+	t.Helper()
+
+	job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{
+		Type:   conditionType,
+		Status: corev1.ConditionTrue,
+	})
+
+	require.NoError(t, c.Status().Update(context.Background(), job))
+}
+
+// TestJobLifecycle tests a job is created, gated on completion, replaced when the
+// specification changes and deleted on deprovision.
+func TestJobLifecycle(t *testing.T) {
+	t.Parallel()
+
+	c := fake.NewClientBuilder().WithStatusSubresource(&batchv1.Job{}).Build()
+	ctx := newContext(c)
+
+	provisioner := job.New(testName, testNamespace, newSpec("migrate:v1"))
+
+	require.ErrorIs(t, provisioner.Provision(ctx), provisioners.ErrYield)
+
+	jobs := mustListJobs(t, c)
+	require.Len(t, jobs, 1)
+
+	first := jobs[0].Name
+
+	// The name is deterministic.
+	require.ErrorIs(t, provisioner.Provision(ctx), provisioners.ErrYield)
+	require.Len(t, mustListJobs(t, c), 1)
+
+	setCondition(t, c, &jobs[0], batchv1.JobComplete)
+
+	require.NoError(t, provisioner.Provision(ctx))
+
+	// A new specification runs a new job, and cleans up the old one on completion.
+	provisioner = job.New(testName, testNamespace, newSpec("migrate:v2"))
+
+	require.ErrorIs(t, provisioner.Provision(ctx), provisioners.ErrYield)
+
+	jobs = mustListJobs(t, c)
+	require.Len(t, jobs, 2)
+
+	for i := range jobs {
+		if jobs[i].Name != first {
+			setCondition(t, c, &jobs[i], batchv1.JobComplete)
+		}
+	}
+
+	require.NoError(t, provisioner.Provision(ctx))
+
+	jobs = mustListJobs(t, c)
+	require.Len(t, jobs, 1)
+	assert.NotEqual(t, first, jobs[0].Name)
+
+	require.ErrorIs(t, provisioner.Deprovision(ctx), provisioners.ErrYield)
+	require.Empty(t, mustListJobs(t, c))
+	require.NoError(t, provisioner.Deprovision(ctx))
+}
+
+// TestJobFailed tests failure details are surfaced.
+func TestJobFailed(t *testing.T) {
+	t.Parallel()
+
+	c := fake.NewClientBuilder().WithStatusSubresource(&batchv1.Job{}).Build()
+	ctx := newContext(c)
+
+	provisioner := job.New(testName, testNamespace, newSpec("migrate:v1"))
+
+	require.ErrorIs(t, provisioner.Provision(ctx), provisioners.ErrYield)
+
+	jobs := mustListJobs(t, c)
+	require.Len(t, jobs, 1)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      jobs[0].Name + "-abcde",
+			Labels: map[string]string{
+				batchv1.JobNameLabel: jobs[0].Name,
+			},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name: "migrate",
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 1,
+							Reason:   "Error",
+							Message:  "relation does not exist",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	provisioner.WithClientset(kubernetesfake.NewClientset(pod))
+
+	setCondition(t, c, &jobs[0], batchv1.JobFailed)
+
+	err := provisioner.Provision(ctx)
+	require.ErrorIs(t, err, job.ErrJobFailed)
+	assert.Contains(t, err.Error(), "relation does not exist")
+}
+
+// TestJobRetention tests completed jobs are retained for a TTL on deprovision.
+func TestJobRetention(t *testing.T) {
+	t.Parallel()
+
+	c := fake.NewClientBuilder().WithStatusSubresource(&batchv1.Job{}).Build()
+	ctx := newContext(c)
+
+	provisioner := job.New(testName, testNamespace, newSpec("migrate:v1")).WithRetention(time.Hour)
+
+	require.ErrorIs(t, provisioner.Provision(ctx), provisioners.ErrYield)
+
+	jobs := mustListJobs(t, c)
+	require.Len(t, jobs, 1)
+
+	setCondition(t, c, &jobs[0], batchv1.JobComplete)
+
+	require.NoError(t, provisioner.Deprovision(ctx))
+
+	jobs = mustListJobs(t, c)
+	require.Len(t, jobs, 1)
+	require.NotNil(t, jobs[0].Spec.TTLSecondsAfterFinished)
+	assert.Equal(t, int32(3600), *jobs[0].Spec.TTLSecondsAfterFinished)
+}
+
+// TestJobLongName tests job names and labels are kept within Kubernetes limits
+// for long provisioner names.
+func TestJobLongName(t *testing.T) {
+	t.Parallel()
+
+	c := fake.NewClientBuilder().WithStatusSubresource(&batchv1.Job{}).Build()
+	ctx := newContext(c)
+
+	provisioner := job.New(strings.Repeat("a", 100), testNamespace, newSpec("migrate:v1"))
+
+	require.ErrorIs(t, provisioner.Provision(ctx), provisioners.ErrYield)
+
+	jobs := mustListJobs(t, c)
+	require.Len(t, jobs, 1)
+	assert.LessOrEqual(t, len(jobs[0].Name), 63)
+	assert.LessOrEqual(t, len(jobs[0].Labels[constants.JobLabel]), 63)
+
+	require.ErrorIs(t, provisioner.Provision(ctx), provisioners.ErrYield)
+	require.Len(t, mustListJobs(t, c), 1)
+
+	require.ErrorIs(t, provisioner.Deprovision(ctx), provisioners.ErrYield)
+	require.Empty(t, mustListJobs(t, c))
+}
+
+// TestJobRecreatedOwner tests a retained job from a deleted resource isn't
+// mistaken for the job of a new resource with the same specification.
+func TestJobRecreatedOwner(t *testing.T) {
+	t.Parallel()
+
+	c := fake.NewClientBuilder().WithStatusSubresource(&batchv1.Job{}).Build()
+	ctx := newOwnedContext(c, "e3b3c4a8-1c1f-4b63-a3b0-2c1ea5b0a6c1")
+
+	provisioner := job.New(testName, testNamespace, newSpec("migrate:v1")).WithRetention(time.Hour)
+
+	require.ErrorIs(t, provisioner.Provision(ctx), provisioners.ErrYield)
+
+	jobs := mustListJobs(t, c)
+	require.Len(t, jobs, 1)
+
+	first := jobs[0].Name
+
+	setCondition(t, c, &jobs[0], batchv1.JobComplete)
+
+	require.NoError(t, provisioner.Provision(ctx))
+	require.NoError(t, provisioner.Deprovision(ctx))
+
+	// The resource is recreated, the job must run again, and the retained job
+	// left for Kubernetes to clean up.
+	ctx = newOwnedContext(c, "9b1d3e52-7f0a-4c4e-8d55-3f7b7e8e2d10")
+
+	require.ErrorIs(t, provisioner.Provision(ctx), provisioners.ErrYield)
+
+	jobs = mustListJobs(t, c)
+	require.Len(t, jobs, 2)
+
+	for i := range jobs {
+		if jobs[i].Name != first {
+			setCondition(t, c, &jobs[i], batchv1.JobComplete)
+		}
+	}
+
+	require.NoError(t, provisioner.Provision(ctx))
+	require.Len(t, mustListJobs(t, c), 2)
+}
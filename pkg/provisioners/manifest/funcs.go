@@ -0,0 +1,219 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	// ErrRequired is raised by the required template function.
+	ErrRequired = errors.New("required value missing")
+
+	// ErrDict is raised when dict is called with bad arguments.
+	ErrDict = errors.New("dict requires key/value pairs with string keys")
+)
+
+// empty mirrors Helm's definition of emptiness.
+func empty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+
+	//nolint:exhaustive
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
+
+func defaultValue(fallback, value interface{}) interface{} {
+	if empty(value) {
+		return fallback
+	}
+
+	return value
+}
+
+func coalesce(values ...interface{}) interface{} {
+	for _, value := range values {
+		if !empty(value) {
+			return value
+		}
+	}
+
+	return nil
+}
+
+func required(message string, value interface{}) (interface{}, error) {
+	if empty(value) {
+		return nil, fmt.Errorf("%w: %s", ErrRequired, message)
+	}
+
+	return value, nil
+}
+
+func ternary(truthy, falsy interface{}, condition bool) interface{} {
+	if condition {
+		return truthy
+	}
+
+	return falsy
+}
+
+func quote(value interface{}) string {
+	return fmt.Sprintf("%q", fmt.Sprint(value))
+}
+
+func squote(value interface{}) string {
+	return "'" + fmt.Sprint(value) + "'"
+}
+
+func indent(spaces int, value string) string {
+	padding := strings.Repeat(" ", spaces)
+
+	return padding + strings.ReplaceAll(value, "\n", "\n"+padding)
+}
+
+func nindent(spaces int, value string) string {
+	return "\n" + indent(spaces, value)
+}
+
+func toYAML(value interface{}) (string, error) {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+func toJSON(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func b64dec(value string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func sha256sum(value string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(value)))
+}
+
+func join(separator string, values interface{}) string {
+	v := reflect.ValueOf(values)
+
+	//nolint:exhaustive
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		s := make([]string, v.Len())
+
+		for i := range v.Len() {
+			s[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+
+		return strings.Join(s, separator)
+	default:
+		return fmt.Sprint(values)
+	}
+}
+
+func dict(values ...interface{}) (map[string]interface{}, error) {
+	if len(values)%2 != 0 {
+		return nil, ErrDict
+	}
+
+	out := make(map[string]interface{}, len(values)/2)
+
+	for i := 0; i < len(values); i += 2 {
+		key, ok := values[i].(string)
+		if !ok {
+			return nil, ErrDict
+		}
+
+		out[key] = values[i+1]
+	}
+
+	return out, nil
+}
+
+func list(values ...interface{}) []interface{} {
+	return values
+}
+
+func hasKey(m map[string]interface{}, key string) bool {
+	_, ok := m[key]
+
+	return ok
+}
+
+// funcs returns a set of Sprig-like helper functions for use in templates.
+// These are modelled on the subset most commonly used by Helm charts.
+func funcs() template.FuncMap {
+	return template.FuncMap{
+		"default":   defaultValue,
+		"empty":     empty,
+		"coalesce":  coalesce,
+		"required":  required,
+		"ternary":   ternary,
+		"quote":     quote,
+		"squote":    squote,
+		"indent":    indent,
+		"nindent":   nindent,
+		"lower":     strings.ToLower,
+		"upper":     strings.ToUpper,
+		"trim":      strings.TrimSpace,
+		"replace":   func(old, replacement, s string) string { return strings.ReplaceAll(s, old, replacement) },
+		"contains":  func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix": func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix": func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"toYaml":    toYAML,
+		"toJson":    toJSON,
+		"b64enc":    func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+		"b64dec":    b64dec,
+		"sha256sum": sha256sum,
+		"join":      join,
+		"dict":      dict,
+		"list":      list,
+		"hasKey":    hasKey,
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package manifesttest provides golden file testing of templated manifests.
+// Run tests with -update-golden to regenerate the golden files after an
+// intentional change, and review the diff.
+package manifesttest
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	unikornv1 "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1"
+	clientlib "github.com/unikorn-cloud/core/pkg/client"
+	"github.com/unikorn-cloud/core/pkg/provisioners/application"
+	"github.com/unikorn-cloud/core/pkg/provisioners/manifest"
+)
+
+//nolint:gochecknoglobals
+var update = flag.Bool("update-golden", false, "Update golden files rather than comparing against them")
+
+// NewContext returns a context populated as the reconciler would do for template
+// rendering.
+func NewContext(ctx context.Context, resource unikornv1.ManagableResourceInterface, cluster *clientlib.ClusterContext) context.Context {
+	ctx = clientlib.NewContextWithCluster(ctx, cluster)
+	ctx = application.NewContext(ctx, resource)
+
+	return ctx
+}
+
+// AssertGolden renders the provisioner's templates and compares them with the
+// golden file at the given path.
+func AssertGolden(ctx context.Context, t *testing.T, provisioner *manifest.Provisioner, path string) {
+	t.Helper()
+
+	rendered, err := provisioner.Render(ctx)
+	require.NoError(t, err)
+
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, rendered, 0o600))
+
+		return
+	}
+
+	golden, err := os.ReadFile(path)
+	require.NoError(t, err, "golden file missing, run with -update-golden to create it")
+
+	assert.Equal(t, string(golden), string(rendered))
+}
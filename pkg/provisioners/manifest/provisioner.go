@@ -0,0 +1,295 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"strings"
+	"text/template"
+
+	unikornv1 "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1"
+	"github.com/unikorn-cloud/core/pkg/cd"
+	clientlib "github.com/unikorn-cloud/core/pkg/client"
+	"github.com/unikorn-cloud/core/pkg/provisioners"
+	"github.com/unikorn-cloud/core/pkg/provisioners/application"
+	"github.com/unikorn-cloud/core/pkg/provisioners/resource"
+)
+
+// DataGenerator is an interface that allows generators to supply arbitrary
+// data to templates, this is exposed as .Values.
+type DataGenerator interface {
+	TemplateData(ctx context.Context) (map[string]interface{}, error)
+}
+
+// ClusterData describes the cluster the manifests are being rendered for.
+type ClusterData struct {
+	// ID is the unique remote cluster ID, this is nil for the
+	// host cluster.
+	ID *cd.ResourceIdentifier
+	// Host is the Kubernetes endpoint hostname.
+	Host string
+	// Port is the Kubernetes endpoint port.
+	Port string
+}
+
+// Data is the top-level object passed to templates.
+type Data struct {
+	// Resource is the managed resource that is being reconciled.
+	Resource unikornv1.ManagableResourceInterface
+	// Cluster describes the cluster being provisioned on to.
+	Cluster ClusterData
+	// Namespace is the namespace set on the provisioner.
+	Namespace string
+	// Values are supplied by the generator, if defined.
+	Values map[string]interface{}
+}
+
+// source is a named template.
+type source struct {
+	name string
+	data []byte
+}
+
+// sourceFunc lazily loads templates.
+type sourceFunc func() ([]source, error)
+
+// Provisioner renders Go templated manifests and provisions the resulting
+// resources.  Templates are rendered on every provision/deprovision with data
+// derived from the reconcile context.  As with Helm, multiple documents may be
+// defined in a single template.
+type Provisioner struct {
+	provisioners.Metadata
+
+	// sources provides the templates to render.
+	sources sourceFunc
+
+	// namespace is exposed to templates and defaults the namespace of
+	// any namespaced resources.
+	namespace string
+
+	// generator provides additional template data.
+	generator interface{}
+
+	// serverSideApply is passed through to the resource provisioner.
+	serverSideApply bool
+
+	// waitForReady is passed through to the resource provisioner.
+	waitForReady bool
+}
+
+// Ensure the Provisioner interface is implemented.
+var _ provisioners.Provisioner = &Provisioner{}
+
+// New returns a provisioner that renders a single, possibly multi-document,
+// template.
+func New(name string, data []byte) *Provisioner {
+	return &Provisioner{
+		Metadata: provisioners.Metadata{
+			Name: name,
+		},
+		sources: func() ([]source, error) {
+			return []source{{name: name, data: data}}, nil
+		},
+	}
+}
+
+// NewFromFS returns a provisioner that renders all templates in the file system that
+// match the provided glob patterns.  Within a pattern, templates are processed in lexical
+// order, and patterns are processed in the order provided.  Every pattern must match at
+// least one template.
+func NewFromFS(name string, fsys fs.FS, patterns ...string) *Provisioner {
+	sources := func() ([]source, error) {
+		var sources []source
+
+		for _, pattern := range patterns {
+			paths, err := fs.Glob(fsys, pattern)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(paths) == 0 {
+				return nil, fmt.Errorf("%w: %s", resource.ErrNoManifests, pattern)
+			}
+
+			for _, path := range paths {
+				data, err := fs.ReadFile(fsys, path)
+				if err != nil {
+					return nil, err
+				}
+
+				sources = append(sources, source{name: path, data: data})
+			}
+		}
+
+		return sources, nil
+	}
+
+	return &Provisioner{
+		Metadata: provisioners.Metadata{
+			Name: name,
+		},
+		sources: sources,
+	}
+}
+
+// InNamespace exposes the namespace to templates, and sets the namespace for any
+// namespaced resources that don't explicitly define one.
+func (p *Provisioner) InNamespace(namespace string) *Provisioner {
+	p.namespace = namespace
+
+	return p
+}
+
+// WithGenerator registers an object that can generate additional template data.
+func (p *Provisioner) WithGenerator(generator interface{}) *Provisioner {
+	p.generator = generator
+
+	return p
+}
+
+// WithServerSideApply uses server-side apply to create and update resources.
+func (p *Provisioner) WithServerSideApply() *Provisioner {
+	p.serverSideApply = true
+
+	return p
+}
+
+// WaitForReady gates provisioning on resource readiness.
+func (p *Provisioner) WaitForReady() *Provisioner {
+	p.waitForReady = true
+
+	return p
+}
+
+// getData builds the template data from the context.
+func (p *Provisioner) getData(ctx context.Context) (*Data, error) {
+	clusterContext, err := clientlib.ClusterFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	managed, err := application.ResourceFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &Data{
+		Resource: managed,
+		Cluster: ClusterData{
+			ID:   clusterContext.ID,
+			Host: clusterContext.Host,
+			Port: clusterContext.Port,
+		},
+		Namespace: p.namespace,
+		Values:    map[string]interface{}{},
+	}
+
+	if p.generator != nil {
+		if generator, ok := p.generator.(DataGenerator); ok {
+			values, err := generator.TemplateData(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			if values != nil {
+				data.Values = values
+			}
+		}
+	}
+
+	return data, nil
+}
+
+// Render renders all templates into a single multi-document manifest.
+func (p *Provisioner) Render(ctx context.Context) ([]byte, error) {
+	data, err := p.getData(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sources, err := p.sources()
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+
+	for _, source := range sources {
+		// Like Helm, missing keys are allowed so that "default" works as expected,
+		// use "required" where a value must be present.
+		tmpl, err := template.New(source.name).Option("missingkey=zero").Funcs(funcs()).Parse(string(source.data))
+		if err != nil {
+			return nil, err
+		}
+
+		var rendered bytes.Buffer
+
+		if err := tmpl.Execute(&rendered, data); err != nil {
+			return nil, fmt.Errorf("%s: %w", source.name, err)
+		}
+
+		buffer.WriteString("---\n")
+		buffer.WriteString(strings.ReplaceAll(rendered.String(), "<no value>", ""))
+		buffer.WriteString("\n")
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// getProvisioner renders the templates and returns a resource provisioner to
+// manage the result.
+func (p *Provisioner) getProvisioner(ctx context.Context) (*resource.Provisioner, error) {
+	manifest, err := p.Render(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	provisioner := resource.NewFromBytes(manifest).InNamespace(p.namespace)
+
+	if p.serverSideApply {
+		provisioner.WithServerSideApply()
+	}
+
+	if p.waitForReady {
+		provisioner.WaitForReady()
+	}
+
+	return provisioner, nil
+}
+
+// Provision implements the Provision interface.
+func (p *Provisioner) Provision(ctx context.Context) error {
+	provisioner, err := p.getProvisioner(ctx)
+	if err != nil {
+		return err
+	}
+
+	return provisioner.Provision(ctx)
+}
+
+// Deprovision implements the Provision interface.
+func (p *Provisioner) Deprovision(ctx context.Context) error {
+	provisioner, err := p.getProvisioner(ctx)
+	if err != nil {
+		return err
+	}
+
+	return provisioner.Deprovision(ctx)
+}
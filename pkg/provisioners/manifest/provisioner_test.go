@@ -0,0 +1,152 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	unikornv1fake "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1/fake"
+	coreclient "github.com/unikorn-cloud/core/pkg/client"
+	coreerrors "github.com/unikorn-cloud/core/pkg/errors"
+	"github.com/unikorn-cloud/core/pkg/provisioners/manifest"
+	"github.com/unikorn-cloud/core/pkg/provisioners/manifest/manifesttest"
+	"github.com/unikorn-cloud/core/pkg/provisioners/resource"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testNamespace = "foo"
+)
+
+// generator supplies template data.
+type generator struct{}
+
+var _ manifest.DataGenerator = &generator{}
+
+func (*generator) TemplateData(_ context.Context) (map[string]interface{}, error) {
+	data := map[string]interface{}{
+		"config": "some-configuration",
+	}
+
+	return data, nil
+}
+
+func newManagedResource() *unikornv1fake.ManagedResource {
+	return &unikornv1fake.ManagedResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "bar",
+			Labels: map[string]string{
+				"cat": "meow",
+				"dog": "woof",
+			},
+		},
+	}
+}
+
+func mustNewClient(t *testing.T) client.Client {
+	t.Helper()
+
+	scheme, err := coreclient.NewScheme()
+	require.NoError(t, err)
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+
+	return fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).Build()
+}
+
+func newContext(c client.Client) context.Context {
+	cluster := &coreclient.ClusterContext{
+		Client: c,
+		Host:   "kubernetes.example.com",
+		Port:   "6443",
+	}
+
+	return manifesttest.NewContext(context.Background(), newManagedResource(), cluster)
+}
+
+func newProvisioner() *manifest.Provisioner {
+	return manifest.NewFromFS("test", os.DirFS("testdata"), "*.yaml.tmpl").InNamespace(testNamespace).WithGenerator(&generator{})
+}
+
+// TestRenderGolden tests templates are rendered as expected.
+func TestRenderGolden(t *testing.T) {
+	t.Parallel()
+
+	ctx := newContext(mustNewClient(t))
+
+	manifesttest.AssertGolden(ctx, t, newProvisioner(), "testdata/configmap.golden.yaml")
+}
+
+// TestRenderRequired tests references to undefined required data are errors.
+func TestRenderRequired(t *testing.T) {
+	t.Parallel()
+
+	ctx := newContext(mustNewClient(t))
+
+	_, err := manifest.New("test", []byte(`{{ required "missing is required" .Values.missing }}`)).Render(ctx)
+	assert.ErrorIs(t, err, manifest.ErrRequired)
+}
+
+// TestProvision tests rendered templates are provisioned.
+func TestProvision(t *testing.T) {
+	t.Parallel()
+
+	c := mustNewClient(t)
+	ctx := newContext(c)
+
+	require.NoError(t, newProvisioner().Provision(ctx))
+
+	var configMap corev1.ConfigMap
+
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: "bar-config"}, &configMap))
+	assert.Equal(t, "https://kubernetes.example.com:6443", configMap.Data["endpoint"])
+	assert.Equal(t, "meow", configMap.Labels["cat"])
+}
+
+// TestRenderNoMatch tests patterns that match no templates are errors.
+func TestRenderNoMatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := newContext(mustNewClient(t))
+
+	_, err := manifest.NewFromFS("test", os.DirFS("testdata"), "*.yaml.tmpl", "*.missing").Render(ctx)
+	assert.ErrorIs(t, err, resource.ErrNoManifests)
+}
+
+// TestRenderNoResource tests a context without a resource is an error rather
+// than a panic.
+func TestRenderNoResource(t *testing.T) {
+	t.Parallel()
+
+	ctx := coreclient.NewContextWithCluster(context.Background(), &coreclient.ClusterContext{Client: mustNewClient(t)})
+
+	_, err := newProvisioner().Render(ctx)
+	assert.ErrorIs(t, err, coreerrors.ErrInvalidContext)
+}
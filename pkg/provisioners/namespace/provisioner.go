@@ -0,0 +1,436 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"slices"
+
+	clientlib "github.com/unikorn-cloud/core/pkg/client"
+	"github.com/unikorn-cloud/core/pkg/constants"
+	"github.com/unikorn-cloud/core/pkg/provisioners"
+	"github.com/unikorn-cloud/core/pkg/provisioners/application"
+	"github.com/unikorn-cloud/core/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// PodSecurityLevel is a Pod Security Standards level.
+type PodSecurityLevel string
+
+const (
+	// PodSecurityPrivileged is unrestricted.
+	PodSecurityPrivileged PodSecurityLevel = "privileged"
+
+	// PodSecurityBaseline prevents known privilege escalations.
+	PodSecurityBaseline PodSecurityLevel = "baseline"
+
+	// PodSecurityRestricted follows pod hardening best practices.
+	PodSecurityRestricted PodSecurityLevel = "restricted"
+)
+
+const (
+	// DefaultPolicyName is the name given to policy objects where only one
+	// of that type is managed.
+	DefaultPolicyName = "default"
+
+	// DefaultDenyPolicyName is the name given to the default deny network
+	// policy.
+	DefaultDenyPolicyName = "default-deny"
+)
+
+// object is a managed object and a function to apply the desired state to it.
+type object struct {
+	resource client.Object
+	mutate   func()
+}
+
+// Provisioner owns a namespace and a bundle of policy objects within it.  The
+// namespace itself is only deleted on deprovision if explicitly requested, as
+// its deletion will cascade to everything within it, otherwise only the policy
+// objects are removed.
+type Provisioner struct {
+	provisioners.Metadata
+
+	// namespace is the namespace name.
+	namespace string
+
+	// labels are applied to the namespace.
+	labels map[string]string
+
+	// podSecurity, if set, enforces the Pod Security Standards level.
+	podSecurity PodSecurityLevel
+
+	// quota, if set, is applied as a resource quota.
+	quota *corev1.ResourceQuotaSpec
+
+	// limits, if set, is applied as a limit range.
+	limits *corev1.LimitRangeSpec
+
+	// networkPolicies are applied in the namespace keyed by name.
+	networkPolicies map[string]networkingv1.NetworkPolicySpec
+
+	// delete removes the namespace on deprovision.
+	delete bool
+}
+
+// Ensure the Provisioner interface is implemented.
+var _ provisioners.Provisioner = &Provisioner{}
+
+// New returns a new namespace provisioner.
+func New(namespace string) *Provisioner {
+	return &Provisioner{
+		Metadata: provisioners.Metadata{
+			Name: "namespace-" + namespace,
+		},
+		namespace:       namespace,
+		networkPolicies: map[string]networkingv1.NetworkPolicySpec{},
+	}
+}
+
+// WithLabels adds labels to the namespace.  Labels not managed by this
+// provisioner are preserved.
+func (p *Provisioner) WithLabels(labels map[string]string) *Provisioner {
+	p.labels = labels
+
+	return p
+}
+
+// WithPodSecurity enforces a Pod Security Standards level on the namespace.
+func (p *Provisioner) WithPodSecurity(level PodSecurityLevel) *Provisioner {
+	p.podSecurity = level
+
+	return p
+}
+
+// WithResourceQuota adds a resource quota to the namespace.
+func (p *Provisioner) WithResourceQuota(spec corev1.ResourceQuotaSpec) *Provisioner {
+	p.quota = &spec
+
+	return p
+}
+
+// WithLimitRange adds a limit range to the namespace.
+func (p *Provisioner) WithLimitRange(spec corev1.LimitRangeSpec) *Provisioner {
+	p.limits = &spec
+
+	return p
+}
+
+// WithNetworkPolicy adds a network policy to the namespace.
+func (p *Provisioner) WithNetworkPolicy(name string, spec networkingv1.NetworkPolicySpec) *Provisioner {
+	p.networkPolicies[name] = spec
+
+	return p
+}
+
+// WithDefaultDeny adds a network policy that denies all ingress and egress
+// traffic to pods in the namespace, unless allowed by another policy.
+func (p *Provisioner) WithDefaultDeny() *Provisioner {
+	return p.WithNetworkPolicy(DefaultDenyPolicyName, networkingv1.NetworkPolicySpec{
+		PolicyTypes: []networkingv1.PolicyType{
+			networkingv1.PolicyTypeIngress,
+			networkingv1.PolicyTypeEgress,
+		},
+	})
+}
+
+// WithDeletion deletes the namespace, and everything in it, on deprovision.
+func (p *Provisioner) WithDeletion() *Provisioner {
+	p.delete = true
+
+	return p
+}
+
+// namespaceLabels returns the complete set of managed labels.
+func (p *Provisioner) namespaceLabels() map[string]string {
+	labels := map[string]string{}
+
+	for k, v := range p.labels {
+		labels[k] = v
+	}
+
+	if p.podSecurity != "" {
+		labels["pod-security.kubernetes.io/enforce"] = string(p.podSecurity)
+		labels["pod-security.kubernetes.io/enforce-version"] = "latest"
+	}
+
+	return labels
+}
+
+// label returns a value that identifies policy objects created by this provisioner.
+// This is the provisioner name where possible, otherwise it is truncated, and a
+// hash of the full name appended to keep it unique.
+func (p *Provisioner) label() string {
+	if len(p.Name) <= validation.DNS1123LabelMaxLength {
+		return p.Name
+	}
+
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(p.Name)))[:8]
+
+	return p.Name[:validation.DNS1123LabelMaxLength-len(hash)-1] + "-" + hash
+}
+
+// owner returns the UID of the resource being reconciled, if known.
+func owner(ctx context.Context) string {
+	resource, err := application.ResourceFromContext(ctx)
+	if err != nil {
+		return ""
+	}
+
+	return string(resource.GetUID())
+}
+
+// selector matches policy objects created by this provisioner for the owner.
+// Other provisioners may manage objects in the same namespace, so these must
+// not be matched, otherwise they would be pruned.
+func (p *Provisioner) selector(owner string) client.MatchingLabels {
+	selector := client.MatchingLabels{
+		constants.NamespacePolicyLabel: p.label(),
+	}
+
+	if owner != "" {
+		selector[constants.NamespacePolicyOwnerLabel] = owner
+	}
+
+	return selector
+}
+
+// objects returns the managed objects in creation order.
+func (p *Provisioner) objects(owner string) []object {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: p.namespace,
+		},
+	}
+
+	objects := []object{
+		{
+			resource: namespace,
+			mutate: func() {
+				if namespace.Labels == nil {
+					namespace.Labels = map[string]string{}
+				}
+
+				for k, v := range p.namespaceLabels() {
+					namespace.Labels[k] = v
+				}
+			},
+		},
+	}
+
+	objectMeta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Namespace: p.namespace,
+			Name:      name,
+		}
+	}
+
+	// Policy objects are labelled so they can be pruned when no longer required.
+	label := func(o client.Object) {
+		labels := o.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+
+		for k, v := range p.selector(owner) {
+			labels[k] = v
+		}
+
+		o.SetLabels(labels)
+	}
+
+	if p.quota != nil {
+		quota := &corev1.ResourceQuota{
+			ObjectMeta: objectMeta(DefaultPolicyName),
+		}
+
+		objects = append(objects, object{
+			resource: quota,
+			mutate: func() {
+				label(quota)
+
+				quota.Spec = *p.quota.DeepCopy()
+			},
+		})
+	}
+
+	if p.limits != nil {
+		limits := &corev1.LimitRange{
+			ObjectMeta: objectMeta(DefaultPolicyName),
+		}
+
+		objects = append(objects, object{
+			resource: limits,
+			mutate: func() {
+				label(limits)
+
+				limits.Spec = *p.limits.DeepCopy()
+			},
+		})
+	}
+
+	names := util.Keys(p.networkPolicies)
+	slices.Sort(names)
+
+	for _, name := range names {
+		spec := p.networkPolicies[name]
+
+		policy := &networkingv1.NetworkPolicy{
+			ObjectMeta: objectMeta(name),
+		}
+
+		objects = append(objects, object{
+			resource: policy,
+			mutate: func() {
+				label(policy)
+
+				policy.Spec = *spec.DeepCopy()
+			},
+		})
+	}
+
+	return objects
+}
+
+// objectKey uniquely identifies an object within the namespace.
+func objectKey(o client.Object) string {
+	return fmt.Sprintf("%T/%s", o, o.GetName())
+}
+
+// prune deletes any policy objects that were created by this provisioner but
+// are no longer desired.
+func (p *Provisioner) prune(ctx context.Context, cli client.Client, owner string, desired []object) error {
+	log := log.FromContext(ctx)
+
+	keep := map[string]bool{}
+
+	for _, o := range desired {
+		keep[objectKey(o.resource)] = true
+	}
+
+	lists := []client.ObjectList{
+		&corev1.ResourceQuotaList{},
+		&corev1.LimitRangeList{},
+		&networkingv1.NetworkPolicyList{},
+	}
+
+	for _, list := range lists {
+		if err := cli.List(ctx, list, client.InNamespace(p.namespace), p.selector(owner)); err != nil {
+			return err
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			//nolint:forcetypeassert
+			resource := item.(client.Object)
+
+			if keep[objectKey(resource)] {
+				continue
+			}
+
+			log.Info("deleting object", "namespace", resource.GetNamespace(), "name", resource.GetName())
+
+			if err := cli.Delete(ctx, resource); err != nil && !kerrors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Provision implements the Provision interface.
+func (p *Provisioner) Provision(ctx context.Context) error {
+	log := log.FromContext(ctx)
+
+	cluster, err := clientlib.ClusterFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	owner := owner(ctx)
+
+	objects := p.objects(owner)
+
+	for _, o := range objects {
+		mutate := func() error {
+			o.mutate()
+
+			return nil
+		}
+
+		result, err := controllerutil.CreateOrUpdate(ctx, cluster.Client, o.resource, mutate)
+		if err != nil {
+			return err
+		}
+
+		log.Info(fmt.Sprintf("object %v", result), "namespace", o.resource.GetNamespace(), "name", o.resource.GetName())
+	}
+
+	return p.prune(ctx, cluster.Client, owner, objects)
+}
+
+// Deprovision implements the Provision interface.
+func (p *Provisioner) Deprovision(ctx context.Context) error {
+	log := log.FromContext(ctx)
+
+	cluster, err := clientlib.ClusterFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	// When the namespace is retained, just remove the policy objects we created.
+	if !p.delete {
+		return p.prune(ctx, cluster.Client, owner(ctx), nil)
+	}
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: p.namespace,
+		},
+	}
+
+	if err := cluster.Client.Delete(ctx, namespace); err != nil {
+		if kerrors.IsNotFound(err) {
+			log.Info("namespace deleted", "namespace", p.namespace)
+
+			return nil
+		}
+
+		return err
+	}
+
+	log.Info("awaiting namespace deletion", "namespace", p.namespace)
+
+	return provisioners.ErrYield
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	unikornv1fake "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1/fake"
+	coreclient "github.com/unikorn-cloud/core/pkg/client"
+	"github.com/unikorn-cloud/core/pkg/provisioners"
+	"github.com/unikorn-cloud/core/pkg/provisioners/application"
+	"github.com/unikorn-cloud/core/pkg/provisioners/namespace"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testNamespace = "tenant"
+)
+
+func newContext(c client.Client) context.Context {
+	return coreclient.NewContextWithCluster(context.Background(), &coreclient.ClusterContext{Client: c})
+}
+
+// newOwnedContext returns a context with a resource that owns the namespace policies.
+func newOwnedContext(c client.Client, uid types.UID) context.Context {
+	resource := &unikornv1fake.ManagedResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "owner",
+			Name:      string(uid),
+			UID:       uid,
+		},
+	}
+
+	return application.NewContext(newContext(c), resource)
+}
+
+func newQuota(cpu string) corev1.ResourceQuotaSpec {
+	return corev1.ResourceQuotaSpec{
+		Hard: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse(cpu),
+		},
+	}
+}
+
+// TestNamespaceProvision tests the namespace and its policies are created and
+// kept up to date, and that labels not managed by us are preserved.
+func TestNamespaceProvision(t *testing.T) {
+	t.Parallel()
+
+	existing := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: testNamespace,
+			Labels: map[string]string{
+				"foo": "bar",
+			},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithObjects(existing).Build()
+	ctx := newContext(c)
+
+	provisioner := namespace.New(testNamespace).
+		WithLabels(map[string]string{"team": "a"}).
+		WithPodSecurity(namespace.PodSecurityRestricted).
+		WithResourceQuota(newQuota("4")).
+		WithLimitRange(corev1.LimitRangeSpec{}).
+		WithDefaultDeny()
+
+	require.NoError(t, provisioner.Provision(ctx))
+
+	var ns corev1.Namespace
+
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: testNamespace}, &ns))
+	assert.Equal(t, "bar", ns.Labels["foo"])
+	assert.Equal(t, "a", ns.Labels["team"])
+	assert.Equal(t, "restricted", ns.Labels["pod-security.kubernetes.io/enforce"])
+
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: namespace.DefaultPolicyName}, &corev1.LimitRange{}))
+
+	var policy networkingv1.NetworkPolicy
+
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: namespace.DefaultDenyPolicyName}, &policy))
+	assert.Len(t, policy.Spec.PolicyTypes, 2)
+
+	// Policy changes are applied.
+	require.NoError(t, namespace.New(testNamespace).WithResourceQuota(newQuota("8")).Provision(ctx))
+
+	var quota corev1.ResourceQuota
+
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: namespace.DefaultPolicyName}, &quota))
+	assert.True(t, quota.Spec.Hard.Cpu().Equal(resource.MustParse("8")))
+}
+
+// TestNamespaceDeprovision tests the namespace is only deleted when requested.
+func TestNamespaceDeprovision(t *testing.T) {
+	t.Parallel()
+
+	c := fake.NewClientBuilder().Build()
+	ctx := newContext(c)
+
+	provisioner := namespace.New(testNamespace)
+
+	require.NoError(t, provisioner.Provision(ctx))
+	require.NoError(t, provisioner.Deprovision(ctx))
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: testNamespace}, &corev1.Namespace{}))
+
+	provisioner.WithDeletion()
+
+	require.ErrorIs(t, provisioner.Deprovision(ctx), provisioners.ErrYield)
+	require.True(t, kerrors.IsNotFound(c.Get(ctx, client.ObjectKey{Name: testNamespace}, &corev1.Namespace{})))
+	require.NoError(t, provisioner.Deprovision(ctx))
+}
+
+// TestNamespacePrune tests policy objects are removed when no longer required,
+// and that objects not created by us are left alone.
+func TestNamespacePrune(t *testing.T) {
+	t.Parallel()
+
+	unmanaged := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      "unmanaged",
+		},
+	}
+
+	c := fake.NewClientBuilder().WithObjects(unmanaged).Build()
+	ctx := newContext(c)
+
+	require.NoError(t, namespace.New(testNamespace).WithResourceQuota(newQuota("4")).WithLimitRange(corev1.LimitRangeSpec{}).WithDefaultDeny().Provision(ctx))
+
+	// Removal of policies deletes them.
+	provisioner := namespace.New(testNamespace).WithResourceQuota(newQuota("4"))
+
+	require.NoError(t, provisioner.Provision(ctx))
+
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: namespace.DefaultPolicyName}, &corev1.ResourceQuota{}))
+	require.True(t, kerrors.IsNotFound(c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: namespace.DefaultPolicyName}, &corev1.LimitRange{})))
+	require.True(t, kerrors.IsNotFound(c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: namespace.DefaultDenyPolicyName}, &networkingv1.NetworkPolicy{})))
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: "unmanaged"}, &networkingv1.NetworkPolicy{}))
+
+	// Deprovisioning without deletion removes all policies, but not the namespace.
+	require.NoError(t, provisioner.Deprovision(ctx))
+
+	require.True(t, kerrors.IsNotFound(c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: namespace.DefaultPolicyName}, &corev1.ResourceQuota{})))
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: "unmanaged"}, &networkingv1.NetworkPolicy{}))
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: testNamespace}, &corev1.Namespace{}))
+}
+
+// TestNamespacePruneShared tests that where multiple resources manage policies in
+// the same namespace, they only prune their own.
+func TestNamespacePruneShared(t *testing.T) {
+	t.Parallel()
+
+	c := fake.NewClientBuilder().Build()
+
+	ctxA := newOwnedContext(c, "2f0c8f4e-8a4b-4f55-9d1e-7b1d8f9e0a11")
+	ctxB := newOwnedContext(c, "6d7e1c2a-3b4f-4e8d-a9c0-5f6e7d8c9b22")
+
+	a := namespace.New(testNamespace).WithNetworkPolicy("allow-a", networkingv1.NetworkPolicySpec{})
+	b := namespace.New(testNamespace).WithNetworkPolicy("allow-b", networkingv1.NetworkPolicySpec{})
+
+	require.NoError(t, a.Provision(ctxA))
+	require.NoError(t, b.Provision(ctxB))
+
+	require.NoError(t, c.Get(ctxA, client.ObjectKey{Namespace: testNamespace, Name: "allow-a"}, &networkingv1.NetworkPolicy{}))
+	require.NoError(t, c.Get(ctxB, client.ObjectKey{Namespace: testNamespace, Name: "allow-b"}, &networkingv1.NetworkPolicy{}))
+
+	require.NoError(t, b.Deprovision(ctxB))
+
+	require.NoError(t, c.Get(ctxA, client.ObjectKey{Namespace: testNamespace, Name: "allow-a"}, &networkingv1.NetworkPolicy{}))
+	require.True(t, kerrors.IsNotFound(c.Get(ctxB, client.ObjectKey{Namespace: testNamespace, Name: "allow-b"}, &networkingv1.NetworkPolicy{})))
+}
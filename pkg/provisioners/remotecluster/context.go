@@ -18,6 +18,7 @@ package remotecluster
 
 import (
 	"context"
+	"sync"
 )
 
 type key int
@@ -26,6 +27,10 @@ const (
 	// backgroundDeletionKey is used to propagate background deletion to
 	// all descendant provisioners in the call graph.
 	backgroundDeletionKey key = iota
+
+	// invocationKey is used to track remote cluster state across a single
+	// reconcile of the provisioner tree.
+	invocationKey
 )
 
 func NewContextWithBackgroundDeletion(ctx context.Context, backgroundDeletion bool) context.Context {
@@ -41,3 +46,71 @@ func BackgroundDeletionFromContext(ctx context.Context) bool {
 
 	return false
 }
+
+// remoteInvocation records what has happened to a single remote cluster during
+// a reconcile.
+type remoteInvocation struct {
+	// provisioned is set once registration of the remote cluster has been
+	// attempted.
+	provisioned bool
+
+	// provisionError is the result of registration, it is returned to all
+	// subsequent children so they don't run on a cluster that isn't ready.
+	provisionError error
+
+	// deprovisioned is the number of children that have been successfully
+	// deprovisioned.
+	deprovisioned int
+
+	// refCount is the number of children registered with the remote cluster
+	// when first encountered during this reconcile.
+	refCount int
+}
+
+// invocation tracks the state of all remote clusters during a reconcile.
+type invocation struct {
+	// lock provides synchronization around concurrency.
+	lock sync.Mutex
+
+	// remotes maps from a remote cluster to its state.
+	remotes map[*RemoteCluster]*remoteInvocation
+}
+
+// get returns the state for the remote cluster, the invocation lock must
+// be held.
+func (i *invocation) get(r *RemoteCluster) *remoteInvocation {
+	state, ok := i.remotes[r]
+	if !ok {
+		state = &remoteInvocation{
+			refCount: r.use(),
+		}
+
+		i.remotes[r] = state
+	}
+
+	return state
+}
+
+func newInvocation() *invocation {
+	return &invocation{
+		remotes: map[*RemoteCluster]*remoteInvocation{},
+	}
+}
+
+// NewContextWithInvocation should be called at the start of each reconcile in order
+// to track remote cluster registration and deregistration.  Provisioner trees may
+// either be built per reconcile or reused, and this state is scoped to the context
+// rather than the provisioners so will be correct regardless.  Without it, state
+// is kept on the remote cluster, so it must be created for each reconcile.
+func NewContextWithInvocation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, invocationKey, newInvocation())
+}
+
+// invocationFromContext returns the current invocation, or nil if not registered.
+func invocationFromContext(ctx context.Context) *invocation {
+	if i, ok := ctx.Value(invocationKey).(*invocation); ok {
+		return i
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remotecluster
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/unikorn-cloud/core/pkg/cd"
+	clientlib "github.com/unikorn-cloud/core/pkg/client"
+	"github.com/unikorn-cloud/core/pkg/errors"
+	"github.com/unikorn-cloud/core/pkg/provisioners"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+//nolint:gochecknoglobals
+var (
+	// DefaultSecretKeys are the keys searched for a Kubernetes configuration
+	// in a secret, in order, if none are explicitly specified.
+	DefaultSecretKeys = []string{"value", "kubeconfig", "config"}
+)
+
+// rewriteServer replaces the server address of the current context's cluster.
+// As the new address will typically not appear in the server certificate's SANs,
+// the TLS server name is set to the original host so verification still works.
+func rewriteServer(config *clientcmdapi.Config, server string) (*clientcmdapi.Config, error) {
+	if server == "" {
+		return config, nil
+	}
+
+	config = config.DeepCopy()
+
+	configContext := config.Contexts[config.CurrentContext]
+	if configContext == nil {
+		return nil, fmt.Errorf("%w: unable to lookup context", errors.ErrKubeconfig)
+	}
+
+	cluster := config.Clusters[configContext.Cluster]
+	if cluster == nil {
+		return nil, fmt.Errorf("%w: unable to lookup cluster", errors.ErrKubeconfig)
+	}
+
+	original, err := url.Parse(cluster.Server)
+	if err != nil {
+		return nil, err
+	}
+
+	if cluster.TLSServerName == "" {
+		cluster.TLSServerName = original.Hostname()
+	}
+
+	cluster.Server = server
+
+	return config, nil
+}
+
+// SecretGenerator reads a Kubernetes configuration from a secret on the cluster
+// in scope e.g. the host cluster, or a parent remote cluster.
+type SecretGenerator struct {
+	// id is the unique remote cluster ID.
+	id *cd.ResourceIdentifier
+
+	// namespace is where the secret lives.
+	namespace string
+
+	// name is the secret name.
+	name string
+
+	// keys are searched in order for the configuration.
+	keys []string
+
+	// server, if set, overrides the server address.
+	server string
+}
+
+// Ensure the RemoteCluster interface is implemented.
+var _ provisioners.RemoteCluster = &SecretGenerator{}
+
+// NewSecretGenerator returns a generator that reads a Kubernetes configuration from
+// the named secret.
+func NewSecretGenerator(id *cd.ResourceIdentifier, namespace, name string) *SecretGenerator {
+	return &SecretGenerator{
+		id:        id,
+		namespace: namespace,
+		name:      name,
+		keys:      DefaultSecretKeys,
+	}
+}
+
+// NewCAPIGenerator returns a generator that reads a Kubernetes configuration from a
+// Cluster API cluster's secret.
+func NewCAPIGenerator(id *cd.ResourceIdentifier, namespace, clusterName string) *SecretGenerator {
+	return NewSecretGenerator(id, namespace, clusterName+"-kubeconfig").WithKeys("value")
+}
+
+// WithKeys sets the keys that are searched, in order, for the configuration.
+func (g *SecretGenerator) WithKeys(keys ...string) *SecretGenerator {
+	g.keys = keys
+
+	return g
+}
+
+// WithServer overrides the server address e.g. when the management cluster must
+// use an internal endpoint rather than that published in the configuration.
+func (g *SecretGenerator) WithServer(server string) *SecretGenerator {
+	g.server = server
+
+	return g
+}
+
+// ID implements the provisioners.RemoteCluster interface.
+func (g *SecretGenerator) ID() *cd.ResourceIdentifier {
+	return g.id
+}
+
+// Config implements the provisioners.RemoteCluster interface.
+func (g *SecretGenerator) Config(ctx context.Context) (*clientcmdapi.Config, error) {
+	log := log.FromContext(ctx)
+
+	clusterContext, err := clientlib.ClusterFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var secret corev1.Secret
+
+	if err := clusterContext.Client.Get(ctx, client.ObjectKey{Namespace: g.namespace, Name: g.name}, &secret); err != nil {
+		if kerrors.IsNotFound(err) {
+			log.Info("kubernetes config secret does not exist, yielding", "namespace", g.namespace, "name", g.name)
+
+			return nil, provisioners.ErrYield
+		}
+
+		return nil, err
+	}
+
+	for _, key := range g.keys {
+		data, ok := secret.Data[key]
+		if !ok {
+			continue
+		}
+
+		config, err := clientcmd.Load(data)
+		if err != nil {
+			return nil, err
+		}
+
+		return rewriteServer(config, g.server)
+	}
+
+	return nil, fmt.Errorf("%w: secret %s/%s contains none of the keys %v", errors.ErrSecretFormatError, g.namespace, g.name, g.keys)
+}
+
+// StaticGenerator uses a fixed Kubernetes configuration.
+type StaticGenerator struct {
+	// id is the unique remote cluster ID.
+	id *cd.ResourceIdentifier
+
+	// config is the Kubernetes configuration.
+	config *clientcmdapi.Config
+
+	// server, if set, overrides the server address.
+	server string
+}
+
+// Ensure the RemoteCluster interface is implemented.
+var _ provisioners.RemoteCluster = &StaticGenerator{}
+
+// NewStaticGenerator returns a generator with a fixed configuration e.g. one
+// loaded from a file.
+func NewStaticGenerator(id *cd.ResourceIdentifier, config *clientcmdapi.Config) *StaticGenerator {
+	return &StaticGenerator{
+		id:     id,
+		config: config,
+	}
+}
+
+// WithServer overrides the server address e.g. when the management cluster must
+// use an internal endpoint rather than that published in the configuration.
+func (g *StaticGenerator) WithServer(server string) *StaticGenerator {
+	g.server = server
+
+	return g
+}
+
+// ID implements the provisioners.RemoteCluster interface.
+func (g *StaticGenerator) ID() *cd.ResourceIdentifier {
+	return g.id
+}
+
+// Config implements the provisioners.RemoteCluster interface.
+func (g *StaticGenerator) Config(_ context.Context) (*clientcmdapi.Config, error) {
+	return rewriteServer(g.config, g.server)
+}
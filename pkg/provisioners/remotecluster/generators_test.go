@@ -0,0 +1,115 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remotecluster_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unikorn-cloud/core/pkg/cd"
+	clientlib "github.com/unikorn-cloud/core/pkg/client"
+	"github.com/unikorn-cloud/core/pkg/errors"
+	"github.com/unikorn-cloud/core/pkg/provisioners"
+	"github.com/unikorn-cloud/core/pkg/provisioners/remotecluster"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testNamespace = "foo"
+)
+
+func newSecret(t *testing.T, name, key string) *corev1.Secret {
+	t.Helper()
+
+	data, err := clientcmd.Write(*newConfig())
+	require.NoError(t, err)
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      name,
+		},
+		Data: map[string][]byte{
+			key: data,
+		},
+	}
+}
+
+func newClusterContext(objects ...client.Object) context.Context {
+	c := fake.NewClientBuilder().WithObjects(objects...).Build()
+
+	return clientlib.NewContextWithCluster(context.Background(), &clientlib.ClusterContext{Client: c})
+}
+
+// TestSecretGeneratorYield tests the generator yields until the secret exists.
+func TestSecretGeneratorYield(t *testing.T) {
+	t.Parallel()
+
+	generator := remotecluster.NewSecretGenerator(&cd.ResourceIdentifier{Name: "test"}, testNamespace, "missing")
+
+	_, err := generator.Config(newClusterContext())
+	assert.ErrorIs(t, err, provisioners.ErrYield)
+}
+
+// TestSecretGeneratorKeys tests keys are searched for the configuration.
+func TestSecretGeneratorKeys(t *testing.T) {
+	t.Parallel()
+
+	ctx := newClusterContext(newSecret(t, "test", "kubeconfig"))
+
+	config, err := remotecluster.NewSecretGenerator(&cd.ResourceIdentifier{Name: "test"}, testNamespace, "test").Config(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "https://127.0.0.1:1", config.Clusters["test"].Server)
+
+	_, err = remotecluster.NewSecretGenerator(&cd.ResourceIdentifier{Name: "test"}, testNamespace, "test").WithKeys("other").Config(ctx)
+	assert.ErrorIs(t, err, errors.ErrSecretFormatError)
+}
+
+// TestCAPIGenerator tests Cluster API conventions are followed, and the server
+// can be rewritten.
+func TestCAPIGenerator(t *testing.T) {
+	t.Parallel()
+
+	ctx := newClusterContext(newSecret(t, "cluster-kubeconfig", "value"))
+
+	config, err := remotecluster.NewCAPIGenerator(&cd.ResourceIdentifier{Name: "test"}, testNamespace, "cluster").WithServer("https://internal:6443").Config(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "https://internal:6443", config.Clusters["test"].Server)
+	assert.Equal(t, "127.0.0.1", config.Clusters["test"].TLSServerName)
+}
+
+// TestStaticGenerator tests static configuration is returned, and rewriting the
+// server doesn't modify the original.
+func TestStaticGenerator(t *testing.T) {
+	t.Parallel()
+
+	original := newConfig()
+
+	config, err := remotecluster.NewStaticGenerator(&cd.ResourceIdentifier{Name: "test"}, original).WithServer("https://internal:6443").Config(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "https://internal:6443", config.Clusters["test"].Server)
+	assert.Equal(t, "https://127.0.0.1:1", original.Clusters["test"].Server)
+}
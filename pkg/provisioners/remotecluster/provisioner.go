@@ -30,7 +30,7 @@ import (
 	"github.com/unikorn-cloud/core/pkg/errors"
 	"github.com/unikorn-cloud/core/pkg/provisioners"
 
-	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/rest"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -46,14 +46,25 @@ type RemoteCluster struct {
 	// controller tells whether we "own" this resource or not.
 	controller bool
 
-	// lock provides synchronization around concurrrency.
+	// lock provides synchronization around registration.
 	lock sync.Mutex
 
-	// refCount tells us how many remote provisioners have been registered.
+	// refCount tells us how many remote provisioners have been registered
+	// in the current provisioner tree.  Per-reconcile state is tracked via
+	// the context, see NewContextWithInvocation.
 	refCount int
 
-	// currentCount tells us how many times remote provisioners have been called.
-	currentCount int
+	// used is set once the provisioner tree has been run.  Any subsequent
+	// registration is assumed to be for a rebuilt tree, so starts counting
+	// afresh rather than accumulating across reconciles.
+	used bool
+
+	// fallback tracks state when the context has no invocation.
+	fallback *invocation
+
+	// fallbackSeen records which provisioners have used the fallback, when one
+	// is seen again the tree is being rerun, so state is reset.
+	fallbackSeen map[*remoteClusterProvisioner]bool
 }
 
 // New returns a new initialized provisioner object.
@@ -99,32 +110,50 @@ func BackgroundDeletion(p *remoteClusterProvisioner) {
 // GetClient gets a client from the remote generator.
 // NOTE: this must only be called in Provision/Deprovision so it
 // respects the context we are in as regards nested remotes.
-func (r *RemoteCluster) getClient(ctx context.Context) (client.Client, *clientcmdapi.Config, error) {
+func (r *RemoteCluster) getClient(ctx context.Context) (client.Client, *rest.Config, *clientcmdapi.Config, error) {
 	config, err := r.generator.Config(ctx)
 	if err != nil {
-		return nil, nil, err
-	}
-
-	getter := func() (*clientcmdapi.Config, error) {
-		return config, nil
+		return nil, nil, nil, err
 	}
 
-	restConfig, err := clientcmd.BuildConfigFromKubeconfigGetter("", getter)
+	// Clients are expensive to create, so reuse them across reconciles.
+	client, restConfig, err := clientlib.DefaultRemoteClientCache().Get(config)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
-	client, err := client.New(restConfig, client.Options{})
+	return client, restConfig, config, nil
+}
+
+// Client returns a client for the remote cluster without registering it with
+// the CD driver.  As with getClient, this must only be called in Provision/Deprovision.
+func (r *RemoteCluster) Client(ctx context.Context) (client.Client, error) {
+	client, _, _, err := r.getClient(ctx)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	return client, config, nil
+	return client, nil
+}
+
+// ID returns the unique remote cluster ID as consumed by the CD layer.
+func (r *RemoteCluster) ID() *cd.ResourceIdentifier {
+	return r.generator.ID()
 }
 
 // ProvisionOn returns a provisioner that will provision the remote,
 // and provision the child provisioner on that remote.
 func (r *RemoteCluster) ProvisionOn(child provisioners.Provisioner, options ...ProvisionerOption) provisioners.Provisioner {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.used {
+		r.refCount = 0
+		r.used = false
+		r.fallback = nil
+		r.fallbackSeen = nil
+	}
+
 	r.refCount++
 
 	provisioner := &remoteClusterProvisioner{
@@ -142,38 +171,112 @@ func (r *RemoteCluster) ProvisionOn(child provisioners.Provisioner, options ...P
 	return provisioner
 }
 
-func (p *remoteClusterProvisioner) provisionRemote(ctx context.Context) error {
-	log := log.FromContext(ctx)
+// use marks the provisioner tree as having been run, and returns the number of
+// remote provisioners registered in it.
+func (r *RemoteCluster) use() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.used = true
+
+	return r.refCount
+}
+
+// invocation returns the current invocation from the context.  If one is not
+// registered, as may be the case when not run by the manager, then state is kept
+// on the remote cluster, and reset when a provisioner is run for a second time,
+// as that indicates a new pass over the tree.
+func (r *RemoteCluster) invocation(ctx context.Context, p *remoteClusterProvisioner) *invocation {
+	if i := invocationFromContext(ctx); i != nil {
+		return i
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.fallback == nil || r.fallbackSeen[p] {
+		r.fallback = newInvocation()
+		r.fallbackSeen = map[*remoteClusterProvisioner]bool{}
+	}
+
+	r.fallbackSeen[p] = true
+
+	return r.fallback
+}
 
-	p.remote.lock.Lock()
-	defer p.remote.lock.Unlock()
+func (p *remoteClusterProvisioner) provisionRemote(ctx context.Context, invocation *invocation) error {
+	// Holding the lock means concurrent children will wait for registration
+	// to complete before continuing.
+	invocation.lock.Lock()
+	defer invocation.lock.Unlock()
 
-	p.remote.currentCount++
+	state := invocation.get(p.remote)
+
+	// If this is the first child encountered during this reconcile, then register
+	// the cluster, otherwise return the result of that registration.
+	if !p.remote.controller || state.provisioned {
+		return state.provisionError
+	}
+
+	state.provisioned = true
+	state.provisionError = p.registerRemote(ctx)
+
+	return state.provisionError
+}
+
+func (p *remoteClusterProvisioner) registerRemote(ctx context.Context) error {
+	log := log.FromContext(ctx)
 
 	id := p.remote.generator.ID()
 
-	// If this is the first remote cluster encountered, reconcile it.
-	if p.remote.controller && p.remote.currentCount == 1 {
-		log.Info("provisioning remote cluster", "remotecluster", id)
+	log.Info("provisioning remote cluster", "remotecluster", id)
 
-		config, err := p.remote.generator.Config(ctx)
-		if err != nil {
-			return err
-		}
+	config, err := p.remote.generator.Config(ctx)
+	if err != nil {
+		return err
+	}
 
-		cluster := &cd.Cluster{
-			Config: config,
-		}
+	cluster := &cd.Cluster{
+		Config: config,
+	}
 
-		if err := cd.FromContext(ctx).CreateOrUpdateCluster(ctx, id, cluster); err != nil {
-			log.Info("remote cluster not ready, yielding", "remotecluster", id)
+	if err := cd.FromContext(ctx).CreateOrUpdateCluster(ctx, id, cluster); err != nil {
+		log.Info("remote cluster not ready, yielding", "remotecluster", id)
 
-			return provisioners.ErrYield
-		}
+		return provisioners.ErrYield
+	}
 
-		log.Info("remote cluster provisioned", "remotecluster", id)
+	log.Info("remote cluster provisioned", "remotecluster", id)
+
+	return nil
+}
+
+// deprovisionRemote is called when a child has successfully deprovisioned.
+// Only once all children have done so is the remote cluster deleted.
+func (p *remoteClusterProvisioner) deprovisionRemote(ctx context.Context, invocation *invocation) error {
+	log := log.FromContext(ctx)
+
+	invocation.lock.Lock()
+	defer invocation.lock.Unlock()
+
+	state := invocation.get(p.remote)
+
+	state.deprovisioned++
+
+	if !p.remote.controller || state.deprovisioned != state.refCount {
+		return nil
 	}
 
+	id := p.remote.generator.ID()
+
+	log.Info("deprovisioning remote cluster", "remotecluster", id)
+
+	if err := cd.FromContext(ctx).DeleteCluster(ctx, id); err != nil {
+		return err
+	}
+
+	log.Info("remote cluster deprovisioned", "remotecluster", id)
+
 	return nil
 }
 
@@ -207,35 +310,56 @@ func getHostPort(url *url.URL) (string, string) {
 	return host, port
 }
 
+// getClusterContext returns the cluster context for the remote cluster.
+func (r *RemoteCluster) getClusterContext(client client.Client, restConfig *rest.Config, config *clientcmdapi.Config) (*clientlib.ClusterContext, error) {
+	url, err := getKuebernetesURL(config)
+	if err != nil {
+		return nil, err
+	}
+
+	host, port := getHostPort(url)
+
+	clusterContext := &clientlib.ClusterContext{
+		Client:     client,
+		RESTConfig: restConfig,
+		ID:         r.generator.ID(),
+		Host:       host,
+		Port:       port,
+	}
+
+	return clusterContext, nil
+}
+
 // Provision implements the Provision interface.
 func (p *remoteClusterProvisioner) Provision(ctx context.Context) error {
-	if err := p.provisionRemote(ctx); err != nil {
+	if err := p.provisionRemote(ctx, p.remote.invocation(ctx, p)); err != nil {
 		return err
 	}
 
-	client, config, err := p.remote.getClient(ctx)
+	client, restConfig, config, err := p.remote.getClient(ctx)
 	if err != nil {
 		return err
 	}
 
-	url, err := getKuebernetesURL(config)
+	clusterContext, err := p.remote.getClusterContext(client, restConfig, config)
 	if err != nil {
 		return err
 	}
 
-	host, port := getHostPort(url)
-
-	clusterContext := &clientlib.ClusterContext{
-		Client: client,
-		ID:     p.remote.generator.ID(),
-		Host:   host,
-		Port:   port,
+	// Generators may use this to determine what to install.  Discovery is best
+	// effort, aggregated APIs that are unavailable should not block provisioning,
+	// and generators must cope with it being missing.
+	discovery, err := clientlib.DefaultRemoteClientCache().Discover(ctx, config)
+	if err != nil {
+		log.FromContext(ctx).Info("remote cluster discovery failed", "error", err)
 	}
 
+	clusterContext.Discovery = discovery
+
 	ctx = clientlib.NewContextWithCluster(ctx, clusterContext)
 
 	// Remote is registered, create the remote applications.
-	if err := p.child.Provision(ctx); err != nil {
+	if err := provisioners.Provision(ctx, p.child); err != nil {
 		return err
 	}
 
@@ -244,14 +368,14 @@ func (p *remoteClusterProvisioner) Provision(ctx context.Context) error {
 
 // Deprovision implements the Provision interface.
 func (p *remoteClusterProvisioner) Deprovision(ctx context.Context) error {
-	log := log.FromContext(ctx)
+	invocation := p.remote.invocation(ctx, p)
 
 	// If the client cannot be instantiated due to a yield error, then
 	// assume the client config is gone, and the child deprovisioning
 	// has completed successfully.
 	deprovisioned := false
 
-	client, config, err := p.remote.getClient(ctx)
+	client, restConfig, config, err := p.remote.getClient(ctx)
 	if err != nil {
 		if !goerrors.Is(err, provisioners.ErrYield) {
 			return err
@@ -261,52 +385,25 @@ func (p *remoteClusterProvisioner) Deprovision(ctx context.Context) error {
 	}
 
 	if !deprovisioned {
-		url, err := getKuebernetesURL(config)
+		clusterContext, err := p.remote.getClusterContext(client, restConfig, config)
 		if err != nil {
 			return err
 		}
 
-		host, port := getHostPort(url)
-
-		clusterContext := &clientlib.ClusterContext{
-			Client: client,
-			ID:     p.remote.generator.ID(),
-			Host:   host,
-			Port:   port,
-		}
-
+		// Discovery is skipped on deletion, it's not needed to remove things,
+		// and a broken cluster would delay its own removal.
 		ctx = clientlib.NewContextWithCluster(ctx, clusterContext)
 
 		if p.backgroundDeletion {
 			ctx = NewContextWithBackgroundDeletion(ctx, true)
 		}
 
-		if err := p.child.Deprovision(ctx); err != nil {
+		if err := provisioners.Deprovision(ctx, p.child); err != nil {
 			return err
 		}
 	}
 
-	// Once all concurrent remote provisioner have done there stuff
-	// they will wait on the lock...
-	p.remote.lock.Lock()
-	defer p.remote.lock.Unlock()
-
-	// ... adding themselves to the total...
-	p.remote.currentCount++
-
-	id := p.remote.generator.ID()
-
-	// ... and if all have completed without an error, then deprovision the
-	// remote cluster itself.
-	if p.remote.controller && p.remote.currentCount == p.remote.refCount {
-		log.Info("deprovisioning remote cluster", "remotecluster", id)
-
-		if err := cd.FromContext(ctx).DeleteCluster(ctx, id); err != nil {
-			return err
-		}
-
-		log.Info("remote cluster deprovisioned", "remotecluster", id)
-	}
-
-	return nil
+	// Children that error or yield are not counted, so the remote cluster will
+	// only be deleted on a reconcile where every child deprovisioned successfully.
+	return p.deprovisionRemote(ctx, invocation)
 }
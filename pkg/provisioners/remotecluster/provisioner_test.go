@@ -0,0 +1,300 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remotecluster_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/unikorn-cloud/core/pkg/cd"
+	mockcd "github.com/unikorn-cloud/core/pkg/cd/mock"
+	"github.com/unikorn-cloud/core/pkg/provisioners"
+	mockprovisioners "github.com/unikorn-cloud/core/pkg/provisioners/mock"
+	"github.com/unikorn-cloud/core/pkg/provisioners/remotecluster"
+
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+var (
+	errChild = errors.New("child error")
+	errCD    = errors.New("cd error")
+)
+
+// newConfig returns a configuration for a cluster that doesn't exist, discovery
+// will fail, but that is tolerated.
+func newConfig() *clientcmdapi.Config {
+	return &clientcmdapi.Config{
+		Clusters: map[string]*clientcmdapi.Cluster{
+			"test": {
+				Server: "https://127.0.0.1:1",
+			},
+		},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{
+			"test": {},
+		},
+		Contexts: map[string]*clientcmdapi.Context{
+			"test": {
+				Cluster:  "test",
+				AuthInfo: "test",
+			},
+		},
+		CurrentContext: "test",
+	}
+}
+
+func newGenerator(c *gomock.Controller, config *clientcmdapi.Config, err error) *mockprovisioners.MockRemoteCluster {
+	generator := mockprovisioners.NewMockRemoteCluster(c)
+	generator.EXPECT().ID().Return(&cd.ResourceIdentifier{Name: "test"}).AnyTimes()
+	generator.EXPECT().Config(gomock.Any()).Return(config, err).AnyTimes()
+
+	return generator
+}
+
+func newContext(driver cd.Driver) context.Context {
+	return remotecluster.NewContextWithInvocation(cd.NewContext(context.Background(), driver))
+}
+
+// TestProvisionYield tests that when registration yields, all children yield
+// without being invoked, and that registration is retried on the next reconcile.
+func TestProvisionYield(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	driver := mockcd.NewMockDriver(c)
+	driver.EXPECT().CreateOrUpdateCluster(gomock.Any(), gomock.Any(), gomock.Any()).Return(errCD).Times(2)
+
+	remote := remotecluster.New(newGenerator(c, newConfig(), nil), true)
+
+	p1 := remote.ProvisionOn(mockprovisioners.NewMockProvisioner(c))
+	p2 := remote.ProvisionOn(mockprovisioners.NewMockProvisioner(c))
+
+	for range 2 {
+		ctx := newContext(driver)
+
+		assert.ErrorIs(t, p1.Provision(ctx), provisioners.ErrYield)
+		assert.ErrorIs(t, p2.Provision(ctx), provisioners.ErrYield)
+	}
+}
+
+// TestProvisionDiscoveryFailure tests that children are still provisioned when
+// the remote cluster cannot be discovered.
+func TestProvisionDiscoveryFailure(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	driver := mockcd.NewMockDriver(c)
+	driver.EXPECT().CreateOrUpdateCluster(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	remote := remotecluster.New(newGenerator(c, newConfig(), nil), true)
+
+	child := mockprovisioners.NewMockProvisioner(c)
+	child.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	child.EXPECT().Provision(gomock.Any()).Return(nil)
+
+	assert.NoError(t, remote.ProvisionOn(child).Provision(newContext(driver)))
+}
+
+// TestDeprovisionPartialFailure tests the remote cluster isn't deleted if any child
+// fails, and is deleted on a subsequent reconcile when all succeed.
+func TestDeprovisionPartialFailure(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	driver := mockcd.NewMockDriver(c)
+
+	remote := remotecluster.New(newGenerator(c, newConfig(), nil), true)
+
+	child1 := mockprovisioners.NewMockProvisioner(c)
+	child1.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	child2 := mockprovisioners.NewMockProvisioner(c)
+	child2.EXPECT().ProvisionerName().Return("test").AnyTimes()
+
+	p1 := remote.ProvisionOn(child1)
+	p2 := remote.ProvisionOn(child2)
+
+	// First reconcile, one child fails, so the cluster is not deleted.
+	child1.EXPECT().Deprovision(gomock.Any()).Return(nil)
+	child2.EXPECT().Deprovision(gomock.Any()).Return(errChild)
+
+	ctx := newContext(driver)
+
+	assert.NoError(t, p1.Deprovision(ctx))
+	assert.ErrorIs(t, p2.Deprovision(ctx), errChild)
+
+	// Second reconcile, both succeed, so the cluster is deleted.
+	child1.EXPECT().Deprovision(gomock.Any()).Return(nil)
+	child2.EXPECT().Deprovision(gomock.Any()).Return(nil)
+	driver.EXPECT().DeleteCluster(gomock.Any(), gomock.Any()).Return(nil)
+
+	ctx = newContext(driver)
+
+	assert.NoError(t, p1.Deprovision(ctx))
+	assert.NoError(t, p2.Deprovision(ctx))
+}
+
+// TestDeprovisionYield tests the remote cluster isn't deleted while a child is
+// yielding.
+func TestDeprovisionYield(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	driver := mockcd.NewMockDriver(c)
+
+	remote := remotecluster.New(newGenerator(c, newConfig(), nil), true)
+
+	child1 := mockprovisioners.NewMockProvisioner(c)
+	child1.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	child1.EXPECT().Deprovision(gomock.Any()).Return(provisioners.ErrYield)
+
+	child2 := mockprovisioners.NewMockProvisioner(c)
+	child2.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	child2.EXPECT().Deprovision(gomock.Any()).Return(nil)
+
+	p1 := remote.ProvisionOn(child1)
+	p2 := remote.ProvisionOn(child2)
+
+	ctx := newContext(driver)
+
+	assert.ErrorIs(t, p1.Deprovision(ctx), provisioners.ErrYield)
+	assert.NoError(t, p2.Deprovision(ctx))
+}
+
+// TestDeprovisionConfigGone tests that when the remote cluster configuration has
+// gone, children are considered deprovisioned and the cluster deleted.
+func TestDeprovisionConfigGone(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	driver := mockcd.NewMockDriver(c)
+	driver.EXPECT().DeleteCluster(gomock.Any(), gomock.Any()).Return(nil)
+
+	remote := remotecluster.New(newGenerator(c, nil, provisioners.ErrYield), true)
+
+	p1 := remote.ProvisionOn(mockprovisioners.NewMockProvisioner(c))
+	p2 := remote.ProvisionOn(mockprovisioners.NewMockProvisioner(c))
+
+	ctx := newContext(driver)
+
+	assert.NoError(t, p1.Deprovision(ctx))
+	assert.NoError(t, p2.Deprovision(ctx))
+}
+
+// TestDeprovisionNotController tests the remote cluster is never deleted when
+// not owned by this provisioner.
+func TestDeprovisionNotController(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	driver := mockcd.NewMockDriver(c)
+
+	remote := remotecluster.New(newGenerator(c, nil, provisioners.ErrYield), false)
+
+	p := remote.ProvisionOn(mockprovisioners.NewMockProvisioner(c))
+
+	assert.NoError(t, p.Deprovision(newContext(driver)))
+}
+
+// TestDeprovisionRebuiltTree tests the remote cluster is deleted when it is reused
+// across reconciles, but the provisioner tree is rebuilt each time.
+func TestDeprovisionRebuiltTree(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	driver := mockcd.NewMockDriver(c)
+	driver.EXPECT().DeleteCluster(gomock.Any(), gomock.Any()).Return(nil)
+
+	remote := remotecluster.New(newGenerator(c, nil, provisioners.ErrYield), true)
+
+	for range 3 {
+		// Only the first child is run until the final reconcile.
+		p := remote.ProvisionOn(mockprovisioners.NewMockProvisioner(c))
+		remote.ProvisionOn(mockprovisioners.NewMockProvisioner(c))
+
+		assert.NoError(t, p.Deprovision(newContext(driver)))
+	}
+
+	p1 := remote.ProvisionOn(mockprovisioners.NewMockProvisioner(c))
+	p2 := remote.ProvisionOn(mockprovisioners.NewMockProvisioner(c))
+
+	ctx := newContext(driver)
+
+	assert.NoError(t, p1.Deprovision(ctx))
+	assert.NoError(t, p2.Deprovision(ctx))
+}
+
+// TestNoInvocation tests that, without an invocation, state is tracked across a
+// single pass of the provisioner tree, and reset when it is run again.
+func TestNoInvocation(t *testing.T) {
+	t.Parallel()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	driver := mockcd.NewMockDriver(c)
+
+	remote := remotecluster.New(newGenerator(c, newConfig(), nil), true)
+
+	child1 := mockprovisioners.NewMockProvisioner(c)
+	child1.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	child2 := mockprovisioners.NewMockProvisioner(c)
+	child2.EXPECT().ProvisionerName().Return("test").AnyTimes()
+
+	p1 := remote.ProvisionOn(child1)
+	p2 := remote.ProvisionOn(child2)
+
+	ctx := cd.NewContext(context.Background(), driver)
+
+	// First pass, the cluster is registered once, but children fail.
+	driver.EXPECT().CreateOrUpdateCluster(gomock.Any(), gomock.Any(), gomock.Any()).Return(errCD)
+
+	assert.ErrorIs(t, p1.Provision(ctx), provisioners.ErrYield)
+	assert.ErrorIs(t, p2.Provision(ctx), provisioners.ErrYield)
+
+	// Second pass, one child fails to deprovision, so the cluster is not deleted.
+	child1.EXPECT().Deprovision(gomock.Any()).Return(nil)
+	child2.EXPECT().Deprovision(gomock.Any()).Return(errChild)
+
+	assert.NoError(t, p1.Deprovision(ctx))
+	assert.ErrorIs(t, p2.Deprovision(ctx), errChild)
+
+	// Third pass, only counts this pass, so the first child alone doesn't trigger
+	// deletion, but both together do.
+	child1.EXPECT().Deprovision(gomock.Any()).Return(nil)
+	child2.EXPECT().Deprovision(gomock.Any()).Return(nil)
+	driver.EXPECT().DeleteCluster(gomock.Any(), gomock.Any()).Return(nil)
+
+	assert.NoError(t, p1.Deprovision(ctx))
+	assert.NoError(t, p2.Deprovision(ctx))
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replicate
+
+import (
+	"context"
+	"strings"
+
+	"github.com/unikorn-cloud/core/pkg/cd"
+	clientlib "github.com/unikorn-cloud/core/pkg/client"
+	"github.com/unikorn-cloud/core/pkg/provisioners/remotecluster"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// hostCluster identifies the cluster the controller is running in.
+	hostCluster = "host"
+)
+
+// Endpoint identifies one side of a replication.  Endpoints are resolved when
+// provisioning so they respect the current cluster context.
+type Endpoint interface {
+	// Client returns a client for the cluster.
+	Client(ctx context.Context) (client.Client, error)
+
+	// ID returns a unique identifier for the cluster.  This doesn't require
+	// the cluster to be accessible.
+	ID(ctx context.Context) (string, error)
+}
+
+// clusterID returns a unique identifier for a cluster.
+func clusterID(id *cd.ResourceIdentifier) string {
+	if id == nil {
+		return hostCluster
+	}
+
+	parts := []string{id.Name}
+
+	for _, label := range id.Labels {
+		parts = append(parts, label.Name+"="+label.Value)
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// uncachedClient reads directly from the API, rather than via a cache, so that
+// reading a resource doesn't start an informer for its type.  Writes are
+// always uncached.
+type uncachedClient struct {
+	client.Client
+
+	reader client.Reader
+}
+
+func (c *uncachedClient) Get(ctx context.Context, key client.ObjectKey, object client.Object, options ...client.GetOption) error {
+	return c.reader.Get(ctx, key, object, options...)
+}
+
+func (c *uncachedClient) List(ctx context.Context, list client.ObjectList, options ...client.ListOption) error {
+	return c.reader.List(ctx, list, options...)
+}
+
+// hostClient returns a client for the host cluster, preferring the uncached
+// reader where one is available.
+func hostClient(ctx context.Context, cli client.Client) client.Client {
+	reader, err := clientlib.ProvisionerReaderFromContext(ctx)
+	if err != nil {
+		return cli
+	}
+
+	return &uncachedClient{
+		Client: cli,
+		reader: reader,
+	}
+}
+
+type currentEndpoint struct{}
+
+// Current is the cluster currently in scope, which will be a remote cluster when
+// the provisioner is run under remotecluster.ProvisionOn.
+func Current() Endpoint {
+	return currentEndpoint{}
+}
+
+func (currentEndpoint) Client(ctx context.Context) (client.Client, error) {
+	cluster, err := clientlib.ClusterFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return cluster.Client, nil
+}
+
+func (currentEndpoint) ID(ctx context.Context) (string, error) {
+	cluster, err := clientlib.ClusterFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return clusterID(cluster.ID), nil
+}
+
+type hostEndpoint struct{}
+
+// Host is the cluster the controller is running in, regardless of any remote
+// cluster scoping.  Reads bypass the controller's cache, so replicating doesn't
+// start cluster wide informers for secrets or config maps.
+func Host() Endpoint {
+	return hostEndpoint{}
+}
+
+func (hostEndpoint) Client(ctx context.Context) (client.Client, error) {
+	cli, err := clientlib.ProvisionerClientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return hostClient(ctx, cli), nil
+}
+
+func (hostEndpoint) ID(_ context.Context) (string, error) {
+	return hostCluster, nil
+}
+
+type remoteEndpoint struct {
+	remote *remotecluster.RemoteCluster
+}
+
+// Remote is a remote cluster, this allows copying directly between clusters
+// without having to descend into either of them.
+func Remote(remote *remotecluster.RemoteCluster) Endpoint {
+	return &remoteEndpoint{
+		remote: remote,
+	}
+}
+
+func (e *remoteEndpoint) Client(ctx context.Context) (client.Client, error) {
+	return e.remote.Client(ctx)
+}
+
+func (e *remoteEndpoint) ID(_ context.Context) (string, error) {
+	return clusterID(e.remote.ID()), nil
+}
@@ -0,0 +1,397 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replicate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/unikorn-cloud/core/pkg/constants"
+	"github.com/unikorn-cloud/core/pkg/provisioners"
+	"github.com/unikorn-cloud/core/pkg/provisioners/application"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var (
+	// ErrKeyNotFound is raised when a requested key isn't in the source.
+	ErrKeyNotFound = errors.New("key not found")
+
+	// ErrNotOwned is raised when the destination exists but wasn't created
+	// by this provisioner.
+	ErrNotOwned = errors.New("destination not owned by replication")
+)
+
+// Provisioner copies a secret or config map from a source cluster to a destination,
+// keeping the copy in sync on every reconcile, and deleting it on deprovision.
+// Copies are annotated with their source cluster and object, and the resource
+// that owns them, and existing resources without matching annotations will not
+// be overwritten or deleted.
+type Provisioner struct {
+	provisioners.Metadata
+
+	// newObject returns an empty object of the type being replicated.
+	newObject func() client.Object
+
+	// source is where to copy from.
+	source Endpoint
+
+	// sourceKey identifies the source object.
+	sourceKey client.ObjectKey
+
+	// destination is where to copy to.
+	destination Endpoint
+
+	// destinationKey identifies the destination object.
+	destinationKey client.ObjectKey
+
+	// keys, if set, limits replication to the specified keys.
+	keys []string
+
+	// renames maps source keys to destination keys.
+	renames map[string]string
+}
+
+// Ensure the Provisioner interface is implemented.
+var _ provisioners.Provisioner = &Provisioner{}
+
+func newProvisioner(name string, newObject func() client.Object, source Endpoint, from client.ObjectKey, destination Endpoint, to client.ObjectKey) *Provisioner {
+	return &Provisioner{
+		Metadata: provisioners.Metadata{
+			Name: name,
+		},
+		newObject:      newObject,
+		source:         source,
+		sourceKey:      from,
+		destination:    destination,
+		destinationKey: to,
+		renames:        map[string]string{},
+	}
+}
+
+// NewSecret returns a provisioner that replicates a secret.
+func NewSecret(name string, source Endpoint, from client.ObjectKey, destination Endpoint, to client.ObjectKey) *Provisioner {
+	return newProvisioner(name, func() client.Object { return &corev1.Secret{} }, source, from, destination, to)
+}
+
+// NewConfigMap returns a provisioner that replicates a config map.
+func NewConfigMap(name string, source Endpoint, from client.ObjectKey, destination Endpoint, to client.ObjectKey) *Provisioner {
+	return newProvisioner(name, func() client.Object { return &corev1.ConfigMap{} }, source, from, destination, to)
+}
+
+// WithKeys limits replication to the specified keys, all of which must exist in
+// the source.  By default all keys are replicated.
+func (p *Provisioner) WithKeys(keys ...string) *Provisioner {
+	p.keys = keys
+
+	return p
+}
+
+// WithRename renames a source key in the destination.
+func (p *Provisioner) WithRename(from, to string) *Provisioner {
+	p.renames[from] = to
+
+	return p
+}
+
+// sourceID uniquely identifies the source for ownership checks.
+func (p *Provisioner) sourceID(ctx context.Context) (string, error) {
+	cluster, err := p.source.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return cluster + "/" + p.sourceKey.String(), nil
+}
+
+// owner returns the UID of the resource being reconciled, if known.
+func owner(ctx context.Context) string {
+	resource, err := application.ResourceFromContext(ctx)
+	if err != nil {
+		return ""
+	}
+
+	return string(resource.GetUID())
+}
+
+// annotations returns the ownership annotations for the destination.
+func annotations(sourceID, owner string) map[string]string {
+	annotations := map[string]string{
+		constants.ReplicationSourceAnnotation: sourceID,
+	}
+
+	if owner != "" {
+		annotations[constants.ReplicationOwnerAnnotation] = owner
+	}
+
+	return annotations
+}
+
+// mapKeys applies key filtering and renaming.
+func mapKeys[T any](p *Provisioner, in map[string]T) map[string]T {
+	if len(in) == 0 {
+		return nil
+	}
+
+	out := map[string]T{}
+
+	for key, value := range in {
+		if p.keys != nil && !slices.Contains(p.keys, key) {
+			continue
+		}
+
+		if renamed, ok := p.renames[key]; ok {
+			key = renamed
+		}
+
+		out[key] = value
+	}
+
+	if len(out) == 0 {
+		return nil
+	}
+
+	return out
+}
+
+// checkKeys ensures all requested keys exist in the source.
+func (p *Provisioner) checkKeys(source client.Object) error {
+	for _, key := range p.keys {
+		var ok bool
+
+		switch t := source.(type) {
+		case *corev1.Secret:
+			_, ok = t.Data[key]
+		case *corev1.ConfigMap:
+			_, ok = t.Data[key]
+
+			if !ok {
+				_, ok = t.BinaryData[key]
+			}
+		}
+
+		if !ok {
+			return fmt.Errorf("%w: %s in %s", ErrKeyNotFound, key, p.sourceKey.String())
+		}
+	}
+
+	return nil
+}
+
+// sync copies data from the source into the destination, returning true if the
+// destination was modified.
+func (p *Provisioner) sync(source, destination client.Object) bool {
+	switch t := destination.(type) {
+	case *corev1.Secret:
+		//nolint:forcetypeassert
+		s := source.(*corev1.Secret)
+
+		data := mapKeys(p, s.Data)
+
+		// The type is immutable, so only set it on creation.
+		if t.ResourceVersion == "" {
+			t.Type = s.Type
+		}
+
+		if equality.Semantic.DeepEqual(t.Data, data) {
+			return false
+		}
+
+		t.Data = data
+	case *corev1.ConfigMap:
+		//nolint:forcetypeassert
+		s := source.(*corev1.ConfigMap)
+
+		data := mapKeys(p, s.Data)
+		binaryData := mapKeys(p, s.BinaryData)
+
+		if equality.Semantic.DeepEqual(t.Data, data) && equality.Semantic.DeepEqual(t.BinaryData, binaryData) {
+			return false
+		}
+
+		t.Data = data
+		t.BinaryData = binaryData
+	}
+
+	return true
+}
+
+// owned returns true if the destination was created by this provisioner.  Replicas
+// created before the source cluster and owner were recorded are also considered
+// owned, and will be adopted on the next provision.
+func (p *Provisioner) owned(destination client.Object, sourceID, owner string) bool {
+	annotations := destination.GetAnnotations()
+
+	if annotations[constants.ReplicationSourceAnnotation] == sourceID && annotations[constants.ReplicationOwnerAnnotation] == owner {
+		return true
+	}
+
+	_, ok := annotations[constants.ReplicationOwnerAnnotation]
+
+	return !ok && annotations[constants.ReplicationSourceAnnotation] == p.sourceKey.String()
+}
+
+// annotate sets the ownership annotations, returning true if the destination was
+// modified.
+func annotate(destination client.Object, sourceID, owner string) bool {
+	want := annotations(sourceID, owner)
+	current := destination.GetAnnotations()
+
+	var modified bool
+
+	if current == nil {
+		current = map[string]string{}
+	}
+
+	for key, value := range want {
+		if current[key] != value {
+			current[key] = value
+			modified = true
+		}
+	}
+
+	destination.SetAnnotations(current)
+
+	return modified
+}
+
+// Provision implements the Provision interface.
+func (p *Provisioner) Provision(ctx context.Context) error {
+	log := log.FromContext(ctx)
+
+	sourceClient, err := p.source.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	sourceID, err := p.sourceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	destinationClient, err := p.destination.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	owner := owner(ctx)
+
+	source := p.newObject()
+
+	if err := sourceClient.Get(ctx, p.sourceKey, source); err != nil {
+		if kerrors.IsNotFound(err) {
+			log.Info("awaiting replication source", "provisioner", p.Name, "source", sourceID)
+
+			return provisioners.ErrYield
+		}
+
+		return err
+	}
+
+	if err := p.checkKeys(source); err != nil {
+		return err
+	}
+
+	destination := p.newObject()
+
+	if err := destinationClient.Get(ctx, p.destinationKey, destination); err != nil {
+		if !kerrors.IsNotFound(err) {
+			return err
+		}
+
+		destination.SetNamespace(p.destinationKey.Namespace)
+		destination.SetName(p.destinationKey.Name)
+		destination.SetAnnotations(annotations(sourceID, owner))
+
+		p.sync(source, destination)
+
+		log.Info("creating replica", "provisioner", p.Name, "destination", p.destinationKey.String())
+
+		return destinationClient.Create(ctx, destination)
+	}
+
+	if !p.owned(destination, sourceID, owner) {
+		return fmt.Errorf("%w: %s", ErrNotOwned, p.destinationKey.String())
+	}
+
+	// Don't short circuit, both need to be applied.
+	annotated := annotate(destination, sourceID, owner)
+	synced := p.sync(source, destination)
+
+	if !annotated && !synced {
+		return nil
+	}
+
+	log.Info("updating replica", "provisioner", p.Name, "destination", p.destinationKey.String())
+
+	return destinationClient.Update(ctx, destination)
+}
+
+// Deprovision implements the Provision interface.
+func (p *Provisioner) Deprovision(ctx context.Context) error {
+	log := log.FromContext(ctx)
+
+	// If the destination cluster cannot be contacted due to a yield error then
+	// assume its configuration is gone, and it has been or is being deleted,
+	// taking the replica with it.
+	destinationClient, err := p.destination.Client(ctx)
+	if err != nil {
+		if errors.Is(err, provisioners.ErrYield) {
+			log.Info("replica destination gone, assuming deleted", "provisioner", p.Name, "destination", p.destinationKey.String())
+
+			return nil
+		}
+
+		return err
+	}
+
+	destination := p.newObject()
+
+	if err := destinationClient.Get(ctx, p.destinationKey, destination); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	sourceID, err := p.sourceID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !p.owned(destination, sourceID, owner(ctx)) {
+		log.Info("replica not owned, ignoring", "provisioner", p.Name, "destination", p.destinationKey.String())
+
+		return nil
+	}
+
+	log.Info("deleting replica", "provisioner", p.Name, "destination", p.destinationKey.String())
+
+	if err := destinationClient.Delete(ctx, destination); err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
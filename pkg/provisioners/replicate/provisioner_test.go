@@ -0,0 +1,260 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replicate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	unikornv1fake "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1/fake"
+	coreclient "github.com/unikorn-cloud/core/pkg/client"
+	"github.com/unikorn-cloud/core/pkg/constants"
+	"github.com/unikorn-cloud/core/pkg/provisioners"
+	"github.com/unikorn-cloud/core/pkg/provisioners/application"
+	"github.com/unikorn-cloud/core/pkg/provisioners/replicate"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+//nolint:gochecknoglobals
+var (
+	sourceKey = client.ObjectKey{
+		Namespace: "remote",
+		Name:      "kubeconfig",
+	}
+
+	destinationKey = client.ObjectKey{
+		Namespace: "host",
+		Name:      "cluster-kubeconfig",
+	}
+)
+
+// newContext returns a context where the current cluster is the "remote" and the
+// host cluster is the provisioner client.
+func newContext(remote, host client.Client) context.Context {
+	ctx := coreclient.NewContextWithCluster(context.Background(), &coreclient.ClusterContext{Client: remote})
+	ctx = coreclient.NewContextWithProvisionerClient(ctx, host)
+
+	return ctx
+}
+
+// newOwnedContext returns a context as per newContext with an owning resource,
+// and an uncached reader for the host.
+func newOwnedContext(remote, host client.Client, reader client.Reader, uid types.UID) context.Context {
+	resource := &unikornv1fake.ManagedResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "owner",
+			Name:      "owner",
+			UID:       uid,
+		},
+	}
+
+	ctx := coreclient.NewContextWithProvisionerReader(newContext(remote, host), reader)
+
+	return application.NewContext(ctx, resource)
+}
+
+// goneEndpoint is a cluster that no longer exists.
+type goneEndpoint struct{}
+
+func (goneEndpoint) Client(_ context.Context) (client.Client, error) {
+	return nil, provisioners.ErrYield
+}
+
+func (goneEndpoint) ID(_ context.Context) (string, error) {
+	return "gone", nil
+}
+
+// countingReader records the number of reads.
+type countingReader struct {
+	client.Reader
+
+	reads int
+}
+
+func (r *countingReader) Get(ctx context.Context, key client.ObjectKey, object client.Object, options ...client.GetOption) error {
+	r.reads++
+
+	return r.Reader.Get(ctx, key, object, options...)
+}
+
+func newSecret(data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: sourceKey.Namespace,
+			Name:      sourceKey.Name,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+}
+
+// TestReplicateSecret tests a secret is copied from the current cluster to the
+// host, kept in sync, and deleted on deprovision.
+func TestReplicateSecret(t *testing.T) {
+	t.Parallel()
+
+	remote := fake.NewClientBuilder().Build()
+	host := fake.NewClientBuilder().Build()
+	ctx := newContext(remote, host)
+
+	provisioner := replicate.NewSecret("kubeconfig", replicate.Current(), sourceKey, replicate.Host(), destinationKey).WithKeys("value").WithRename("value", "kubeconfig")
+
+	// Wait for the source to appear.
+	require.ErrorIs(t, provisioner.Provision(ctx), provisioners.ErrYield)
+
+	source := newSecret(map[string][]byte{
+		"value":  []byte("foo"),
+		"secret": []byte("bar"),
+	})
+
+	require.NoError(t, remote.Create(ctx, source))
+	require.NoError(t, provisioner.Provision(ctx))
+
+	var destination corev1.Secret
+
+	require.NoError(t, host.Get(ctx, destinationKey, &destination))
+	assert.Equal(t, map[string][]byte{"kubeconfig": []byte("foo")}, destination.Data)
+	assert.Equal(t, corev1.SecretTypeOpaque, destination.Type)
+
+	// Updates are synchronized.
+	source.Data["value"] = []byte("baz")
+
+	require.NoError(t, remote.Update(ctx, source))
+	require.NoError(t, provisioner.Provision(ctx))
+
+	require.NoError(t, host.Get(ctx, destinationKey, &destination))
+	assert.Equal(t, map[string][]byte{"kubeconfig": []byte("baz")}, destination.Data)
+
+	// And cleaned up.
+	require.NoError(t, provisioner.Deprovision(ctx))
+	require.True(t, kerrors.IsNotFound(host.Get(ctx, destinationKey, &destination)))
+	require.NoError(t, provisioner.Deprovision(ctx))
+}
+
+// TestReplicateMissingKey tests an error is raised when a requested key is missing.
+func TestReplicateMissingKey(t *testing.T) {
+	t.Parallel()
+
+	remote := fake.NewClientBuilder().WithObjects(newSecret(map[string][]byte{"foo": []byte("bar")})).Build()
+	host := fake.NewClientBuilder().Build()
+	ctx := newContext(remote, host)
+
+	provisioner := replicate.NewSecret("kubeconfig", replicate.Current(), sourceKey, replicate.Host(), destinationKey).WithKeys("value")
+
+	require.ErrorIs(t, provisioner.Provision(ctx), replicate.ErrKeyNotFound)
+}
+
+// TestReplicateNotOwned tests resources not created by replication are left alone.
+func TestReplicateNotOwned(t *testing.T) {
+	t.Parallel()
+
+	existing := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: destinationKey.Namespace,
+			Name:      destinationKey.Name,
+		},
+	}
+
+	source := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: sourceKey.Namespace,
+			Name:      sourceKey.Name,
+		},
+		Data: map[string]string{
+			"foo": "bar",
+		},
+	}
+
+	remote := fake.NewClientBuilder().WithObjects(source).Build()
+	host := fake.NewClientBuilder().WithObjects(existing).Build()
+	ctx := newContext(remote, host)
+
+	provisioner := replicate.NewConfigMap("config", replicate.Current(), sourceKey, replicate.Host(), destinationKey)
+
+	require.ErrorIs(t, provisioner.Provision(ctx), replicate.ErrNotOwned)
+	require.NoError(t, provisioner.Deprovision(ctx))
+	require.NoError(t, host.Get(ctx, destinationKey, &corev1.ConfigMap{}))
+}
+
+// TestReplicateDeprovisionDestinationGone tests deprovisioning succeeds when the
+// destination cluster has gone away.
+func TestReplicateDeprovisionDestinationGone(t *testing.T) {
+	t.Parallel()
+
+	remote := fake.NewClientBuilder().Build()
+	host := fake.NewClientBuilder().Build()
+	ctx := newContext(remote, host)
+
+	provisioner := replicate.NewSecret("kubeconfig", replicate.Current(), sourceKey, goneEndpoint{}, destinationKey)
+
+	require.NoError(t, provisioner.Deprovision(ctx))
+}
+
+// TestReplicateOwnership tests the host is read without a cache, replicas are
+// annotated with their source cluster and owner, replicas belonging to another
+// owner are left alone, and legacy replicas are adopted.
+func TestReplicateOwnership(t *testing.T) {
+	t.Parallel()
+
+	remote := fake.NewClientBuilder().WithObjects(newSecret(map[string][]byte{"foo": []byte("bar")})).Build()
+	host := fake.NewClientBuilder().Build()
+	reader := &countingReader{Reader: host}
+	ctx := newOwnedContext(remote, host, reader, "bfb1c4ac-6b0b-4a3e-9f5d-0f3c3b2c7e6d")
+
+	provisioner := replicate.NewSecret("kubeconfig", replicate.Current(), sourceKey, replicate.Host(), destinationKey)
+
+	require.NoError(t, provisioner.Provision(ctx))
+	assert.Positive(t, reader.reads)
+
+	var destination corev1.Secret
+
+	require.NoError(t, host.Get(ctx, destinationKey, &destination))
+	assert.Equal(t, "host/remote/kubeconfig", destination.Annotations[constants.ReplicationSourceAnnotation])
+	assert.Equal(t, "bfb1c4ac-6b0b-4a3e-9f5d-0f3c3b2c7e6d", destination.Annotations[constants.ReplicationOwnerAnnotation])
+
+	// Another resource replicating the same source cannot touch it.
+	other := newOwnedContext(remote, host, reader, "5a8e2b0c-3f4d-4d1e-8c7a-9b6f1e2d3c4b")
+
+	require.ErrorIs(t, provisioner.Provision(other), replicate.ErrNotOwned)
+	require.NoError(t, provisioner.Deprovision(other))
+	require.NoError(t, host.Get(ctx, destinationKey, &destination))
+
+	// Legacy replicas, annotated with only the source key, are adopted.
+	destination.Annotations = map[string]string{
+		constants.ReplicationSourceAnnotation: sourceKey.String(),
+	}
+
+	require.NoError(t, host.Update(ctx, &destination))
+	require.NoError(t, provisioner.Provision(ctx))
+
+	require.NoError(t, host.Get(ctx, destinationKey, &destination))
+	assert.Equal(t, "host/remote/kubeconfig", destination.Annotations[constants.ReplicationSourceAnnotation])
+	assert.Equal(t, "bfb1c4ac-6b0b-4a3e-9f5d-0f3c3b2c7e6d", destination.Annotations[constants.ReplicationOwnerAnnotation])
+
+	require.NoError(t, provisioner.Deprovision(ctx))
+	require.True(t, kerrors.IsNotFound(host.Get(ctx, destinationKey, &destination)))
+}
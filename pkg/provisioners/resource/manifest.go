@@ -0,0 +1,105 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"bytes"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// ErrManifest is raised when a manifest cannot be parsed.
+	ErrManifest = goerrors.New("manifest invalid")
+
+	// ErrNoManifests is raised when a glob pattern matches no files.
+	ErrNoManifests = goerrors.New("no manifests match pattern")
+)
+
+// ParseManifest parses a multi-document YAML (or JSON) manifest into a set of
+// unstructured objects, preserving document order.  Empty documents are ignored.
+func ParseManifest(data []byte) ([]client.Object, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+
+	var objects []client.Object
+
+	for {
+		object := &unstructured.Unstructured{}
+
+		if err := decoder.Decode(&object.Object); err != nil {
+			if goerrors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, fmt.Errorf("%w: %w", ErrManifest, err)
+		}
+
+		if len(object.Object) == 0 {
+			continue
+		}
+
+		if object.GetKind() == "" || object.GetAPIVersion() == "" {
+			return nil, fmt.Errorf("%w: object missing apiVersion or kind", ErrManifest)
+		}
+
+		objects = append(objects, object)
+	}
+
+	return objects, nil
+}
+
+// ParseManifestFS parses all files in the file system that match the provided
+// glob patterns.  Within a pattern, files are processed in lexical order, and
+// patterns are processed in the order provided.  A pattern that matches nothing
+// is an error, as that's almost certainly a typo.
+func ParseManifestFS(fsys fs.FS, patterns ...string) ([]client.Object, error) {
+	var objects []client.Object
+
+	for _, pattern := range patterns {
+		paths, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrNoManifests, pattern)
+		}
+
+		for _, path := range paths {
+			data, err := fs.ReadFile(fsys, path)
+			if err != nil {
+				return nil, err
+			}
+
+			o, err := ParseManifest(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+
+			objects = append(objects, o...)
+		}
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	goerrors "errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var (
+	// ErrResourceFailed is raised when a resource has failed permanently
+	// e.g. a Job has exceeded its back off limit, and waiting will not help.
+	ErrResourceFailed = goerrors.New("resource failed")
+)
+
+// getCondition looks up a status condition's status from an unstructured object.
+func getCondition(object *unstructured.Unstructured, t string) (string, bool) {
+	conditions, _, _ := unstructured.NestedSlice(object.Object, "status", "conditions")
+
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if conditionType, _, _ := unstructured.NestedString(condition, "type"); conditionType != t {
+			continue
+		}
+
+		status, _, _ := unstructured.NestedString(condition, "status")
+
+		return status, true
+	}
+
+	return "", false
+}
+
+func getInt64(object *unstructured.Unstructured, fields ...string) int64 {
+	value, _, _ := unstructured.NestedInt64(object.Object, fields...)
+
+	return value
+}
+
+// getReplicas returns the desired number of replicas, which defaults to 1.
+func getReplicas(object *unstructured.Unstructured) int64 {
+	replicas, ok, _ := unstructured.NestedInt64(object.Object, "spec", "replicas")
+	if !ok {
+		return 1
+	}
+
+	return replicas
+}
+
+// generationObserved returns false if the resource has a status that reports the
+// observed generation, and this is behind the actual generation i.e. a controller
+// has yet to see the latest specification.
+func generationObserved(object *unstructured.Unstructured) bool {
+	observedGeneration, ok, _ := unstructured.NestedInt64(object.Object, "status", "observedGeneration")
+	if !ok {
+		return true
+	}
+
+	return observedGeneration >= object.GetGeneration()
+}
+
+func deploymentReady(object *unstructured.Unstructured) bool {
+	replicas := getReplicas(object)
+
+	return getInt64(object, "status", "updatedReplicas") == replicas &&
+		getInt64(object, "status", "availableReplicas") == replicas &&
+		getInt64(object, "status", "replicas") == replicas
+}
+
+func statefulSetReady(object *unstructured.Unstructured) bool {
+	replicas := getReplicas(object)
+
+	currentRevision, _, _ := unstructured.NestedString(object.Object, "status", "currentRevision")
+	updateRevision, _, _ := unstructured.NestedString(object.Object, "status", "updateRevision")
+
+	return getInt64(object, "status", "readyReplicas") == replicas &&
+		getInt64(object, "status", "updatedReplicas") == replicas &&
+		currentRevision == updateRevision
+}
+
+func daemonSetReady(object *unstructured.Unstructured) bool {
+	desired := getInt64(object, "status", "desiredNumberScheduled")
+
+	return getInt64(object, "status", "updatedNumberScheduled") == desired &&
+		getInt64(object, "status", "numberAvailable") == desired
+}
+
+func jobReady(object *unstructured.Unstructured) (bool, error) {
+	if status, ok := getCondition(object, "Failed"); ok && status == "True" {
+		return false, fmt.Errorf("%w: job %s failed", ErrResourceFailed, object.GetName())
+	}
+
+	status, ok := getCondition(object, "Complete")
+
+	return ok && status == "True", nil
+}
+
+func customResourceDefinitionReady(object *unstructured.Unstructured) bool {
+	status, ok := getCondition(object, "Established")
+
+	return ok && status == "True"
+}
+
+// Ready does a kstatus-like readiness check on a resource.  Well known types
+// have specific checks, e.g. Deployments must be fully rolled out, Jobs must
+// complete and CRDs must be established.  For everything else, the generation
+// must be observed, and if a Ready condition exists, it must be true.
+// An error is returned if the resource has failed terminally.
+func Ready(object *unstructured.Unstructured) (bool, error) {
+	if !generationObserved(object) {
+		return false, nil
+	}
+
+	gvk := object.GroupVersionKind()
+
+	switch gvk.GroupKind().String() {
+	case "Deployment.apps":
+		return deploymentReady(object), nil
+	case "StatefulSet.apps":
+		return statefulSetReady(object), nil
+	case "DaemonSet.apps":
+		return daemonSetReady(object), nil
+	case "Job.batch":
+		return jobReady(object)
+	case "CustomResourceDefinition.apiextensions.k8s.io":
+		return customResourceDefinitionReady(object), nil
+	}
+
+	if status, ok := getCondition(object, "Ready"); ok {
+		return status == "True", nil
+	}
+
+	return true, nil
+}
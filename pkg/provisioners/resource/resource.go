@@ -20,24 +20,46 @@ package resource
 import (
 	"context"
 	"fmt"
+	"io/fs"
 
 	clientlib "github.com/unikorn-cloud/core/pkg/client"
+	"github.com/unikorn-cloud/core/pkg/constants"
 	"github.com/unikorn-cloud/core/pkg/provisioners"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+// LoaderFunc lazily generates a set of resources to manage.
+type LoaderFunc func() ([]client.Object, error)
+
 // Provisioner is a provisioner that is able to parse and manage resources
 // sourced from a yaml manifest.
 type Provisioner struct {
 	provisioners.Metadata
 
-	// resource is the resource to provision.
-	resource client.Object
+	// loader returns the resources to provision, these are generated
+	// lazily so that errors are handled in Provision/Deprovision rather
+	// than the constructor.
+	loader LoaderFunc
+
+	// namespace, if set, is applied to namespaced resources that don't
+	// explicitly specify one.
+	namespace string
+
+	// serverSideApply uses server-side apply rather than a client side
+	// create or update.
+	serverSideApply bool
+
+	// waitForReady gates provisioning on resource readiness.
+	waitForReady bool
 }
 
 // Ensure the Provisioner interface is implemented.
@@ -47,15 +69,164 @@ var _ provisioners.Provisioner = &Provisioner{}
 // a manifest with kubectl.  The path argument may be a path on the local file
 // system or a URL.
 func New(resource client.Object) *Provisioner {
+	return NewWithLoader(func() ([]client.Object, error) {
+		return []client.Object{resource}, nil
+	})
+}
+
+// NewWithLoader returns a provisioner that manages an ordered set of resources
+// generated by the loader.
+func NewWithLoader(loader LoaderFunc) *Provisioner {
 	return &Provisioner{
-		resource: resource,
+		loader: loader,
 	}
 }
 
+// NewFromBytes returns a provisioner that manages the resources defined in a
+// multi-document YAML manifest.
+func NewFromBytes(data []byte) *Provisioner {
+	return NewWithLoader(func() ([]client.Object, error) {
+		return ParseManifest(data)
+	})
+}
+
+// NewFromFS returns a provisioner that manages the resources defined in multi-document
+// YAML manifests, selected from the file system by glob patterns.
+func NewFromFS(fsys fs.FS, patterns ...string) *Provisioner {
+	return NewWithLoader(func() ([]client.Object, error) {
+		return ParseManifestFS(fsys, patterns...)
+	})
+}
+
+// InNamespace sets the namespace for any namespaced resources that don't
+// explicitly define one.
+func (p *Provisioner) InNamespace(namespace string) *Provisioner {
+	p.namespace = namespace
+
+	return p
+}
+
+// WithServerSideApply uses server-side apply, with Unikorn as the field manager,
+// to create and update resources.
+func (p *Provisioner) WithServerSideApply() *Provisioner {
+	p.serverSideApply = true
+
+	return p
+}
+
+// WaitForReady gates provisioning on each resource being ready, in order, before
+// moving on to the next, yielding until it is.  See Ready for the definition of
+// readiness.
+func (p *Provisioner) WaitForReady() *Provisioner {
+	p.waitForReady = true
+
+	return p
+}
+
+// getResources loads the resources and defaults the namespace where required.
+func (p *Provisioner) getResources(c client.Client) ([]client.Object, error) {
+	resources, err := p.loader()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.namespace == "" {
+		return resources, nil
+	}
+
+	for _, resource := range resources {
+		if resource.GetNamespace() != "" {
+			continue
+		}
+
+		namespaced, err := c.IsObjectNamespaced(resource)
+		if err != nil {
+			return nil, err
+		}
+
+		if namespaced {
+			resource.SetNamespace(p.namespace)
+		}
+	}
+
+	return resources, nil
+}
+
+// toUnstructured converts a resource into its unstructured form, as required by server-side
+// apply and readiness checks.
+func toUnstructured(c client.Client, resource client.Object) (*unstructured.Unstructured, error) {
+	if u, ok := resource.(*unstructured.Unstructured); ok {
+		return u, nil
+	}
+
+	gvk, err := apiutil.GVKForObject(resource, c.Scheme())
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &unstructured.Unstructured{
+		Object: object,
+	}
+
+	u.SetGroupVersionKind(gvk)
+
+	return u, nil
+}
+
 func mutate() error {
 	return nil
 }
 
+// createOrUpdate creates the resource if it doesn't exist, the resource is updated
+// with the current state from the server.
+func createOrUpdate(ctx context.Context, c client.Client, resource client.Object) error {
+	log := log.FromContext(ctx)
+
+	result, err := controllerutil.CreateOrUpdate(ctx, c, resource, mutate)
+	if err != nil {
+		return err
+	}
+
+	log.Info(fmt.Sprintf("object %v", result), "name", resource.GetName(), "generateName", resource.GetGenerateName())
+
+	return nil
+}
+
+// apply does a server-side apply of the resource, the resource is updated with
+// the current state from the server.
+func apply(ctx context.Context, c client.Client, resource client.Object) error {
+	log := log.FromContext(ctx)
+
+	u, err := toUnstructured(c, resource)
+	if err != nil {
+		return err
+	}
+
+	// Server-side apply doesn't allow these to be set.
+	u = u.DeepCopy()
+	u.SetManagedFields(nil)
+	u.SetResourceVersion("")
+
+	if err := c.Patch(ctx, u, client.Apply, client.FieldOwner(constants.FieldManager), client.ForceOwnership); err != nil {
+		return err
+	}
+
+	log.Info("object applied", "name", resource.GetName())
+
+	if typed, ok := resource.(*unstructured.Unstructured); ok {
+		typed.Object = u.Object
+
+		return nil
+	}
+
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, resource)
+}
+
 // Provision implements the Provision interface.
 func (p *Provisioner) Provision(ctx context.Context) error {
 	log := log.FromContext(ctx)
@@ -65,21 +236,53 @@ func (p *Provisioner) Provision(ctx context.Context) error {
 		return err
 	}
 
-	objectKey := client.ObjectKeyFromObject(p.resource)
-
-	log.Info("creating object", "key", objectKey)
-
-	result, err := controllerutil.CreateOrUpdate(ctx, clusterContext.Client, p.resource, mutate)
+	resources, err := p.getResources(clusterContext.Client)
 	if err != nil {
 		return err
 	}
 
-	log.Info(fmt.Sprintf("object %v", result), "name", p.resource.GetName(), "generateName", p.resource.GetGenerateName())
+	for _, resource := range resources {
+		objectKey := client.ObjectKeyFromObject(resource)
+
+		log.Info("creating object", "key", objectKey)
+
+		if p.serverSideApply {
+			if err := apply(ctx, clusterContext.Client, resource); err != nil {
+				return err
+			}
+		} else {
+			if err := createOrUpdate(ctx, clusterContext.Client, resource); err != nil {
+				return err
+			}
+		}
+
+		if !p.waitForReady {
+			continue
+		}
+
+		u, err := toUnstructured(clusterContext.Client, resource)
+		if err != nil {
+			return err
+		}
+
+		ready, err := Ready(u)
+		if err != nil {
+			return err
+		}
+
+		if !ready {
+			log.Info("awaiting object readiness", "key", objectKey)
+
+			return provisioners.ErrYield
+		}
+	}
 
 	return nil
 }
 
 // Deprovision implements the Provision interface.
+// Resources are deleted in the reverse order to provisioning, each must be
+// deleted before moving on to the next.
 func (p *Provisioner) Deprovision(ctx context.Context) error {
 	log := log.FromContext(ctx)
 
@@ -88,21 +291,34 @@ func (p *Provisioner) Deprovision(ctx context.Context) error {
 		return err
 	}
 
-	objectKey := client.ObjectKeyFromObject(p.resource)
+	resources, err := p.getResources(clusterContext.Client)
+	if err != nil {
+		return err
+	}
+
+	for i := range resources {
+		resource := resources[len(resources)-(i+1)]
 
-	log.Info("deleting object", "key", objectKey)
+		objectKey := client.ObjectKeyFromObject(resource)
 
-	if err := clusterContext.Client.Delete(ctx, p.resource); err != nil {
-		if errors.IsNotFound(err) {
-			log.Info("object deleted", "key", objectKey)
+		log.Info("deleting object", "key", objectKey)
 
-			return nil
+		if err := clusterContext.Client.Delete(ctx, resource); err != nil {
+			// If the type is no longer defined, e.g. a CRD has been deleted,
+			// then neither is the resource.
+			if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				log.Info("object deleted", "key", objectKey)
+
+				continue
+			}
+
+			return err
 		}
 
-		return err
-	}
+		log.Info("awaiting object deletion", "key", objectKey)
 
-	log.Info("awaiting object deletion", "key", objectKey)
+		return provisioners.ErrYield
+	}
 
-	return provisioners.ErrYield
+	return nil
 }
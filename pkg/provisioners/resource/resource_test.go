@@ -0,0 +1,279 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	coreclient "github.com/unikorn-cloud/core/pkg/client"
+	"github.com/unikorn-cloud/core/pkg/constants"
+	"github.com/unikorn-cloud/core/pkg/provisioners"
+	"github.com/unikorn-cloud/core/pkg/provisioners/resource"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+const (
+	testNamespace = "foo"
+
+	manifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: first
+data:
+  foo: bar
+---
+# Empty documents are ignored.
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: second
+  namespace: explicit
+`
+)
+
+func mustNewClient(t *testing.T, objects ...client.Object) client.Client {
+	t.Helper()
+
+	scheme, err := coreclient.NewScheme()
+	require.NoError(t, err)
+
+	// Everything is namespaced for the purposes of testing.
+	mapper := meta.NewDefaultRESTMapper(nil)
+
+	for gvk := range scheme.AllKnownTypes() {
+		mapper.Add(gvk, meta.RESTScopeNamespace)
+	}
+
+	return fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(objects...).Build()
+}
+
+func newContext(c client.Client) context.Context {
+	return coreclient.NewContextWithCluster(context.Background(), &coreclient.ClusterContext{Client: c})
+}
+
+func mustGetConfigMap(t *testing.T, c client.Client, namespace, name string) *corev1.ConfigMap {
+	t.Helper()
+
+	var configMap corev1.ConfigMap
+
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: name}, &configMap))
+
+	return &configMap
+}
+
+// TestParseManifest tests multi-document manifests are parsed in order.
+func TestParseManifest(t *testing.T) {
+	t.Parallel()
+
+	objects, err := resource.ParseManifest([]byte(manifest))
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	assert.Equal(t, "first", objects[0].GetName())
+	assert.Equal(t, "second", objects[1].GetName())
+}
+
+// TestParseManifestInvalid tests objects must be typed.
+func TestParseManifestInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := resource.ParseManifest([]byte("metadata:\n  name: foo\n"))
+	assert.ErrorIs(t, err, resource.ErrManifest)
+}
+
+// TestParseManifestFS tests manifests are loaded from a file system in lexical order.
+func TestParseManifestFS(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"manifests/b.yaml": &fstest.MapFile{Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: b\n")},
+		"manifests/a.yaml": &fstest.MapFile{Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n")},
+	}
+
+	objects, err := resource.ParseManifestFS(fsys, "manifests/*.yaml")
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	assert.Equal(t, "a", objects[0].GetName())
+	assert.Equal(t, "b", objects[1].GetName())
+
+	_, err = resource.ParseManifestFS(fsys, "manifests/*.yaml", "manifest/*.yaml")
+	assert.ErrorIs(t, err, resource.ErrNoManifests)
+}
+
+// TestProvisionManifest tests all resources in a manifest are created, with
+// the namespace defaulted where not specified.
+func TestProvisionManifest(t *testing.T) {
+	t.Parallel()
+
+	c := mustNewClient(t)
+	ctx := newContext(c)
+
+	require.NoError(t, resource.NewFromBytes([]byte(manifest)).InNamespace(testNamespace).Provision(ctx))
+
+	assert.Equal(t, "bar", mustGetConfigMap(t, c, testNamespace, "first").Data["foo"])
+	mustGetConfigMap(t, c, "explicit", "second")
+}
+
+// TestProvisionServerSideApply tests resources are applied with the correct
+// field manager.
+func TestProvisionServerSideApply(t *testing.T) {
+	t.Parallel()
+
+	var patches []client.Patch
+
+	var fieldOwners []string
+
+	funcs := interceptor.Funcs{
+		Patch: func(_ context.Context, _ client.WithWatch, _ client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			options := &client.PatchOptions{}
+			options.ApplyOptions(opts)
+
+			patches = append(patches, patch)
+			fieldOwners = append(fieldOwners, options.FieldManager)
+
+			return nil
+		},
+	}
+
+	scheme, err := coreclient.NewScheme()
+	require.NoError(t, err)
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(funcs).Build()
+	ctx := newContext(c)
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      "typed",
+		},
+	}
+
+	require.NoError(t, resource.New(configMap).WithServerSideApply().Provision(ctx))
+	require.Len(t, patches, 1)
+	assert.Equal(t, client.Apply, patches[0])
+	assert.Equal(t, constants.FieldManager, fieldOwners[0])
+}
+
+func newDeployment(ready bool) *appsv1.Deployment {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  testNamespace,
+			Name:       "deployment",
+			Generation: 1,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To[int32](2),
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 1,
+			Replicas:           2,
+			UpdatedReplicas:    1,
+			AvailableReplicas:  2,
+		},
+	}
+
+	if ready {
+		deployment.Status.UpdatedReplicas = 2
+	}
+
+	return deployment
+}
+
+// TestProvisionWaitForReadyDeployment tests a provisioner yields until a
+// deployment is rolled out.
+func TestProvisionWaitForReadyDeployment(t *testing.T) {
+	t.Parallel()
+
+	c := mustNewClient(t, newDeployment(false))
+	ctx := newContext(c)
+
+	assert.ErrorIs(t, resource.New(newDeployment(false)).WaitForReady().Provision(ctx), provisioners.ErrYield)
+
+	c = mustNewClient(t, newDeployment(true))
+	ctx = newContext(c)
+
+	assert.NoError(t, resource.New(newDeployment(true)).WaitForReady().Provision(ctx))
+}
+
+// TestProvisionWaitForReadyJobFailed tests a provisioner errors when a job
+// has failed.
+func TestProvisionWaitForReadyJobFailed(t *testing.T) {
+	t.Parallel()
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      "job",
+		},
+		Status: batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{
+				{
+					Type:   batchv1.JobFailed,
+					Status: corev1.ConditionTrue,
+				},
+			},
+		},
+	}
+
+	c := mustNewClient(t, job)
+	ctx := newContext(c)
+
+	assert.ErrorIs(t, resource.New(job.DeepCopy()).WaitForReady().Provision(ctx), resource.ErrResourceFailed)
+}
+
+// TestDeprovisionReverseOrder tests resources are deleted in the reverse order
+// to that which they were created.
+func TestDeprovisionReverseOrder(t *testing.T) {
+	t.Parallel()
+
+	c := mustNewClient(t)
+	ctx := newContext(c)
+
+	provisioner := resource.NewFromBytes([]byte(manifest)).InNamespace(testNamespace)
+
+	require.NoError(t, provisioner.Provision(ctx))
+
+	assert.ErrorIs(t, provisioner.Deprovision(ctx), provisioners.ErrYield)
+
+	mustGetConfigMap(t, c, testNamespace, "first")
+
+	var configMap corev1.ConfigMap
+
+	assert.True(t, kerrors.IsNotFound(c.Get(ctx, types.NamespacedName{Namespace: "explicit", Name: "second"}, &configMap)))
+
+	assert.ErrorIs(t, provisioner.Deprovision(ctx), provisioners.ErrYield)
+	assert.True(t, kerrors.IsNotFound(c.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: "first"}, &configMap)))
+
+	assert.NoError(t, provisioner.Deprovision(ctx))
+}
@@ -51,7 +51,7 @@ func (p *Provisioner) Provision(ctx context.Context) error {
 	log.Info("provisioning serial group", "group", p.Name)
 
 	for _, provisioner := range p.provisioners {
-		if err := provisioner.Provision(ctx); err != nil {
+		if err := provisioners.Provision(ctx, provisioner); err != nil {
 			log.Info("serial group member exited with error", "error", err, "group", p.Name, "provisioner", provisioner.ProvisionerName())
 
 			return err
@@ -75,7 +75,7 @@ func (p *Provisioner) Deprovision(ctx context.Context) error {
 	for i := range p.provisioners {
 		provisioner := p.provisioners[len(p.provisioners)-(i+1)]
 
-		if err := provisioner.Deprovision(ctx); err != nil {
+		if err := provisioners.Deprovision(ctx, provisioner); err != nil {
 			log.Info("serial group member exited with error", "error", err, "group", p.Name, "provisioner", provisioner.ProvisionerName())
 
 			return err
@@ -58,7 +58,8 @@ func TestSerialProvision(t *testing.T) {
 	ctx := context.Background()
 
 	p := mock.NewMockProvisioner(c)
-	p.EXPECT().Provision(ctx).Return(nil).Times(2)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Provision(gomock.Any()).Return(nil).Times(2)
 
 	assert.NoError(t, serial.New("test", p, p).Provision(ctx))
 }
@@ -74,8 +75,8 @@ func TestSerialProvisionYieldFirst(t *testing.T) {
 	ctx := context.Background()
 
 	p := mock.NewMockProvisioner(c)
-	p.EXPECT().Provision(ctx).Return(provisioners.ErrYield)
-	p.EXPECT().ProvisionerName().Return("")
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Provision(gomock.Any()).Return(provisioners.ErrYield)
 
 	assert.ErrorIs(t, provisioners.ErrYield, serial.New("test", p, p).Provision(ctx))
 }
@@ -91,11 +92,12 @@ func TestSerialProvisionYieldSecond(t *testing.T) {
 	ctx := context.Background()
 
 	p1 := mock.NewMockProvisioner(c)
-	p1.EXPECT().Provision(ctx).Return(nil)
+	p1.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p1.EXPECT().Provision(gomock.Any()).Return(nil)
 
 	p2 := mock.NewMockProvisioner(c)
-	p2.EXPECT().Provision(ctx).Return(provisioners.ErrYield)
-	p2.EXPECT().ProvisionerName().Return("")
+	p2.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p2.EXPECT().Provision(gomock.Any()).Return(provisioners.ErrYield)
 
 	assert.ErrorIs(t, provisioners.ErrYield, serial.New("test", p1, p2).Provision(ctx))
 }
@@ -111,7 +113,8 @@ func TestSerialDeprovision(t *testing.T) {
 	ctx := context.Background()
 
 	p := mock.NewMockProvisioner(c)
-	p.EXPECT().Deprovision(ctx).Return(nil).Times(2)
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Deprovision(gomock.Any()).Return(nil).Times(2)
 
 	assert.NoError(t, serial.New("test", p, p).Deprovision(ctx))
 }
@@ -128,11 +131,12 @@ func TestSerialDeprovisionYieldFirst(t *testing.T) {
 	ctx := context.Background()
 
 	p1 := mock.NewMockProvisioner(c)
-	p1.EXPECT().Deprovision(ctx).Return(provisioners.ErrYield)
-	p1.EXPECT().ProvisionerName().Return("")
+	p1.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p1.EXPECT().Deprovision(gomock.Any()).Return(provisioners.ErrYield)
 
 	p2 := mock.NewMockProvisioner(c)
-	p2.EXPECT().Deprovision(ctx).Return(nil)
+	p2.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p2.EXPECT().Deprovision(gomock.Any()).Return(nil)
 
 	assert.ErrorIs(t, provisioners.ErrYield, serial.New("test", p1, p2).Deprovision(ctx))
 }
@@ -149,8 +153,8 @@ func TestSerialDeprovisionYieldSecond(t *testing.T) {
 	ctx := context.Background()
 
 	p := mock.NewMockProvisioner(c)
-	p.EXPECT().Deprovision(ctx).Return(provisioners.ErrYield)
-	p.EXPECT().ProvisionerName().Return("")
+	p.EXPECT().ProvisionerName().Return("test").AnyTimes()
+	p.EXPECT().Deprovision(gomock.Any()).Return(provisioners.ErrYield)
 
 	assert.ErrorIs(t, provisioners.ErrYield, serial.New("test", p, p).Deprovision(ctx))
 }
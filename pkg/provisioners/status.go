@@ -0,0 +1,99 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioners
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// ReconcileStatus collects status reported by provisioners during a single
+// reconcile, which the manager uses to set the Healthy and Upgrading conditions.
+// Provisioners may run concurrently so access is synchronized.
+type ReconcileStatus struct {
+	// lock provides synchronization around concurrency.
+	lock sync.Mutex
+
+	// degraded records why the resource is degraded.
+	degraded []string
+
+	// upgrading records what is being upgraded.
+	upgrading []string
+}
+
+type reconcileStatusKeyType int
+
+//nolint:gochecknoglobals
+var reconcileStatusKey reconcileStatusKeyType
+
+// NewContextWithReconcileStatus adds a new, empty, reconcile status to the context.
+func NewContextWithReconcileStatus(ctx context.Context) context.Context {
+	return context.WithValue(ctx, reconcileStatusKey, &ReconcileStatus{})
+}
+
+// ReconcileStatusFromContext returns the reconcile status, or nil if not set.
+func ReconcileStatusFromContext(ctx context.Context) *ReconcileStatus {
+	if value, ok := ctx.Value(reconcileStatusKey).(*ReconcileStatus); ok {
+		return value
+	}
+
+	return nil
+}
+
+// ReportDegraded records that the resource is usable, but degraded for the given
+// reason.  Unlike an error, this doesn't stop provisioning.
+func ReportDegraded(ctx context.Context, reason string) {
+	if s := ReconcileStatusFromContext(ctx); s != nil {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		s.degraded = append(s.degraded, reason)
+	}
+}
+
+// ReportUpgrading records that the named component is being upgraded.
+func ReportUpgrading(ctx context.Context, name string) {
+	if s := ReconcileStatusFromContext(ctx); s != nil {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		s.upgrading = append(s.upgrading, name)
+	}
+}
+
+// Degraded returns all reasons the resource is degraded, sorted for stability.
+func (s *ReconcileStatus) Degraded() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	out := slices.Clone(s.degraded)
+	slices.Sort(out)
+
+	return out
+}
+
+// Upgrading returns everything being upgraded, sorted for stability.
+func (s *ReconcileStatus) Upgrading() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	out := slices.Clone(s.upgrading)
+	slices.Sort(out)
+
+	return out
+}
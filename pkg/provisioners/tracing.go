@@ -0,0 +1,86 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioners
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/unikorn-cloud/core/pkg/util/otel"
+)
+
+const (
+	// OutcomeDone is recorded when a provisioner completes.
+	OutcomeDone = "done"
+
+	// OutcomeYield is recorded when a provisioner yields.
+	OutcomeYield = "yield"
+
+	// OutcomeError is recorded when a provisioner fails.
+	OutcomeError = "error"
+)
+
+// RecordOutcome records the outcome of an operation against a span under the
+// given attribute key.  Yields are expected, so are not treated as errors.
+func RecordOutcome(span trace.Span, key attribute.Key, err error) {
+	switch {
+	case err == nil:
+		span.SetAttributes(key.String(OutcomeDone))
+		span.SetStatus(codes.Ok, "")
+	case errors.Is(err, ErrYield):
+		span.SetAttributes(key.String(OutcomeYield))
+	default:
+		span.SetAttributes(key.String(OutcomeError))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// traced runs a provisioner operation in a new span, recording the outcome.
+func traced(ctx context.Context, p Provisioner, operation string, f func(context.Context) error) error {
+	name := p.ProvisionerName()
+
+	ctx, span := otel.StartSpan(ctx, operation+" "+name)
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("provisioner.name", name),
+		attribute.String("provisioner.operation", operation),
+	)
+
+	err := f(ctx)
+
+	RecordOutcome(span, "provisioner.outcome", err)
+
+	return err
+}
+
+// Provision calls the provisioner's Provision method in a child span.  Provisioners
+// that invoke other provisioners should use this so that the provisioner tree is
+// reflected in traces.
+func Provision(ctx context.Context, p Provisioner) error {
+	return traced(ctx, p, "provision", p.Provision)
+}
+
+// Deprovision calls the provisioner's Deprovision method in a child span.
+func Deprovision(ctx context.Context, p Provisioner) error {
+	return traced(ctx, p, "deprovision", p.Deprovision)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioners_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/mock/gomock"
+
+	"github.com/unikorn-cloud/core/pkg/provisioners"
+	"github.com/unikorn-cloud/core/pkg/provisioners/mock"
+	"github.com/unikorn-cloud/core/pkg/provisioners/serial"
+)
+
+var errUnhandled = errors.New("unhandled")
+
+func outcome(t *testing.T, span sdktrace.ReadOnlySpan) string {
+	t.Helper()
+
+	for _, a := range span.Attributes() {
+		if a.Key == attribute.Key("provisioner.outcome") {
+			return a.Value.AsString()
+		}
+	}
+
+	return ""
+}
+
+// TestTracing ensures spans are created for provisioners, with outcomes recorded
+// and parented correctly.
+//
+//nolint:paralleltest
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	ctx := context.Background()
+
+	p1 := mock.NewMockProvisioner(c)
+	p1.EXPECT().ProvisionerName().Return("first").AnyTimes()
+	p1.EXPECT().Provision(gomock.Any()).Return(nil)
+
+	p2 := mock.NewMockProvisioner(c)
+	p2.EXPECT().ProvisionerName().Return("second").AnyTimes()
+	p2.EXPECT().Provision(gomock.Any()).Return(provisioners.ErrYield)
+
+	p3 := mock.NewMockProvisioner(c)
+	p3.EXPECT().ProvisionerName().Return("third").AnyTimes()
+	p3.EXPECT().Deprovision(gomock.Any()).Return(errUnhandled)
+
+	require.ErrorIs(t, provisioners.Provision(ctx, serial.New("group", p1, p2)), provisioners.ErrYield)
+	require.ErrorIs(t, provisioners.Deprovision(ctx, p3), errUnhandled)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+
+	// Spans are recorded as they end, so children precede their parents.
+	first, second, group, third := spans[0], spans[1], spans[2], spans[3]
+
+	assert.Equal(t, "provision first", first.Name())
+	assert.Equal(t, provisioners.OutcomeDone, outcome(t, first))
+	assert.Equal(t, group.SpanContext().SpanID(), first.Parent().SpanID())
+
+	assert.Equal(t, "provision second", second.Name())
+	assert.Equal(t, provisioners.OutcomeYield, outcome(t, second))
+	assert.Equal(t, group.SpanContext().SpanID(), second.Parent().SpanID())
+
+	assert.Equal(t, "provision group", group.Name())
+	assert.Equal(t, provisioners.OutcomeYield, outcome(t, group))
+	assert.False(t, group.Parent().IsValid())
+
+	assert.Equal(t, "deprovision third", third.Name())
+	assert.Equal(t, provisioners.OutcomeError, outcome(t, third))
+	assert.Equal(t, codes.Error, third.Status().Code)
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+var (
+	// ErrPredicate is raised when a predicate cannot be evaluated.
+	ErrPredicate = errors.New("predicate error")
+)
+
+// Predicate is evaluated against the object being waited on.  The object will
+// be nil if it does not exist.
+type Predicate func(ctx context.Context, object *unstructured.Unstructured) (bool, error)
+
+// Exists is satisfied when the object exists.
+func Exists() Predicate {
+	return func(_ context.Context, object *unstructured.Unstructured) (bool, error) {
+		return object != nil, nil
+	}
+}
+
+// Absent is satisfied when the object does not exist.
+func Absent() Predicate {
+	return func(_ context.Context, object *unstructured.Unstructured) (bool, error) {
+		return object == nil, nil
+	}
+}
+
+// Condition is satisfied when the object has a status condition of the given
+// type with the given status e.g. Condition("Ready", "True").
+func Condition(conditionType, status string) Predicate {
+	return func(_ context.Context, object *unstructured.Unstructured) (bool, error) {
+		if object == nil {
+			return false, nil
+		}
+
+		conditions, _, err := unstructured.NestedSlice(object.Object, "status", "conditions")
+		if err != nil {
+			return false, fmt.Errorf("%w: %w", ErrPredicate, err)
+		}
+
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			if condition["type"] == conditionType {
+				return condition["status"] == status, nil
+			}
+		}
+
+		return false, nil
+	}
+}
+
+// JSONPath evaluates a kubectl style JSONPath expression against the object e.g.
+// "{.status.loadBalancer.ingress[0].ip}".  When values are provided, the predicate
+// is satisfied when any result matches any of the values, otherwise it is satisfied
+// when the expression yields a non-empty result.
+func JSONPath(expression string, values ...string) Predicate {
+	// Be relaxed, like kubectl, about enclosing braces.
+	if !strings.HasPrefix(expression, "{") {
+		expression = "{" + expression + "}"
+	}
+
+	return func(_ context.Context, object *unstructured.Unstructured) (bool, error) {
+		if object == nil {
+			return false, nil
+		}
+
+		path := jsonpath.New("wait")
+		path.AllowMissingKeys(true)
+
+		if err := path.Parse(expression); err != nil {
+			return false, fmt.Errorf("%w: %w", ErrPredicate, err)
+		}
+
+		results, err := path.FindResults(object.Object)
+		if err != nil {
+			return false, fmt.Errorf("%w: %w", ErrPredicate, err)
+		}
+
+		for _, result := range results {
+			for _, value := range result {
+				if !value.IsValid() || !value.CanInterface() {
+					continue
+				}
+
+				v := value.Interface()
+				if v == nil {
+					continue
+				}
+
+				s := fmt.Sprint(v)
+
+				if len(values) == 0 && s != "" {
+					return true, nil
+				}
+
+				if slices.Contains(values, s) {
+					return true, nil
+				}
+			}
+		}
+
+		return false, nil
+	}
+}
+
+// compileCEL compiles a CEL expression that is evaluated against an object.
+func compileCEL(expression string) (cel.Program, error) {
+	env, err := cel.NewEnv(cel.Variable("object", cel.DynType))
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	if !ast.OutputType().IsExactType(cel.BoolType) && !ast.OutputType().IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("%w: expression must yield a boolean, got %s", ErrPredicate, ast.OutputType())
+	}
+
+	return env.Program(ast)
+}
+
+// CEL evaluates a CEL expression against the object, which is bound to the
+// "object" variable e.g. "object.status.readyReplicas == object.spec.replicas".
+// The predicate is satisfied when the expression yields true.  Like Kubernetes
+// validation rules, referencing a missing field is an error, so guard optional
+// fields with has() e.g. "has(object.status.ready) && object.status.ready".
+func CEL(expression string) Predicate {
+	// Compilation is expensive, so do it once, and report any errors when the
+	// predicate is evaluated.
+	program := sync.OnceValues(func() (cel.Program, error) {
+		return compileCEL(expression)
+	})
+
+	return func(ctx context.Context, object *unstructured.Unstructured) (bool, error) {
+		if object == nil {
+			return false, nil
+		}
+
+		p, err := program()
+		if err != nil {
+			return false, fmt.Errorf("%w: %w", ErrPredicate, err)
+		}
+
+		result, _, err := p.ContextEval(ctx, map[string]any{
+			"object": object.Object,
+		})
+		if err != nil {
+			return false, fmt.Errorf("%w: %w", ErrPredicate, err)
+		}
+
+		ok, isBool := result.Value().(bool)
+		if !isBool {
+			return false, fmt.Errorf("%w: expression yielded %v, not a boolean", ErrPredicate, result.Value())
+		}
+
+		return ok, nil
+	}
+}
+
+// Func converts the object into the provided type and evaluates a typed predicate
+// against it.  The predicate is not satisfied when the object does not exist.
+func Func[T any](predicate func(object *T) (bool, error)) Predicate {
+	return func(_ context.Context, object *unstructured.Unstructured) (bool, error) {
+		if object == nil {
+			return false, nil
+		}
+
+		var typed T
+
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &typed); err != nil {
+			return false, fmt.Errorf("%w: %w", ErrPredicate, err)
+		}
+
+		return predicate(&typed)
+	}
+}
+
+// All is satisfied when all predicates are satisfied.
+func All(predicates ...Predicate) Predicate {
+	return func(ctx context.Context, object *unstructured.Unstructured) (bool, error) {
+		for _, predicate := range predicates {
+			ok, err := predicate(ctx, object)
+			if err != nil {
+				return false, err
+			}
+
+			if !ok {
+				return false, nil
+			}
+		}
+
+		return true, nil
+	}
+}
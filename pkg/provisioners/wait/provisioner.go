@@ -0,0 +1,161 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	clientlib "github.com/unikorn-cloud/core/pkg/client"
+	"github.com/unikorn-cloud/core/pkg/provisioners"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var (
+	// ErrDeadlineExceeded is raised when the predicate isn't satisfied before
+	// the deadline.
+	ErrDeadlineExceeded = errors.New("deadline exceeded")
+)
+
+// Provisioner gates further provisioning on an object, typically one not owned by
+// us, satisfying a predicate e.g. a Cluster API cluster becoming ready, a CRD being
+// established, or a load balancer service being allocated an address.  The object
+// is polled on the current cluster, yielding until the predicate is satisfied.
+type Provisioner struct {
+	provisioners.Metadata
+
+	// object identifies the object to wait on.  It may be typed or unstructured,
+	// only its type, namespace and name are used.
+	object client.Object
+
+	// predicate must be satisfied for provisioning to continue.
+	predicate Predicate
+
+	// deadline, if set, raises an error if the predicate isn't satisfied
+	// in time.
+	deadline *time.Time
+
+	// deprovision also waits on the predicate when deprovisioning.
+	deprovision bool
+}
+
+// Ensure the Provisioner interface is implemented.
+var _ provisioners.Provisioner = &Provisioner{}
+
+// New returns a new provisioner that waits for the object to satisfy the predicate.
+func New(name string, object client.Object, predicate Predicate) *Provisioner {
+	return &Provisioner{
+		Metadata: provisioners.Metadata{
+			Name: name,
+		},
+		object:    object,
+		predicate: predicate,
+	}
+}
+
+// WithDeadline raises an error if the predicate isn't satisfied by the given time.
+// As provisioners are stateless, this is typically derived from the resource being
+// reconciled e.g. its creation time.
+func (p *Provisioner) WithDeadline(deadline time.Time) *Provisioner {
+	p.deadline = &deadline
+
+	return p
+}
+
+// WithDeprovision also waits for the predicate when deprovisioning, for example
+// to wait for an object's absence.  By default deprovisioning is a no-op.
+func (p *Provisioner) WithDeprovision() *Provisioner {
+	p.deprovision = true
+
+	return p
+}
+
+// get returns the object, or nil if it doesn't exist.
+func (p *Provisioner) get(ctx context.Context, cli client.Client) (*unstructured.Unstructured, error) {
+	gvk, err := apiutil.GVKForObject(p.object, cli.Scheme())
+	if err != nil {
+		return nil, err
+	}
+
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(gvk)
+
+	if err := cli.Get(ctx, client.ObjectKeyFromObject(p.object), object); err != nil {
+		if kerrors.IsNotFound(err) {
+			//nolint:nilnil
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return object, nil
+}
+
+// wait evaluates the predicate, yielding until it's satisfied.
+func (p *Provisioner) wait(ctx context.Context) error {
+	log := log.FromContext(ctx)
+
+	cluster, err := clientlib.ClusterFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	object, err := p.get(ctx, cluster.Client)
+	if err != nil {
+		return err
+	}
+
+	ok, err := p.predicate(ctx, object)
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		return nil
+	}
+
+	if p.deadline != nil && time.Now().After(*p.deadline) {
+		return fmt.Errorf("%w: %s waiting for %s/%s", ErrDeadlineExceeded, p.Name, p.object.GetNamespace(), p.object.GetName())
+	}
+
+	log.Info("awaiting predicate", "provisioner", p.Name, "namespace", p.object.GetNamespace(), "name", p.object.GetName())
+
+	return provisioners.ErrYield
+}
+
+// Provision implements the Provision interface.
+func (p *Provisioner) Provision(ctx context.Context) error {
+	return p.wait(ctx)
+}
+
+// Deprovision implements the Provision interface.
+func (p *Provisioner) Deprovision(ctx context.Context) error {
+	if !p.deprovision {
+		return nil
+	}
+
+	return p.wait(ctx)
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wait_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	coreclient "github.com/unikorn-cloud/core/pkg/client"
+	"github.com/unikorn-cloud/core/pkg/provisioners"
+	"github.com/unikorn-cloud/core/pkg/provisioners/wait"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testNamespace = "foo"
+	testName      = "bar"
+)
+
+func newContext(c client.Client) context.Context {
+	return coreclient.NewContextWithCluster(context.Background(), &coreclient.ClusterContext{Client: c})
+}
+
+func newService() *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testName,
+		},
+	}
+}
+
+// TestWaitJSONPath tests a typed object is gated on a JSONPath expression.
+func TestWaitJSONPath(t *testing.T) {
+	t.Parallel()
+
+	c := fake.NewClientBuilder().WithStatusSubresource(&corev1.Service{}).Build()
+	ctx := newContext(c)
+
+	provisioner := wait.New("service", newService(), wait.JSONPath(".status.loadBalancer.ingress[0].ip"))
+
+	// Missing objects cannot satisfy the predicate.
+	require.ErrorIs(t, provisioner.Provision(ctx), provisioners.ErrYield)
+
+	service := newService()
+	require.NoError(t, c.Create(ctx, service))
+	require.ErrorIs(t, provisioner.Provision(ctx), provisioners.ErrYield)
+
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{
+		{
+			IP: "192.168.0.1",
+		},
+	}
+
+	require.NoError(t, c.Status().Update(ctx, service))
+	require.NoError(t, provisioner.Provision(ctx))
+
+	require.NoError(t, wait.New("service", newService(), wait.JSONPath("{.status.loadBalancer.ingress[*].ip}", "192.168.0.1")).Provision(ctx))
+	require.ErrorIs(t, wait.New("service", newService(), wait.JSONPath("{.status.loadBalancer.ingress[*].ip}", "10.0.0.1")).Provision(ctx), provisioners.ErrYield)
+}
+
+// TestWaitCEL tests a typed object is gated on a CEL expression.
+func TestWaitCEL(t *testing.T) {
+	t.Parallel()
+
+	c := fake.NewClientBuilder().WithStatusSubresource(&corev1.Service{}).Build()
+	ctx := newContext(c)
+
+	provisioner := wait.New("service", newService(), wait.CEL("has(object.status.loadBalancer.ingress) && object.status.loadBalancer.ingress.exists(i, i.ip.startsWith('192.168.'))"))
+
+	// Missing objects cannot satisfy the predicate.
+	require.ErrorIs(t, provisioner.Provision(ctx), provisioners.ErrYield)
+
+	service := newService()
+	require.NoError(t, c.Create(ctx, service))
+	require.ErrorIs(t, provisioner.Provision(ctx), provisioners.ErrYield)
+
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{
+		{
+			IP: "192.168.0.1",
+		},
+	}
+
+	require.NoError(t, c.Status().Update(ctx, service))
+	require.NoError(t, provisioner.Provision(ctx))
+
+	// Invalid expressions, those that don't yield a boolean, and those that
+	// reference missing fields are errors.
+	require.ErrorIs(t, wait.New("service", newService(), wait.CEL("object.status.")).Provision(ctx), wait.ErrPredicate)
+	require.ErrorIs(t, wait.New("service", newService(), wait.CEL("'true'")).Provision(ctx), wait.ErrPredicate)
+	require.ErrorIs(t, wait.New("service", newService(), wait.CEL("object.metadata.name")).Provision(ctx), wait.ErrPredicate)
+	require.ErrorIs(t, wait.New("service", newService(), wait.CEL("object.spec.missing == 'x'")).Provision(ctx), wait.ErrPredicate)
+}
+
+// TestWaitCondition tests an unstructured object is gated on a status condition.
+func TestWaitCondition(t *testing.T) {
+	t.Parallel()
+
+	gvk := schema.GroupVersionKind{
+		Group:   "cluster.x-k8s.io",
+		Version: "v1beta1",
+		Kind:    "Cluster",
+	}
+
+	cluster := &unstructured.Unstructured{}
+	cluster.SetGroupVersionKind(gvk)
+	cluster.SetNamespace(testNamespace)
+	cluster.SetName(testName)
+
+	require.NoError(t, unstructured.SetNestedSlice(cluster.Object, []interface{}{
+		map[string]interface{}{
+			"type":   "Ready",
+			"status": "False",
+		},
+	}, "status", "conditions"))
+
+	c := fake.NewClientBuilder().WithObjects(cluster).Build()
+	ctx := newContext(c)
+
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(gvk)
+	object.SetNamespace(testNamespace)
+	object.SetName(testName)
+
+	provisioner := wait.New("cluster", object, wait.Condition("Ready", "True"))
+
+	require.ErrorIs(t, provisioner.Provision(ctx), provisioners.ErrYield)
+
+	require.NoError(t, unstructured.SetNestedSlice(cluster.Object, []interface{}{
+		map[string]interface{}{
+			"type":   "Ready",
+			"status": "True",
+		},
+	}, "status", "conditions"))
+
+	require.NoError(t, c.Update(ctx, cluster))
+	require.NoError(t, provisioner.Provision(ctx))
+}
+
+// TestWaitAbsent tests deprovisioning can wait for an object's absence.
+func TestWaitAbsent(t *testing.T) {
+	t.Parallel()
+
+	c := fake.NewClientBuilder().WithObjects(newService()).Build()
+	ctx := newContext(c)
+
+	provisioner := wait.New("service", newService(), wait.Absent())
+
+	// Deprovisioning doesn't wait by default.
+	require.NoError(t, provisioner.Deprovision(ctx))
+
+	provisioner.WithDeprovision()
+
+	require.ErrorIs(t, provisioner.Deprovision(ctx), provisioners.ErrYield)
+	require.NoError(t, c.Delete(ctx, newService()))
+	require.NoError(t, provisioner.Deprovision(ctx))
+}
+
+// TestWaitDeadline tests an error is raised when the deadline is exceeded.
+func TestWaitDeadline(t *testing.T) {
+	t.Parallel()
+
+	c := fake.NewClientBuilder().Build()
+	ctx := newContext(c)
+
+	predicate := wait.Func(func(service *corev1.Service) (bool, error) {
+		return service.Spec.ClusterIP != "", nil
+	})
+
+	require.ErrorIs(t, wait.New("service", newService(), predicate).WithDeadline(time.Now().Add(time.Hour)).Provision(ctx), provisioners.ErrYield)
+	require.ErrorIs(t, wait.New("service", newService(), predicate).WithDeadline(time.Now().Add(-time.Hour)).Provision(ctx), wait.ErrDeadlineExceeded)
+
+	service := newService()
+	service.Spec.ClusterIP = "10.0.0.1"
+
+	require.NoError(t, c.Create(ctx, service))
+	require.NoError(t, wait.New("service", newService(), predicate).WithDeadline(time.Now().Add(-time.Hour)).Provision(ctx))
+}
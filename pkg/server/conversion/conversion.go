@@ -26,6 +26,7 @@ import (
 	"github.com/unikorn-cloud/core/pkg/openapi"
 	"github.com/unikorn-cloud/core/pkg/util"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 )
@@ -46,11 +47,55 @@ func ConvertStatusCondition(in *unikornv1.Condition) openapi.ResourceProvisionin
 		return openapi.ResourceProvisioningStatusError
 	case unikornv1.ConditionReasonDeprovisioning:
 		return openapi.ResourceProvisioningStatusDeprovisioning
+	case unikornv1.ConditionReasonDegraded:
+		return openapi.ResourceProvisioningStatusDegraded
+	case unikornv1.ConditionReasonUpgrading:
+		return openapi.ResourceProvisioningStatusUpgrading
+	case unikornv1.ConditionReasonPaused:
+		return openapi.ResourceProvisioningStatusPaused
 	default:
 		return openapi.ResourceProvisioningStatusUnknown
 	}
 }
 
+// ConvertStatusConditions translates from the full set of Kubernetes status conditions
+// to a single API status.  Where multiple conditions apply, the most significant to
+// the user is reported: pausing and deletion trump everything, then errors, then
+// upgrades, degradation and finally the Available condition.
+func ConvertStatusConditions(in unikornv1.StatusConditionReader) openapi.ResourceProvisioningStatus {
+	read := func(t unikornv1.ConditionType) *unikornv1.Condition {
+		condition, err := in.StatusConditionRead(t)
+		if err != nil {
+			return nil
+		}
+
+		return condition
+	}
+
+	available := read(unikornv1.ConditionAvailable)
+	if available == nil {
+		return openapi.ResourceProvisioningStatusUnknown
+	}
+
+	if paused := read(unikornv1.ConditionPaused); paused != nil && paused.Status == corev1.ConditionTrue {
+		return ConvertStatusCondition(paused)
+	}
+
+	if available.Reason == unikornv1.ConditionReasonDeprovisioning || available.Reason == unikornv1.ConditionReasonErrored {
+		return ConvertStatusCondition(available)
+	}
+
+	if upgrading := read(unikornv1.ConditionUpgrading); upgrading != nil && upgrading.Status == corev1.ConditionTrue {
+		return ConvertStatusCondition(upgrading)
+	}
+
+	if healthy := read(unikornv1.ConditionHealthy); healthy != nil && healthy.Status == corev1.ConditionFalse {
+		return ConvertStatusCondition(healthy)
+	}
+
+	return ConvertStatusCondition(available)
+}
+
 // ResourceReadMetadata extracts generic metadata from a resource for GET APIs.
 func ResourceReadMetadata(in metav1.Object, tags unikornv1.TagList, status openapi.ResourceProvisioningStatus) openapi.ResourceReadMetadata {
 	labels := in.GetLabels()
@@ -0,0 +1,123 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	unikornv1 "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1"
+	unikornv1fake "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1/fake"
+	"github.com/unikorn-cloud/core/pkg/openapi"
+	"github.com/unikorn-cloud/core/pkg/server/conversion"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// newResource returns a resource with an Available condition with the given reason.
+func newResource(reason unikornv1.ConditionReason) *unikornv1fake.ManagedResource {
+	status := corev1.ConditionFalse
+
+	if reason == unikornv1.ConditionReasonProvisioned {
+		status = corev1.ConditionTrue
+	}
+
+	r := &unikornv1fake.ManagedResource{}
+	r.StatusConditionWrite(unikornv1.ConditionAvailable, status, reason, "")
+
+	return r
+}
+
+// TestConvertStatusConditionsUnknown tests a resource without an Available condition
+// has an unknown status, regardless of any other conditions.
+func TestConvertStatusConditionsUnknown(t *testing.T) {
+	t.Parallel()
+
+	r := &unikornv1fake.ManagedResource{}
+
+	assert.Equal(t, openapi.ResourceProvisioningStatusUnknown, conversion.ConvertStatusConditions(r))
+
+	r.StatusConditionWrite(unikornv1.ConditionUpgrading, corev1.ConditionTrue, unikornv1.ConditionReasonUpgrading, "")
+
+	assert.Equal(t, openapi.ResourceProvisioningStatusUnknown, conversion.ConvertStatusConditions(r))
+}
+
+// TestConvertStatusConditionsAvailable tests the Available condition is reported
+// when no other conditions are more significant.
+func TestConvertStatusConditionsAvailable(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, openapi.ResourceProvisioningStatusProvisioning, conversion.ConvertStatusConditions(newResource(unikornv1.ConditionReasonProvisioning)))
+
+	r := newResource(unikornv1.ConditionReasonProvisioned)
+	r.StatusConditionWrite(unikornv1.ConditionHealthy, corev1.ConditionTrue, unikornv1.ConditionReasonHealthy, "")
+	r.StatusConditionWrite(unikornv1.ConditionUpgrading, corev1.ConditionFalse, unikornv1.ConditionReasonUpgraded, "")
+	r.StatusConditionWrite(unikornv1.ConditionPaused, corev1.ConditionFalse, unikornv1.ConditionReasonResumed, "")
+
+	assert.Equal(t, openapi.ResourceProvisioningStatusProvisioned, conversion.ConvertStatusConditions(r))
+}
+
+// TestConvertStatusConditionsPaused tests pausing trumps everything else.
+func TestConvertStatusConditionsPaused(t *testing.T) {
+	t.Parallel()
+
+	r := newResource(unikornv1.ConditionReasonErrored)
+	r.StatusConditionWrite(unikornv1.ConditionUpgrading, corev1.ConditionTrue, unikornv1.ConditionReasonUpgrading, "")
+	r.StatusConditionWrite(unikornv1.ConditionPaused, corev1.ConditionTrue, unikornv1.ConditionReasonPaused, "")
+
+	assert.Equal(t, openapi.ResourceProvisioningStatusPaused, conversion.ConvertStatusConditions(r))
+}
+
+// TestConvertStatusConditionsErrored tests errors and deletion trump upgrades and
+// degradation.
+func TestConvertStatusConditionsErrored(t *testing.T) {
+	t.Parallel()
+
+	for reason, expected := range map[unikornv1.ConditionReason]openapi.ResourceProvisioningStatus{
+		unikornv1.ConditionReasonErrored:        openapi.ResourceProvisioningStatusError,
+		unikornv1.ConditionReasonDeprovisioning: openapi.ResourceProvisioningStatusDeprovisioning,
+	} {
+		r := newResource(reason)
+		r.StatusConditionWrite(unikornv1.ConditionUpgrading, corev1.ConditionTrue, unikornv1.ConditionReasonUpgrading, "")
+		r.StatusConditionWrite(unikornv1.ConditionHealthy, corev1.ConditionFalse, unikornv1.ConditionReasonDegraded, "")
+
+		assert.Equal(t, expected, conversion.ConvertStatusConditions(r))
+	}
+}
+
+// TestConvertStatusConditionsUpgrading tests upgrades trump degradation.
+func TestConvertStatusConditionsUpgrading(t *testing.T) {
+	t.Parallel()
+
+	r := newResource(unikornv1.ConditionReasonProvisioning)
+	r.StatusConditionWrite(unikornv1.ConditionUpgrading, corev1.ConditionTrue, unikornv1.ConditionReasonUpgrading, "")
+	r.StatusConditionWrite(unikornv1.ConditionHealthy, corev1.ConditionFalse, unikornv1.ConditionReasonDegraded, "")
+
+	assert.Equal(t, openapi.ResourceProvisioningStatusUpgrading, conversion.ConvertStatusConditions(r))
+}
+
+// TestConvertStatusConditionsDegraded tests degradation is reported over the
+// Available condition.
+func TestConvertStatusConditionsDegraded(t *testing.T) {
+	t.Parallel()
+
+	r := newResource(unikornv1.ConditionReasonProvisioned)
+	r.StatusConditionWrite(unikornv1.ConditionHealthy, corev1.ConditionFalse, unikornv1.ConditionReasonDegraded, "")
+
+	assert.Equal(t, openapi.ResourceProvisioningStatusDegraded, conversion.ConvertStatusConditions(r))
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package otel
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// tracerName identifies spans created by controllers.
+	tracerName = "github.com/unikorn-cloud/core"
+)
+
+type key int
+
+const (
+	// loggerKey is the logger that span values are added to.
+	loggerKey key = iota
+)
+
+// NewContextWithLogger sets the logger that spans add their values to.  This
+// allows values that apply to all spans, for example the resource being reconciled,
+// to be retained.
+func NewContextWithLogger(ctx context.Context, logger logr.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// loggerFromContext returns the logger that spans add their values to, by default
+// this is the global logger, as is done by the HTTP middleware.
+func loggerFromContext(ctx context.Context) logr.Logger {
+	if logger, ok := ctx.Value(loggerKey).(logr.Logger); ok {
+		return logger
+	}
+
+	return log.Log
+}
+
+// StartSpan starts a new span from the globally configured tracer provider, and
+// attaches the span context to the logger in the returned context so that log
+// lines can be correlated with traces, as is done by the HTTP middleware.  Values
+// are added to a base logger, not the one in the context, so nested spans replace
+// those of their parent rather than adding duplicates.
+//
+//nolint:spancheck
+func StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	ctx, span := otel.GetTracerProvider().Tracer(tracerName).Start(ctx, name, opts...)
+
+	spanContext := span.SpanContext()
+
+	logger := loggerFromContext(ctx).WithValues(
+		"span.name", name,
+		"span.id", spanContext.SpanID().String(),
+		"trace.id", spanContext.TraceID().String(),
+	)
+
+	return log.IntoContext(ctx, logger), span
+}
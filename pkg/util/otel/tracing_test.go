@@ -0,0 +1,60 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package otel_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/unikorn-cloud/core/pkg/util/otel"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// TestStartSpanLogging tests nested spans replace logging values rather than
+// accumulating duplicates, and that base logger values are retained.
+func TestStartSpanLogging(t *testing.T) {
+	t.Parallel()
+
+	var lines []string
+
+	logger := funcr.New(func(prefix, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{}).WithValues("controller", "test")
+
+	ctx := otel.NewContextWithLogger(context.Background(), logger)
+
+	ctx, outer := otel.StartSpan(ctx, "outer")
+	defer outer.End()
+
+	ctx, inner := otel.StartSpan(ctx, "inner")
+	defer inner.End()
+
+	log.FromContext(ctx).Info("message")
+
+	require.Len(t, lines, 1)
+
+	assert.Equal(t, 1, strings.Count(lines[0], `"span.name"`))
+	assert.Equal(t, 1, strings.Count(lines[0], `"trace.id"`))
+	assert.Contains(t, lines[0], `"span.name"="inner"`)
+	assert.Contains(t, lines[0], `"controller"="test"`)
+}
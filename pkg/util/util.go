@@ -25,8 +25,9 @@ import (
 	"io"
 	"net/http"
 
+	coreclient "github.com/unikorn-cloud/core/pkg/client"
+
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -97,12 +98,7 @@ var (
 type DefaultK8SAPITester struct{}
 
 func (t *DefaultK8SAPITester) Connect(ctx context.Context, config *clientcmdapi.Config) error {
-	restConfig, err := clientcmd.NewDefaultClientConfig(*config, &clientcmd.ConfigOverrides{}).ClientConfig()
-	if err != nil {
-		return err
-	}
-
-	c, err := client.New(restConfig, client.Options{})
+	c, _, err := coreclient.DefaultRemoteClientCache().Get(config)
 	if err != nil {
 		return err
 	}
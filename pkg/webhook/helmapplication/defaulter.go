@@ -0,0 +1,64 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmapplication
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	unikornv1 "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1"
+	"github.com/unikorn-cloud/core/pkg/constants"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// Defaulter fills in defaults for Helm applications.
+type Defaulter struct{}
+
+var _ admission.CustomDefaulter = &Defaulter{}
+
+// NewDefaulter returns a new defaulter.
+func NewDefaulter() *Defaulter {
+	return &Defaulter{}
+}
+
+// Default implements the admission.CustomDefaulter interface.  Applications
+// without a name label can't be depended upon, so it defaults to the resource
+// name.  Versions are sorted so they are presented consistently.
+func (d *Defaulter) Default(_ context.Context, obj runtime.Object) error {
+	application, ok := obj.(*unikornv1.HelmApplication)
+	if !ok {
+		return fmt.Errorf("%w: %T", ErrUnexpectedType, obj)
+	}
+
+	if _, ok := application.Labels[constants.NameLabel]; !ok {
+		if application.Labels == nil {
+			application.Labels = map[string]string{}
+		}
+
+		application.Labels[constants.NameLabel] = application.Name
+	}
+
+	slices.SortStableFunc(application.Spec.Versions, func(a, b unikornv1.HelmApplicationVersion) int {
+		return a.Version.Compare(&b.Version)
+	})
+
+	return nil
+}
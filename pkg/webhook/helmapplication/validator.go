@@ -0,0 +1,363 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmapplication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	unikornv1 "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1"
+	"github.com/unikorn-cloud/core/pkg/constants"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var (
+	// ErrUnexpectedType is raised when the webhook is called with the wrong type.
+	ErrUnexpectedType = errors.New("unexpected object type")
+)
+
+// ReferenceFunc returns a description of anything that references the given
+// version of an application, for example "cluster foo/bar".  Versions that are
+// referenced cannot be removed.
+type ReferenceFunc func(ctx context.Context, application *unikornv1.HelmApplication, version unikornv1.SemanticVersion) ([]string, error)
+
+// Validator validates Helm applications against the rest of the catalog.  The
+// catalog is defined as all applications in the same namespace, and applications
+// are referred to by dependencies using their name label.
+type Validator struct {
+	// client is used to read the catalog.
+	client client.Client
+
+	// references are called to find references to versions being removed.
+	references []ReferenceFunc
+}
+
+var _ admission.CustomValidator = &Validator{}
+
+// NewValidator returns a new validator, by default references from other
+// applications and application rollouts are checked.
+func NewValidator(client client.Client) *Validator {
+	v := &Validator{
+		client: client,
+	}
+
+	v.references = []ReferenceFunc{
+		v.dependencyReferences,
+		v.rolloutReferences,
+	}
+
+	return v
+}
+
+// WithReferences adds extra checks for version references, for example by resources
+// defined by other services.
+func (v *Validator) WithReferences(f ReferenceFunc) *Validator {
+	v.references = append(v.references, f)
+
+	return v
+}
+
+// applicationName returns the name other applications refer to an application by.
+func applicationName(application *unikornv1.HelmApplication) string {
+	return application.Labels[constants.NameLabel]
+}
+
+// catalog returns all other applications in the catalog, keyed by name.
+func (v *Validator) catalog(ctx context.Context, application *unikornv1.HelmApplication) (map[string]*unikornv1.HelmApplication, error) {
+	var applications unikornv1.HelmApplicationList
+
+	if err := v.client.List(ctx, &applications, client.InNamespace(application.Namespace)); err != nil {
+		return nil, err
+	}
+
+	catalog := map[string]*unikornv1.HelmApplication{}
+
+	for i := range applications.Items {
+		a := &applications.Items[i]
+
+		if a.Name == application.Name {
+			continue
+		}
+
+		if name := applicationName(a); name != "" {
+			catalog[name] = a
+		}
+	}
+
+	return catalog, nil
+}
+
+// satisfiable returns whether any version of the application meets the constraints.
+func satisfiable(application *unikornv1.HelmApplication, constraints *unikornv1.SemanticVersionConstraints) bool {
+	if constraints == nil {
+		return len(application.Spec.Versions) != 0
+	}
+
+	for version := range application.Versions() {
+		if constraints.Check(&version.Version) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateSource checks the version is either a Helm chart, or a path in a git branch.
+func validateSource(version *unikornv1.HelmApplicationVersion, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if version.Repo == nil || *version.Repo == "" {
+		errs = append(errs, field.Required(path.Child("repo"), "repository must be specified"))
+	}
+
+	switch {
+	case version.Chart != nil && version.Branch != nil:
+		errs = append(errs, field.Forbidden(path.Child("branch"), "only one of chart or branch may be specified"))
+	case version.Chart == nil && version.Branch == nil:
+		errs = append(errs, field.Required(path.Child("chart"), "either chart or branch must be specified"))
+	case version.Chart != nil && version.Path != nil:
+		errs = append(errs, field.Forbidden(path.Child("path"), "path may only be specified for git repositories"))
+	case version.Branch != nil && (version.Path == nil || *version.Path == ""):
+		errs = append(errs, field.Required(path.Child("path"), "path must be specified for git repositories"))
+	}
+
+	return errs
+}
+
+// validate checks the application is self consistent, and consistent with the catalog.
+func (v *Validator) validate(ctx context.Context, application *unikornv1.HelmApplication) (admission.Warnings, error) {
+	catalog, err := v.catalog(ctx, application)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs field.ErrorList
+
+	var warnings admission.Warnings
+
+	versionsPath := field.NewPath("spec", "versions")
+
+	for i := range application.Spec.Versions {
+		version := &application.Spec.Versions[i]
+		path := versionsPath.Index(i)
+
+		for j := range i {
+			if application.Spec.Versions[j].Version.Equal(&version.Version) {
+				errs = append(errs, field.Duplicate(path.Child("version"), version.Version.Original()))
+
+				break
+			}
+		}
+
+		errs = append(errs, validateSource(version, path)...)
+
+		for j, dependency := range version.Dependencies {
+			dependencyPath := path.Child("dependencies").Index(j)
+
+			target, ok := catalog[dependency.Name]
+			if !ok {
+				errs = append(errs, field.NotFound(dependencyPath.Child("name"), dependency.Name))
+
+				continue
+			}
+
+			if !satisfiable(target, dependency.Constraints) {
+				errs = append(errs, field.Invalid(dependencyPath.Child("constraints"), dependency.Constraints.String(), "no version of "+dependency.Name+" satisfies the constraints"))
+			}
+		}
+
+		for _, recommendation := range version.Recommends {
+			if _, ok := catalog[recommendation.Name]; !ok {
+				warnings = append(warnings, fmt.Sprintf("version %s recommends %s which does not exist", version.Version.Original(), recommendation.Name))
+			}
+		}
+	}
+
+	if len(errs) != 0 {
+		return warnings, kerrors.NewInvalid(unikornv1.SchemeGroupVersion.WithKind("HelmApplication").GroupKind(), application.Name, errs)
+	}
+
+	return warnings, nil
+}
+
+// dependencyReferences finds other applications that depend on this one, and
+// would no longer be satisfiable without the version.
+func (v *Validator) dependencyReferences(ctx context.Context, application *unikornv1.HelmApplication, version unikornv1.SemanticVersion) ([]string, error) {
+	name := applicationName(application)
+	if name == "" {
+		return nil, nil
+	}
+
+	catalog, err := v.catalog(ctx, application)
+	if err != nil {
+		return nil, err
+	}
+
+	// What remains once the version has been removed.
+	remaining := application.DeepCopy()
+	remaining.Spec.Versions = slices.DeleteFunc(remaining.Spec.Versions, func(candidate unikornv1.HelmApplicationVersion) bool {
+		return candidate.Version.Equal(&version)
+	})
+
+	var references []string
+
+	for _, dependent := range catalog {
+		for dependentVersion := range dependent.Versions() {
+			for _, dependency := range dependentVersion.Dependencies {
+				if dependency.Name != name {
+					continue
+				}
+
+				if dependency.Constraints != nil && !dependency.Constraints.Check(&version) {
+					continue
+				}
+
+				if !satisfiable(remaining, dependency.Constraints) {
+					references = append(references, fmt.Sprintf("application %s version %s", applicationName(dependent), dependentVersion.Version.Original()))
+				}
+			}
+		}
+	}
+
+	return references, nil
+}
+
+// rolloutReferences finds application rollouts that use the version.
+func (v *Validator) rolloutReferences(ctx context.Context, application *unikornv1.HelmApplication, version unikornv1.SemanticVersion) ([]string, error) {
+	var rollouts unikornv1.ApplicationRolloutList
+
+	if err := v.client.List(ctx, &rollouts, client.InNamespace(application.Namespace)); err != nil {
+		return nil, err
+	}
+
+	var references []string
+
+	for i := range rollouts.Items {
+		rollout := &rollouts.Items[i]
+
+		if rollout.Spec.Application != application.Name {
+			continue
+		}
+
+		// Once complete, nothing should be on the old version.
+		from := rollout.Status.Phase != unikornv1.ApplicationRolloutPhaseComplete && rollout.Spec.From.Equal(&version)
+
+		if from || rollout.Spec.To.Equal(&version) {
+			references = append(references, "application rollout "+rollout.Name)
+		}
+	}
+
+	return references, nil
+}
+
+// validateRemoval checks that no removed version is still referenced.
+func (v *Validator) validateRemoval(ctx context.Context, application *unikornv1.HelmApplication, removed []unikornv1.SemanticVersion) error {
+	var errs field.ErrorList
+
+	for _, version := range removed {
+		var references []string
+
+		for _, f := range v.references {
+			r, err := f(ctx, application, version)
+			if err != nil {
+				return err
+			}
+
+			references = append(references, r...)
+		}
+
+		if len(references) != 0 {
+			slices.Sort(references)
+
+			errs = append(errs, field.Forbidden(field.NewPath("spec", "versions"), fmt.Sprintf("version %s is referenced by %s", version.Original(), strings.Join(references, ", "))))
+		}
+	}
+
+	if len(errs) != 0 {
+		return kerrors.NewInvalid(unikornv1.SchemeGroupVersion.WithKind("HelmApplication").GroupKind(), application.Name, errs)
+	}
+
+	return nil
+}
+
+// removedVersions returns versions in the old application that are not in the new one.
+func removedVersions(oldApplication, newApplication *unikornv1.HelmApplication) []unikornv1.SemanticVersion {
+	var removed []unikornv1.SemanticVersion
+
+	for version := range oldApplication.Versions() {
+		if newApplication != nil {
+			if _, err := newApplication.GetVersion(version.Version); err == nil {
+				continue
+			}
+		}
+
+		removed = append(removed, version.Version)
+	}
+
+	return removed
+}
+
+// ValidateCreate implements the admission.CustomValidator interface.
+func (v *Validator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	application, ok := obj.(*unikornv1.HelmApplication)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnexpectedType, obj)
+	}
+
+	return v.validate(ctx, application)
+}
+
+// ValidateUpdate implements the admission.CustomValidator interface.
+func (v *Validator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldApplication, ok := oldObj.(*unikornv1.HelmApplication)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnexpectedType, oldObj)
+	}
+
+	newApplication, ok := newObj.(*unikornv1.HelmApplication)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnexpectedType, newObj)
+	}
+
+	warnings, err := v.validate(ctx, newApplication)
+	if err != nil {
+		return warnings, err
+	}
+
+	// Check against the old application, this is what others will have referenced
+	// e.g. if the name label is changed.
+	return warnings, v.validateRemoval(ctx, oldApplication, removedVersions(oldApplication, newApplication))
+}
+
+// ValidateDelete implements the admission.CustomValidator interface.
+func (v *Validator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	application, ok := obj.(*unikornv1.HelmApplication)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnexpectedType, obj)
+	}
+
+	return nil, v.validateRemoval(ctx, application, removedVersions(application, nil))
+}
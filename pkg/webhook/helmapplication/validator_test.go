@@ -0,0 +1,205 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmapplication_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	unikornv1 "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1"
+	coreclient "github.com/unikorn-cloud/core/pkg/client"
+	"github.com/unikorn-cloud/core/pkg/constants"
+	"github.com/unikorn-cloud/core/pkg/webhook/helmapplication"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	namespace = "catalog"
+)
+
+func mustNewValidator(t *testing.T, objects ...client.Object) *helmapplication.Validator {
+	t.Helper()
+
+	scheme, err := coreclient.NewScheme()
+	require.NoError(t, err)
+
+	return helmapplication.NewValidator(fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build())
+}
+
+func newVersion(version string) unikornv1.HelmApplicationVersion {
+	return unikornv1.HelmApplicationVersion{
+		Repo:    ptr.To("https://charts.example.com"),
+		Chart:   ptr.To("chart"),
+		Version: unikornv1.SemanticVersion{Version: *semver.MustParse(version)},
+	}
+}
+
+func newApplication(name string, versions ...unikornv1.HelmApplicationVersion) *unikornv1.HelmApplication {
+	return &unikornv1.HelmApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name + "-id",
+			Labels: map[string]string{
+				constants.NameLabel: name,
+			},
+		},
+		Spec: unikornv1.HelmApplicationSpec{
+			Versions: versions,
+		},
+	}
+}
+
+func withDependency(version unikornv1.HelmApplicationVersion, name, constraints string) unikornv1.HelmApplicationVersion {
+	dependency := unikornv1.HelmApplicationDependency{
+		Name: name,
+	}
+
+	if constraints != "" {
+		c, err := semver.NewConstraint(constraints)
+		if err != nil {
+			panic(err)
+		}
+
+		dependency.Constraints = &unikornv1.SemanticVersionConstraints{Constraints: *c}
+	}
+
+	version.Dependencies = append(version.Dependencies, dependency)
+
+	return version
+}
+
+// TestValidateVersions tests versions must be unique and have a valid source.
+func TestValidateVersions(t *testing.T) {
+	t.Parallel()
+
+	validator := mustNewValidator(t)
+
+	git := newVersion("1.1.0")
+	git.Chart = nil
+	git.Branch = ptr.To("main")
+
+	chartWithPath := newVersion("1.2.0")
+	chartWithPath.Path = ptr.To("charts/foo")
+
+	_, err := validator.ValidateCreate(context.Background(), newApplication("foo", newVersion("1.0.0"), newVersion("1.0.0"), git, chartWithPath))
+	require.True(t, kerrors.IsInvalid(err))
+	assert.ErrorContains(t, err, "spec.versions[1].version: Duplicate value")
+	assert.ErrorContains(t, err, "spec.versions[2].path: Required value")
+	assert.ErrorContains(t, err, "spec.versions[3].path: Forbidden")
+
+	git.Path = ptr.To("charts/foo")
+
+	_, err = validator.ValidateCreate(context.Background(), newApplication("foo", newVersion("1.0.0"), git))
+	require.NoError(t, err)
+}
+
+// TestValidateDependencies tests dependencies must exist and be satisfiable.
+func TestValidateDependencies(t *testing.T) {
+	t.Parallel()
+
+	validator := mustNewValidator(t, newApplication("bar", newVersion("1.0.0"), newVersion("1.1.0")))
+
+	application := newApplication("foo",
+		withDependency(newVersion("1.0.0"), "missing", ""),
+		withDependency(newVersion("1.1.0"), "bar", ">= 2.0.0"),
+	)
+
+	_, err := validator.ValidateCreate(context.Background(), application)
+	require.True(t, kerrors.IsInvalid(err))
+	assert.ErrorContains(t, err, "spec.versions[0].dependencies[0].name: Not found")
+	assert.ErrorContains(t, err, "spec.versions[1].dependencies[0].constraints: Invalid value")
+
+	application = newApplication("foo", withDependency(newVersion("1.0.0"), "bar", "~1.1"))
+	application.Spec.Versions[0].Recommends = []unikornv1.HelmApplicationRecommendation{{Name: "missing"}}
+
+	warnings, err := validator.ValidateCreate(context.Background(), application)
+	require.NoError(t, err)
+	assert.Len(t, warnings, 1)
+}
+
+// TestValidateRemoval tests versions cannot be removed while referenced.
+func TestValidateRemoval(t *testing.T) {
+	t.Parallel()
+
+	bar := newApplication("bar", newVersion("1.0.0"), newVersion("1.1.0"), newVersion("2.0.0"))
+
+	dependent := newApplication("foo", withDependency(newVersion("1.0.0"), "bar", "~1.1"))
+
+	rollout := &unikornv1.ApplicationRollout{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      "upgrade",
+		},
+		Spec: unikornv1.ApplicationRolloutSpec{
+			Application: bar.Name,
+			From:        unikornv1.SemanticVersion{Version: *semver.MustParse("1.1.0")},
+			To:          unikornv1.SemanticVersion{Version: *semver.MustParse("2.0.0")},
+		},
+	}
+
+	validator := mustNewValidator(t, bar, dependent, rollout)
+
+	// Version 1.0.0 isn't referenced.
+	updated := newApplication("bar", newVersion("1.1.0"), newVersion("2.0.0"))
+
+	_, err := validator.ValidateUpdate(context.Background(), bar, updated)
+	require.NoError(t, err)
+
+	// Version 1.1.0 is required by foo, and by the rollout.
+	updated = newApplication("bar", newVersion("1.0.0"), newVersion("2.0.0"))
+
+	_, err = validator.ValidateUpdate(context.Background(), bar, updated)
+	require.True(t, kerrors.IsInvalid(err))
+	assert.ErrorContains(t, err, "version 1.1.0 is referenced by application foo version 1.0.0, application rollout upgrade")
+
+	// Additional references can be checked.
+	external := func(_ context.Context, _ *unikornv1.HelmApplication, version unikornv1.SemanticVersion) ([]string, error) {
+		if version.Original() == "1.0.0" {
+			return []string{"cluster bar"}, nil
+		}
+
+		return nil, nil
+	}
+
+	_, err = validator.WithReferences(external).ValidateDelete(context.Background(), bar)
+	require.True(t, kerrors.IsInvalid(err))
+	assert.ErrorContains(t, err, "version 1.0.0 is referenced by cluster bar")
+	assert.ErrorContains(t, err, "version 2.0.0 is referenced by application rollout upgrade")
+}
+
+// TestDefault tests the name label is defaulted and versions are sorted.
+func TestDefault(t *testing.T) {
+	t.Parallel()
+
+	application := newApplication("foo", newVersion("1.10.0"), newVersion("1.2.0"))
+	application.Labels = nil
+
+	require.NoError(t, helmapplication.NewDefaulter().Default(context.Background(), application))
+	assert.Equal(t, "foo-id", application.Labels[constants.NameLabel])
+	assert.Equal(t, "1.2.0", application.Spec.Versions[0].Version.Original())
+	assert.Equal(t, "1.10.0", application.Spec.Versions[1].Version.Original())
+}
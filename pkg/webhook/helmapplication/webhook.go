@@ -0,0 +1,42 @@
+/*
+Copyright 2024-2025 the Unikorn Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmapplication
+
+import (
+	unikornv1 "github.com/unikorn-cloud/core/pkg/apis/unikorn/v1alpha1"
+
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// Register adds the defaulting and validating webhooks to the manager, these
+// are served on /mutate-unikorn-cloud-org-v1alpha1-helmapplication and
+// /validate-unikorn-cloud-org-v1alpha1-helmapplication respectively.  Any extra
+// reference checks are added to the validator.
+func Register(manager manager.Manager, references ...ReferenceFunc) error {
+	validator := NewValidator(manager.GetClient())
+
+	for _, f := range references {
+		validator.WithReferences(f)
+	}
+
+	return builder.WebhookManagedBy(manager).
+		For(&unikornv1.HelmApplication{}).
+		WithDefaulter(NewDefaulter()).
+		WithValidator(validator).
+		Complete()
+}